@@ -1,37 +1,87 @@
 package main
 
 import (
+	"bufio"
 	"flag"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 
 	"github.com/VibeShit/VibeShitCraft/pkg/server"
 )
 
 func main() {
-	address := flag.String("address", ":25565", "Server address to listen on")
-	maxPlayers := flag.Int("max-players", 20, "Maximum number of players")
-	motd := flag.String("motd", "A VibeShitCraft Server", "Server MOTD")
+	defaults := server.DefaultConfig()
+	address := flag.String("address", defaults.Address, "Server address to listen on")
+	maxPlayers := flag.Int("max-players", defaults.MaxPlayers, "Maximum number of players")
+	motd := flag.String("motd", defaults.MOTD, "Server MOTD")
 	seed := flag.Int64("seed", 0, "World seed (0 = random)")
 	defaultGameMode := flag.String("default-gamemode", "survival", "Default game mode (survival, creative, adventure, spectator)")
+	whitelist := flag.Bool("whitelist", false, "Only allow whitelisted players and operators to join")
+	levelType := flag.String("level-type", "default", "World type (default, flat, void, amplified)")
+	lavaLevel := flag.Int("lava-level", defaults.LavaLevel, "Caves fill with lava at and below this Y")
+	viewDistance := flag.Int("view-distance", defaults.ViewDistance, "Chunks sent around each player in each direction")
+	pregen := flag.Int("pregen", 0, "Pregenerate chunks within this radius (in chunks) of spawn before accepting players")
+	dataDir := flag.String("data-dir", "", "Directory for server.properties, ops.json and other data files (default: working directory)")
+	renderMap := flag.String("render-map", "", "Render a PNG map of the world around spawn to this file and exit without starting the server")
+	mapSize := flag.Int("map-size", 512, "Width and height in pixels of the map drawn by -render-map")
+	mapScale := flag.Int("map-scale", 4, "Blocks per pixel of the map drawn by -render-map")
 	flag.Parse()
 
-	gameMode, ok := server.ParseGameMode(*defaultGameMode)
-	if !ok {
-		log.Fatalf("Invalid default game mode: %s", *defaultGameMode)
+	// Settings come from server.properties; flags given on the command line take precedence
+	config, err := server.LoadConfig(filepath.Join(*dataDir, server.PropertiesFile))
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	config.DataDir = *dataDir
 
-	config := server.Config{
-		Address:         *address,
-		MaxPlayers:      *maxPlayers,
-		MOTD:            *motd,
-		Seed:            *seed,
-		DefaultGameMode: gameMode,
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "address":
+			config.Address = *address
+		case "max-players":
+			config.MaxPlayers = *maxPlayers
+		case "motd":
+			config.MOTD = *motd
+		case "seed":
+			config.Seed = *seed
+		case "default-gamemode":
+			gameMode, ok := server.ParseGameMode(*defaultGameMode)
+			if !ok {
+				log.Fatalf("Invalid default game mode: %s", *defaultGameMode)
+			}
+			config.DefaultGameMode = gameMode
+		case "whitelist":
+			config.Whitelist = *whitelist
+		case "view-distance":
+			config.ViewDistance = *viewDistance
+		case "level-type":
+			config.WorldType = *levelType
+		case "lava-level":
+			config.LavaLevel = *lavaLevel
+		}
+	})
+
+	if *renderMap != "" {
+		if err := writeMap(*renderMap, config, *mapSize, *mapScale); err != nil {
+			log.Fatalf("Failed to render map: %v", err)
+		}
+		return
 	}
 
 	srv := server.New(config)
+
+	// Generate the area around spawn up front so early visitors don't wait on terrain generation
+	if *pregen > 0 {
+		task, err := srv.Pregenerate(*pregen, nil)
+		if err != nil {
+			log.Fatalf("Failed to pregenerate: %v", err)
+		}
+		task.Wait()
+	}
+
 	if err := srv.Start(); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
@@ -39,6 +89,14 @@ func main() {
 	log.Printf("VibeShitCraft server started (Minecraft 1.8.9, Protocol 47)")
 	log.Printf("Address: %s | Max Players: %d", config.Address, config.MaxPlayers)
 
+	// Run commands typed into the console
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			srv.RunConsoleCommand(scanner.Text())
+		}
+	}()
+
 	// Wait for interrupt signal or internal shutdown
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
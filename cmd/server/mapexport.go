@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"image/png"
+	"log"
+	"os"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/server"
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// Block coordinates of the spawn point, where new players appear.
+const (
+	spawnBlockX = 8
+	spawnBlockZ = 8
+)
+
+// writeMap renders a size x size pixel map centred on the spawn point, scale
+// blocks per pixel, for the world described by config and saves it as a PNG.
+// Only the generator is created, so no chunks are generated or saved. The seed
+// must be set, as a map of a random seed could not be reproduced.
+func writeMap(path string, config server.Config, size, scale int) error {
+	if size < 1 || scale < 1 {
+		return fmt.Errorf("map size and scale must be positive")
+	}
+	if config.Seed == 0 {
+		return fmt.Errorf("no seed set; pass -seed or set level-seed in server.properties")
+	}
+	gen, err := world.NewChunkGenerator(config.WorldType, config.Seed, config.GeneratorSettings)
+	if err != nil {
+		return err
+	}
+	terrain, ok := gen.(*world.Generator)
+	if !ok {
+		return fmt.Errorf("%s worlds have no terrain to map", config.WorldType)
+	}
+
+	half := size * scale / 2
+	minX, minZ := spawnBlockX-half, spawnBlockZ-half
+	log.Printf("Rendering %d, %d to %d, %d around spawn of seed %d...", minX, minZ, minX+size*scale, minZ+size*scale, config.Seed)
+	img := terrain.RenderMap(minX, minZ, size, size, scale)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Printf("Map written to %s", path)
+	return nil
+}
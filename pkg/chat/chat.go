@@ -38,3 +38,12 @@ func WithExtra(text string, extra ...Message) Message {
 	}
 	return msg
 }
+
+// PlainText returns the message text with all formatting removed.
+func (m Message) PlainText() string {
+	text := m.Text
+	for _, extra := range m.Extra {
+		text += extra.PlainText()
+	}
+	return text
+}
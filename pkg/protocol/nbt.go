@@ -0,0 +1,304 @@
+package protocol
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// NBT tag type IDs.
+const (
+	TagEnd byte = iota
+	TagByte
+	TagShort
+	TagInt
+	TagLong
+	TagFloat
+	TagDouble
+	TagByteArray
+	TagString
+	TagList
+	TagCompound
+	TagIntArray
+)
+
+// NBTCompound is an NBT compound tag. Values are int8, int16, int32, int64,
+// float32, float64, []byte, string, NBTList, NBTCompound or []int32.
+type NBTCompound map[string]any
+
+// NBTList is an NBT list tag. All elements must have the same type.
+type NBTList []any
+
+// WriteNBT writes c as a named root compound tag, as used in block entity
+// packets and .schematic files. Keys are written in sorted order so the
+// output is deterministic.
+func WriteNBT(w io.Writer, name string, c NBTCompound) error {
+	if err := WriteByte(w, TagCompound); err != nil {
+		return err
+	}
+	if err := writeNBTString(w, name); err != nil {
+		return err
+	}
+	return writeNBTPayload(w, c)
+}
+
+// nbtTagType returns the tag type of an NBT value.
+func nbtTagType(v any) (byte, error) {
+	switch v.(type) {
+	case int8:
+		return TagByte, nil
+	case int16:
+		return TagShort, nil
+	case int32:
+		return TagInt, nil
+	case int64:
+		return TagLong, nil
+	case float32:
+		return TagFloat, nil
+	case float64:
+		return TagDouble, nil
+	case []byte:
+		return TagByteArray, nil
+	case string:
+		return TagString, nil
+	case NBTList:
+		return TagList, nil
+	case NBTCompound:
+		return TagCompound, nil
+	case []int32:
+		return TagIntArray, nil
+	}
+	return 0, fmt.Errorf("unsupported NBT value type %T", v)
+}
+
+func writeNBTString(w io.Writer, s string) error {
+	if len(s) > 0xFFFF {
+		return fmt.Errorf("NBT string too long (%d bytes)", len(s))
+	}
+	if err := WriteUint16(w, uint16(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+func writeNBTPayload(w io.Writer, v any) error {
+	switch v := v.(type) {
+	case int8:
+		return WriteByte(w, byte(v))
+	case int16:
+		return WriteInt16(w, v)
+	case int32:
+		return WriteInt32(w, v)
+	case int64:
+		return WriteInt64(w, v)
+	case float32:
+		return WriteFloat32(w, v)
+	case float64:
+		return WriteFloat64(w, v)
+	case []byte:
+		if err := WriteInt32(w, int32(len(v))); err != nil {
+			return err
+		}
+		_, err := w.Write(v)
+		return err
+	case string:
+		return writeNBTString(w, v)
+	case []int32:
+		if err := WriteInt32(w, int32(len(v))); err != nil {
+			return err
+		}
+		for _, n := range v {
+			if err := WriteInt32(w, n); err != nil {
+				return err
+			}
+		}
+		return nil
+	case NBTList:
+		elemType := TagEnd
+		if len(v) > 0 {
+			t, err := nbtTagType(v[0])
+			if err != nil {
+				return err
+			}
+			elemType = t
+		}
+		if err := WriteByte(w, elemType); err != nil {
+			return err
+		}
+		if err := WriteInt32(w, int32(len(v))); err != nil {
+			return err
+		}
+		for _, elem := range v {
+			if t, err := nbtTagType(elem); err != nil || t != elemType {
+				return fmt.Errorf("NBT list mixes %T with other element types", elem)
+			}
+			if err := writeNBTPayload(w, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case NBTCompound:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			t, err := nbtTagType(v[k])
+			if err != nil {
+				return fmt.Errorf("NBT tag %q: %w", k, err)
+			}
+			if err := WriteByte(w, t); err != nil {
+				return err
+			}
+			if err := writeNBTString(w, k); err != nil {
+				return err
+			}
+			if err := writeNBTPayload(w, v[k]); err != nil {
+				return err
+			}
+		}
+		return WriteByte(w, TagEnd)
+	}
+	return fmt.Errorf("unsupported NBT value type %T", v)
+}
+
+// maxNBTDepth limits how deeply compounds and lists may nest in NBT read by
+// ReadNBT, so malformed input cannot exhaust the stack.
+const maxNBTDepth = 512
+
+// ReadNBT reads a named root compound tag written by WriteNBT or another
+// NBT encoder, returning its name and contents. Values have the Go types
+// listed on NBTCompound.
+func ReadNBT(r io.Reader) (string, NBTCompound, error) {
+	tag, err := ReadByte(r)
+	if err != nil {
+		return "", nil, err
+	}
+	if tag != TagCompound {
+		return "", nil, fmt.Errorf("NBT root is tag type %d, not a compound", tag)
+	}
+	name, err := readNBTString(r)
+	if err != nil {
+		return "", nil, err
+	}
+	v, err := readNBTPayload(r, TagCompound, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, v.(NBTCompound), nil
+}
+
+func readNBTString(r io.Reader) (string, error) {
+	n, err := ReadUint16(r)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// readNBTLength reads the length of an array or list tag.
+func readNBTLength(r io.Reader) (int, error) {
+	n, err := ReadInt32(r)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("negative NBT length %d", n)
+	}
+	return int(n), nil
+}
+
+func readNBTPayload(r io.Reader, tagType byte, depth int) (any, error) {
+	if depth > maxNBTDepth {
+		return nil, fmt.Errorf("NBT nested more than %d levels deep", maxNBTDepth)
+	}
+	switch tagType {
+	case TagByte:
+		b, err := ReadByte(r)
+		return int8(b), err
+	case TagShort:
+		return ReadInt16(r)
+	case TagInt:
+		return ReadInt32(r)
+	case TagLong:
+		return ReadInt64(r)
+	case TagFloat:
+		return ReadFloat32(r)
+	case TagDouble:
+		return ReadFloat64(r)
+	case TagByteArray:
+		n, err := readNBTLength(r)
+		if err != nil {
+			return nil, err
+		}
+		// Read through a limited reader so a bogus length fails at EOF
+		// instead of allocating the whole array up front
+		buf, err := io.ReadAll(io.LimitReader(r, int64(n)))
+		if err != nil {
+			return nil, err
+		}
+		if len(buf) != n {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return buf, nil
+	case TagString:
+		return readNBTString(r)
+	case TagList:
+		elemType, err := ReadByte(r)
+		if err != nil {
+			return nil, err
+		}
+		n, err := readNBTLength(r)
+		if err != nil {
+			return nil, err
+		}
+		list := NBTList{}
+		for i := 0; i < n; i++ {
+			elem, err := readNBTPayload(r, elemType, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, elem)
+		}
+		return list, nil
+	case TagCompound:
+		c := NBTCompound{}
+		for {
+			t, err := ReadByte(r)
+			if err != nil {
+				return nil, err
+			}
+			if t == TagEnd {
+				return c, nil
+			}
+			name, err := readNBTString(r)
+			if err != nil {
+				return nil, err
+			}
+			if c[name], err = readNBTPayload(r, t, depth+1); err != nil {
+				return nil, fmt.Errorf("NBT tag %q: %w", name, err)
+			}
+		}
+	case TagIntArray:
+		n, err := readNBTLength(r)
+		if err != nil {
+			return nil, err
+		}
+		ints := []int32{}
+		for i := 0; i < n; i++ {
+			v, err := ReadInt32(r)
+			if err != nil {
+				return nil, err
+			}
+			ints = append(ints, v)
+		}
+		return ints, nil
+	}
+	return nil, fmt.Errorf("unknown NBT tag type %d", tagType)
+}
@@ -0,0 +1,111 @@
+package protocol
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestWriteNBT(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteNBT(&buf, "", NBTCompound{
+		"id": "MobSpawner",
+		"x":  int32(1),
+	})
+	if err != nil {
+		t.Fatalf("WriteNBT error: %v", err)
+	}
+	expected := []byte{
+		TagCompound, 0x00, 0x00, // Root compound, empty name
+		TagString, 0x00, 0x02, 'i', 'd', 0x00, 0x0A, 'M', 'o', 'b', 'S', 'p', 'a', 'w', 'n', 'e', 'r',
+		TagInt, 0x00, 0x01, 'x', 0x00, 0x00, 0x00, 0x01,
+		TagEnd,
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("WriteNBT = %v, want %v", buf.Bytes(), expected)
+	}
+}
+
+func TestWriteNBTNested(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteNBT(&buf, "Schematic", NBTCompound{
+		"Width":    int16(2),
+		"Blocks":   []byte{1, 2, 3, 4},
+		"Entities": NBTList{},
+		"Items": NBTList{
+			NBTCompound{"Slot": int8(0), "id": int16(264), "Count": int8(3)},
+			NBTCompound{"Slot": int8(5), "id": int16(265), "Count": int8(1)},
+		},
+		"Pos":    NBTList{float64(1.5), float64(64), float64(-2)},
+		"Ints":   []int32{7, 8},
+		"Seed":   int64(-1),
+		"Health": float32(20),
+	})
+	if err != nil {
+		t.Fatalf("WriteNBT error: %v", err)
+	}
+
+	// The existing slot-data reader must be able to skip what we wrote
+	r := bytes.NewReader(buf.Bytes())
+	if tag, _ := ReadByte(r); tag != TagCompound {
+		t.Fatalf("root tag = %d, want compound", tag)
+	}
+	if err := skipNBTString(r); err != nil {
+		t.Fatal(err)
+	}
+	if err := skipNBTCompound(r); err != nil {
+		t.Fatalf("skipNBTCompound: %v", err)
+	}
+	if r.Len() != 0 {
+		t.Errorf("%d bytes left after skipping the compound", r.Len())
+	}
+}
+
+func TestWriteNBTRejectsBadValues(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteNBT(&buf, "", NBTCompound{"bad": 3}); err == nil {
+		t.Error("expected error for untyped int")
+	}
+	if err := WriteNBT(&buf, "", NBTCompound{"mixed": NBTList{int32(1), "two"}}); err == nil {
+		t.Error("expected error for mixed list")
+	}
+}
+
+func TestReadNBT(t *testing.T) {
+	want := NBTCompound{
+		"Width":    int16(2),
+		"Blocks":   []byte{1, 2, 3, 4},
+		"Entities": NBTList{},
+		"Items": NBTList{
+			NBTCompound{"Slot": int8(0), "id": int16(264), "Count": int8(3)},
+		},
+		"Pos":    NBTList{float64(1.5), float64(64), float64(-2)},
+		"Ints":   []int32{7, 8},
+		"Seed":   int64(-1),
+		"Health": float32(20),
+		"Name":   "house",
+	}
+	var buf bytes.Buffer
+	if err := WriteNBT(&buf, "Schematic", want); err != nil {
+		t.Fatal(err)
+	}
+	name, got, err := ReadNBT(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadNBT error: %v", err)
+	}
+	if name != "Schematic" {
+		t.Errorf("name = %q, want Schematic", name)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadNBT = %v, want %v", got, want)
+	}
+
+	// Truncated input and absurd lengths are errors, not panics
+	if _, _, err := ReadNBT(bytes.NewReader(buf.Bytes()[:buf.Len()-5])); err == nil {
+		t.Error("expected error for truncated NBT")
+	}
+	huge := []byte{TagCompound, 0, 0, TagByteArray, 0, 1, 'a', 0x7f, 0xff, 0xff, 0xff}
+	if _, _, err := ReadNBT(bytes.NewReader(huge)); err == nil {
+		t.Error("expected error for a byte array longer than the input")
+	}
+}
@@ -0,0 +1,581 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/chat"
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+// banTimeFormat is the date format used in vanilla ban lists.
+const banTimeFormat = "2006-01-02 15:04:05 -0700"
+
+// banForever is the Expires value of permanent bans.
+const banForever = "forever"
+
+// defaultBanReason and defaultKickReason are used when no reason is given.
+const (
+	defaultBanReason  = "Banned by an operator."
+	defaultKickReason = "Kicked by an operator."
+)
+
+// WhitelistEntry is an entry in whitelist.json (vanilla format).
+type WhitelistEntry struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+// BanEntry is an entry in banned-players.json (vanilla format).
+type BanEntry struct {
+	UUID    string `json:"uuid"`
+	Name    string `json:"name"`
+	Created string `json:"created"`
+	Source  string `json:"source"`
+	Expires string `json:"expires"`
+	Reason  string `json:"reason"`
+}
+
+// IPBanEntry is an entry in banned-ips.json (vanilla format).
+type IPBanEntry struct {
+	IP      string `json:"ip"`
+	Created string `json:"created"`
+	Source  string `json:"source"`
+	Expires string `json:"expires"`
+	Reason  string `json:"reason"`
+}
+
+// banExpired returns true if a ban with the given Expires value is no longer in effect.
+func banExpired(expires string) bool {
+	if expires == "" || expires == banForever {
+		return false
+	}
+	t, err := time.Parse(banTimeFormat, expires)
+	return err == nil && time.Now().After(t)
+}
+
+// loadAccessLists reads whitelist.json, banned-players.json and banned-ips.json.
+func (s *Server) loadAccessLists() error {
+	var whitelist []WhitelistEntry
+	var bans []BanEntry
+	var ipBans []IPBanEntry
+	if err := readJSONFile(s.dataPath("whitelist.json"), &whitelist); err != nil {
+		return err
+	}
+	if err := readJSONFile(s.dataPath("banned-players.json"), &bans); err != nil {
+		return err
+	}
+	if err := readJSONFile(s.dataPath("banned-ips.json"), &ipBans); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.whitelist = whitelist
+	s.bannedPlayers = bans
+	s.bannedIPs = ipBans
+	s.mu.Unlock()
+	log.Printf("Loaded %d whitelisted players, %d banned players and %d banned IPs", len(whitelist), len(bans), len(ipBans))
+	return nil
+}
+
+// saveWhitelist writes the whitelist to whitelist.json.
+func (s *Server) saveWhitelist() error {
+	s.mu.RLock()
+	entries := append([]WhitelistEntry{}, s.whitelist...)
+	s.mu.RUnlock()
+	return writeJSONFile(s.dataPath("whitelist.json"), entries)
+}
+
+// saveBans writes the player ban list to banned-players.json.
+func (s *Server) saveBans() error {
+	s.mu.RLock()
+	entries := append([]BanEntry{}, s.bannedPlayers...)
+	s.mu.RUnlock()
+	return writeJSONFile(s.dataPath("banned-players.json"), entries)
+}
+
+// saveIPBans writes the IP ban list to banned-ips.json.
+func (s *Server) saveIPBans() error {
+	s.mu.RLock()
+	entries := append([]IPBanEntry{}, s.bannedIPs...)
+	s.mu.RUnlock()
+	return writeJSONFile(s.dataPath("banned-ips.json"), entries)
+}
+
+// loginDenyReason returns why a player may not join, or "" if they are allowed.
+func (s *Server) loginDenyReason(username string, uuid [16]byte, ip string) string {
+	uuidStr := formatUUID(uuid)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, ban := range s.bannedPlayers {
+		if (ban.UUID == uuidStr || strings.EqualFold(ban.Name, username)) && !banExpired(ban.Expires) {
+			return banMessage("You are banned from this server.", ban.Reason, ban.Expires)
+		}
+	}
+	for _, ban := range s.bannedIPs {
+		if ban.IP == ip && !banExpired(ban.Expires) {
+			return banMessage("Your IP address is banned from this server.", ban.Reason, ban.Expires)
+		}
+	}
+
+	if !s.whitelistEnabled {
+		return ""
+	}
+	for _, entry := range s.whitelist {
+		if entry.UUID == uuidStr || strings.EqualFold(entry.Name, username) {
+			return ""
+		}
+	}
+	// Operators may always join
+	for _, op := range s.ops {
+		if op.UUID == uuidStr || strings.EqualFold(op.Name, username) {
+			return ""
+		}
+	}
+	return "You are not white-listed on this server!"
+}
+
+// banMessage formats the disconnect message shown to a banned player.
+func banMessage(header, reason, expires string) string {
+	msg := header + "\nReason: " + reason
+	if expires != "" && expires != banForever {
+		msg += "\nYour ban will be removed on " + expires
+	}
+	return msg
+}
+
+// sendLoginDisconnect rejects a connection in the Login state with Login Disconnect (0x00).
+func sendLoginDisconnect(conn net.Conn, reason string) error {
+	pkt := protocol.MarshalPacket(0x00, func(w *bytes.Buffer) {
+		protocol.WriteString(w, chat.Text(reason).String())
+	})
+	return protocol.WritePacket(conn, pkt)
+}
+
+// KickPlayer disconnects a player with the given reason using Play Disconnect (0x40).
+func (s *Server) KickPlayer(player *Player, reason string) {
+	pkt := protocol.MarshalPacket(0x40, func(w *bytes.Buffer) {
+		protocol.WriteString(w, chat.Text(reason).String())
+	})
+	player.mu.Lock()
+	if player.Conn != nil {
+		protocol.WritePacket(player.Conn, pkt)
+		player.Conn.Close()
+	}
+	player.mu.Unlock()
+	log.Printf("Kicked %s: %s", player.Username, reason)
+}
+
+// playerIP returns the IP address a player is connected from, or "" if unknown.
+func playerIP(player *Player) string {
+	player.mu.Lock()
+	defer player.mu.Unlock()
+	if player.Conn == nil {
+		return ""
+	}
+	return addrIP(player.Conn.RemoteAddr())
+}
+
+// addrIP returns the host part of a network address.
+func addrIP(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// SetWhitelistEnabled turns whitelist enforcement on or off and saves the
+// setting as white-list in server.properties. Enabling it does not kick
+// players who are already online, like vanilla.
+func (s *Server) SetWhitelistEnabled(enabled bool) error {
+	s.mu.Lock()
+	s.whitelistEnabled = enabled
+	s.mu.Unlock()
+	return SetProperty(s.dataPath(PropertiesFile), "white-list", strconv.FormatBool(enabled))
+}
+
+// AddToWhitelist adds a player to the whitelist. Returns false if they were already on it.
+func (s *Server) AddToWhitelist(name string) (bool, error) {
+	s.mu.Lock()
+	for _, entry := range s.whitelist {
+		if strings.EqualFold(entry.Name, name) {
+			s.mu.Unlock()
+			return false, nil
+		}
+	}
+	s.whitelist = append(s.whitelist, WhitelistEntry{UUID: formatUUID(offlineUUID(name)), Name: name})
+	s.mu.Unlock()
+	return true, s.saveWhitelist()
+}
+
+// RemoveFromWhitelist removes a player from the whitelist. Returns false if they were not on it.
+func (s *Server) RemoveFromWhitelist(name string) (bool, error) {
+	s.mu.Lock()
+	removed := false
+	entries := s.whitelist[:0]
+	for _, entry := range s.whitelist {
+		if strings.EqualFold(entry.Name, name) {
+			removed = true
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	s.whitelist = entries
+	s.mu.Unlock()
+
+	if !removed {
+		return false, nil
+	}
+	return true, s.saveWhitelist()
+}
+
+// BanPlayer bans a player by name and kicks them if they are online.
+func (s *Server) BanPlayer(name, source, reason string) error {
+	if reason == "" {
+		reason = defaultBanReason
+	}
+	entry := BanEntry{
+		UUID:    formatUUID(offlineUUID(name)),
+		Name:    name,
+		Created: time.Now().Format(banTimeFormat),
+		Source:  source,
+		Expires: banForever,
+		Reason:  reason,
+	}
+
+	s.mu.Lock()
+	entries := s.bannedPlayers[:0]
+	for _, ban := range s.bannedPlayers {
+		if !strings.EqualFold(ban.Name, name) {
+			entries = append(entries, ban)
+		}
+	}
+	s.bannedPlayers = append(entries, entry)
+	s.mu.Unlock()
+
+	if p := s.findPlayer(name); p != nil {
+		s.KickPlayer(p, banMessage("You are banned from this server.", reason, banForever))
+	}
+	return s.saveBans()
+}
+
+// BanIP bans an IP address and kicks every player connected from it.
+// Returns the names of the kicked players.
+func (s *Server) BanIP(ip, source, reason string) ([]string, error) {
+	if reason == "" {
+		reason = defaultBanReason
+	}
+	entry := IPBanEntry{
+		IP:      ip,
+		Created: time.Now().Format(banTimeFormat),
+		Source:  source,
+		Expires: banForever,
+		Reason:  reason,
+	}
+
+	s.mu.Lock()
+	entries := s.bannedIPs[:0]
+	for _, ban := range s.bannedIPs {
+		if ban.IP != ip {
+			entries = append(entries, ban)
+		}
+	}
+	s.bannedIPs = append(entries, entry)
+	var online []*Player
+	for _, p := range s.players {
+		online = append(online, p)
+	}
+	s.mu.Unlock()
+
+	var kicked []string
+	for _, p := range online {
+		if playerIP(p) == ip {
+			s.KickPlayer(p, banMessage("Your IP address is banned from this server.", reason, banForever))
+			kicked = append(kicked, p.Username)
+		}
+	}
+	return kicked, s.saveIPBans()
+}
+
+// PardonPlayer removes a player's ban. Returns false if they were not banned.
+func (s *Server) PardonPlayer(name string) (bool, error) {
+	s.mu.Lock()
+	removed := false
+	entries := s.bannedPlayers[:0]
+	for _, ban := range s.bannedPlayers {
+		if strings.EqualFold(ban.Name, name) {
+			removed = true
+			continue
+		}
+		entries = append(entries, ban)
+	}
+	s.bannedPlayers = entries
+	s.mu.Unlock()
+
+	if !removed {
+		return false, nil
+	}
+	return true, s.saveBans()
+}
+
+// PardonIP removes an IP ban. Returns false if the address was not banned.
+func (s *Server) PardonIP(ip string) (bool, error) {
+	s.mu.Lock()
+	removed := false
+	entries := s.bannedIPs[:0]
+	for _, ban := range s.bannedIPs {
+		if ban.IP == ip {
+			removed = true
+			continue
+		}
+		entries = append(entries, ban)
+	}
+	s.bannedIPs = entries
+	s.mu.Unlock()
+
+	if !removed {
+		return false, nil
+	}
+	return true, s.saveIPBans()
+}
+
+// registerAccessCommands registers the whitelist, ban and kick commands.
+func (s *Server) registerAccessCommands() {
+	s.RegisterCommand(&Command{
+		Name:        "whitelist",
+		Description: "Manages the server whitelist",
+		Permission:  PermissionAdmin,
+		Syntaxes: []CommandSyntax{
+			{
+				Args: []Arg{Required("action", ChoiceArg{Options: []string{"on", "off", "list", "reload"}})},
+				Run:  s.runWhitelistCommand,
+			},
+			{
+				Args: []Arg{Required("action", ChoiceArg{Options: []string{"add", "remove"}}), Required("player", PlayerNameArg{})},
+				Run:  s.runWhitelistCommand,
+			},
+		},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "ban",
+		Description: "Bans a player from the server",
+		Permission:  PermissionAdmin,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Required("player", PlayerNameArg{}), Optional("reason", GreedyStringArg{})},
+			Run:  s.runBanCommand,
+		}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "ban-ip",
+		Description: "Bans an IP address, or the address of an online player",
+		Permission:  PermissionAdmin,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Required("address", PlayerNameArg{}), Optional("reason", GreedyStringArg{})},
+			Run:  s.runBanIPCommand,
+		}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "pardon",
+		Description: "Removes a player's ban",
+		Permission:  PermissionAdmin,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Required("player", bannedNameArg{})},
+			Run:  s.runPardonCommand,
+		}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "pardon-ip",
+		Description: "Removes an IP ban",
+		Permission:  PermissionAdmin,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Required("address", bannedIPArg{})},
+			Run:  s.runPardonIPCommand,
+		}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "kick",
+		Description: "Disconnects a player from the server",
+		Permission:  PermissionAdmin,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Required("player", PlayerArg{}), Optional("reason", GreedyStringArg{})},
+			Run:  s.runKickCommand,
+		}},
+	})
+}
+
+// bannedNameArg accepts any player name and suggests banned players.
+type bannedNameArg struct{}
+
+func (bannedNameArg) Parse(ctx *CommandContext, input string) (any, error) { return input, nil }
+
+func (bannedNameArg) Suggest(ctx *CommandContext) []string {
+	ctx.server.mu.RLock()
+	defer ctx.server.mu.RUnlock()
+	names := make([]string, 0, len(ctx.server.bannedPlayers))
+	for _, ban := range ctx.server.bannedPlayers {
+		names = append(names, ban.Name)
+	}
+	return names
+}
+
+// bannedIPArg accepts an IP address and suggests banned addresses.
+type bannedIPArg struct{}
+
+func (bannedIPArg) Parse(ctx *CommandContext, input string) (any, error) {
+	if net.ParseIP(input) == nil {
+		return nil, fmt.Errorf("%q is not a valid IP address", input)
+	}
+	return input, nil
+}
+
+func (bannedIPArg) Suggest(ctx *CommandContext) []string {
+	ctx.server.mu.RLock()
+	defer ctx.server.mu.RUnlock()
+	ips := make([]string, 0, len(ctx.server.bannedIPs))
+	for _, ban := range ctx.server.bannedIPs {
+		ips = append(ips, ban.IP)
+	}
+	return ips
+}
+
+// runWhitelistCommand handles /whitelist <on|off|list|reload> and /whitelist <add|remove> <player>.
+func (s *Server) runWhitelistCommand(ctx *CommandContext) {
+	name := ctx.String("player")
+	switch ctx.String("action") {
+	case "on", "off":
+		enabled := ctx.String("action") == "on"
+		if err := s.SetWhitelistEnabled(enabled); err != nil {
+			ctx.Error("Failed to save " + PropertiesFile + ": " + err.Error())
+			return
+		}
+		ctx.Info("Whitelist turned " + ctx.String("action"))
+		log.Printf("%s turned the whitelist %s", ctx.SenderName(), ctx.String("action"))
+	case "list":
+		s.mu.RLock()
+		names := make([]string, 0, len(s.whitelist))
+		for _, entry := range s.whitelist {
+			names = append(names, entry.Name)
+		}
+		s.mu.RUnlock()
+		ctx.Info(fmt.Sprintf("There are %d whitelisted players: %s", len(names), strings.Join(names, ", ")))
+	case "reload":
+		if err := s.loadAccessLists(); err != nil {
+			ctx.Error("Failed to reload: " + err.Error())
+			return
+		}
+		ctx.Info("Reloaded the whitelist and ban lists")
+	case "add":
+		added, err := s.AddToWhitelist(name)
+		if err != nil {
+			ctx.Error("Failed to save whitelist.json: " + err.Error())
+			return
+		}
+		if !added {
+			ctx.Error(name + " is already whitelisted")
+			return
+		}
+		ctx.Info("Added " + name + " to the whitelist")
+		log.Printf("%s added %s to the whitelist", ctx.SenderName(), name)
+	case "remove":
+		removed, err := s.RemoveFromWhitelist(name)
+		if err != nil {
+			ctx.Error("Failed to save whitelist.json: " + err.Error())
+			return
+		}
+		if !removed {
+			ctx.Error(name + " is not whitelisted")
+			return
+		}
+		ctx.Info("Removed " + name + " from the whitelist")
+		log.Printf("%s removed %s from the whitelist", ctx.SenderName(), name)
+	}
+}
+
+// runBanCommand handles /ban <player> [reason].
+func (s *Server) runBanCommand(ctx *CommandContext) {
+	name := ctx.String("player")
+	if err := s.BanPlayer(name, ctx.SenderName(), ctx.String("reason")); err != nil {
+		ctx.Error("Failed to save banned-players.json: " + err.Error())
+		return
+	}
+	ctx.Info("Banned " + name)
+	log.Printf("%s banned %s", ctx.SenderName(), name)
+}
+
+// runBanIPCommand handles /ban-ip <address|player> [reason].
+func (s *Server) runBanIPCommand(ctx *CommandContext) {
+	target := ctx.String("address")
+	ip := target
+	if net.ParseIP(target) == nil {
+		p := s.findPlayer(target)
+		if p == nil {
+			ctx.Error(fmt.Sprintf("%q is not a valid IP address or online player", target))
+			return
+		}
+		ip = playerIP(p)
+	}
+
+	kicked, err := s.BanIP(ip, ctx.SenderName(), ctx.String("reason"))
+	if err != nil {
+		ctx.Error("Failed to save banned-ips.json: " + err.Error())
+		return
+	}
+	ctx.Info("Banned IP address " + ip)
+	if len(kicked) > 0 {
+		ctx.Info("Kicked " + strings.Join(kicked, ", "))
+	}
+	log.Printf("%s banned IP address %s", ctx.SenderName(), ip)
+}
+
+// runPardonCommand handles /pardon <player>.
+func (s *Server) runPardonCommand(ctx *CommandContext) {
+	name := ctx.String("player")
+	removed, err := s.PardonPlayer(name)
+	if err != nil {
+		ctx.Error("Failed to save banned-players.json: " + err.Error())
+		return
+	}
+	if !removed {
+		ctx.Error(name + " is not banned")
+		return
+	}
+	ctx.Info("Unbanned " + name)
+	log.Printf("%s unbanned %s", ctx.SenderName(), name)
+}
+
+// runPardonIPCommand handles /pardon-ip <address>.
+func (s *Server) runPardonIPCommand(ctx *CommandContext) {
+	ip := ctx.String("address")
+	removed, err := s.PardonIP(ip)
+	if err != nil {
+		ctx.Error("Failed to save banned-ips.json: " + err.Error())
+		return
+	}
+	if !removed {
+		ctx.Error(ip + " is not banned")
+		return
+	}
+	ctx.Info("Unbanned IP address " + ip)
+	log.Printf("%s unbanned IP address %s", ctx.SenderName(), ip)
+}
+
+// runKickCommand handles /kick <player> [reason].
+func (s *Server) runKickCommand(ctx *CommandContext) {
+	target := ctx.Player("player")
+	reason := ctx.String("reason")
+	if reason == "" {
+		reason = defaultKickReason
+	}
+	s.KickPlayer(target, reason)
+	ctx.Info("Kicked " + target.Username + ": " + reason)
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"bytes"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+func TestWhitelist(t *testing.T) {
+	s := newCommandTestServer(t)
+	uuid := offlineUUID("Stranger")
+
+	if reason := s.loginDenyReason("Stranger", uuid, "10.0.0.1"); reason != "" {
+		t.Fatalf("whitelist is off, got deny reason %q", reason)
+	}
+
+	s.RunConsoleCommand("whitelist on")
+	if reason := s.loginDenyReason("Stranger", uuid, "10.0.0.1"); !strings.Contains(reason, "white-listed") {
+		t.Errorf("non-whitelisted player should be refused, got %q", reason)
+	}
+
+	s.RunConsoleCommand("whitelist add Stranger")
+	if reason := s.loginDenyReason("Stranger", uuid, "10.0.0.1"); reason != "" {
+		t.Errorf("whitelisted player refused: %q", reason)
+	}
+
+	// Operators bypass the whitelist
+	s.RunConsoleCommand("op Admin")
+	if reason := s.loginDenyReason("Admin", offlineUUID("Admin"), "10.0.0.1"); reason != "" {
+		t.Errorf("operator refused by whitelist: %q", reason)
+	}
+
+	// The list survives a reload from disk
+	s2 := New(s.config)
+	if err := s2.loadAccessLists(); err != nil {
+		t.Fatalf("loadAccessLists: %v", err)
+	}
+	s2.SetWhitelistEnabled(true)
+	if reason := s2.loginDenyReason("Stranger", uuid, "10.0.0.1"); reason != "" {
+		t.Errorf("whitelist not persisted: %q", reason)
+	}
+}
+
+func TestWhitelistToggleSavesProperties(t *testing.T) {
+	s := newCommandTestServer(t)
+	path := filepath.Join(s.config.DataDir, PropertiesFile)
+	config := DefaultConfig()
+	config.MOTD = "Keep me"
+	if err := SaveConfig(path, config); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	s.RunConsoleCommand("whitelist on")
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if !loaded.Whitelist || loaded.MOTD != "Keep me" {
+		t.Errorf("after /whitelist on: Whitelist = %v, MOTD = %q", loaded.Whitelist, loaded.MOTD)
+	}
+
+	s.RunConsoleCommand("whitelist off")
+	if loaded, err = LoadConfig(path); err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if loaded.Whitelist {
+		t.Error("white-list still true after /whitelist off")
+	}
+}
+
+func TestBanAndPardon(t *testing.T) {
+	s := newCommandTestServer(t)
+	uuid := offlineUUID("Griefer")
+
+	s.RunConsoleCommand("ban Griefer Burning down spawn")
+	reason := s.loginDenyReason("griefer", uuid, "10.0.0.1")
+	if !strings.Contains(reason, "banned") || !strings.Contains(reason, "Burning down spawn") {
+		t.Errorf("banned player deny reason = %q", reason)
+	}
+	if bans := s.bannedPlayers; len(bans) != 1 || bans[0].Source != "Server" || bans[0].Expires != banForever {
+		t.Errorf("ban entry = %+v", bans)
+	}
+
+	s.RunConsoleCommand("pardon Griefer")
+	if reason := s.loginDenyReason("Griefer", uuid, "10.0.0.1"); reason != "" {
+		t.Errorf("pardoned player refused: %q", reason)
+	}
+}
+
+func TestIPBan(t *testing.T) {
+	s := newCommandTestServer(t)
+
+	s.RunConsoleCommand("ban-ip 10.0.0.5")
+	if reason := s.loginDenyReason("Anyone", offlineUUID("Anyone"), "10.0.0.5"); !strings.Contains(reason, "IP address is banned") {
+		t.Errorf("banned IP deny reason = %q", reason)
+	}
+	if reason := s.loginDenyReason("Anyone", offlineUUID("Anyone"), "10.0.0.6"); reason != "" {
+		t.Errorf("other IP refused: %q", reason)
+	}
+
+	s.RunConsoleCommand("pardon-ip 10.0.0.5")
+	if reason := s.loginDenyReason("Anyone", offlineUUID("Anyone"), "10.0.0.5"); reason != "" {
+		t.Errorf("pardoned IP refused: %q", reason)
+	}
+}
+
+func TestExpiredBanIgnored(t *testing.T) {
+	s := newCommandTestServer(t)
+	s.bannedPlayers = []BanEntry{{
+		Name:    "Returning",
+		Expires: time.Now().Add(-time.Hour).Format(banTimeFormat),
+		Reason:  "Temporary",
+	}}
+	if reason := s.loginDenyReason("Returning", offlineUUID("Returning"), "10.0.0.1"); reason != "" {
+		t.Errorf("expired ban still enforced: %q", reason)
+	}
+}
+
+func TestBannedLoginGetsDisconnect(t *testing.T) {
+	s := newCommandTestServer(t)
+	s.RunConsoleCommand("ban Griefer")
+
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	loginStart := &protocol.Packet{ID: 0x00}
+	var buf bytes.Buffer
+	protocol.WriteString(&buf, "Griefer")
+	loginStart.Data = buf.Bytes()
+
+	type result struct {
+		player *Player
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		p, err := s.handleLoginStart(c1, loginStart)
+		done <- result{p, err}
+	}()
+
+	pkt, err := protocol.ReadPacket(c2)
+	if err != nil {
+		t.Fatalf("reading disconnect: %v", err)
+	}
+	if pkt.ID != 0x00 {
+		t.Fatalf("packet ID = 0x%02X, want Login Disconnect 0x00", pkt.ID)
+	}
+	msg, _ := protocol.ReadString(bytes.NewReader(pkt.Data))
+	if !strings.Contains(msg, "banned") {
+		t.Errorf("disconnect reason = %s", msg)
+	}
+
+	r := <-done
+	if r.err == nil || r.player != nil {
+		t.Error("banned login should fail")
+	}
+}
+
+func TestKickCommand(t *testing.T) {
+	s := newCommandTestServer(t)
+	c1, c2 := net.Pipe()
+	defer c2.Close()
+	player := &Player{EntityID: 1, Username: "Loud", Conn: c1, trackedEntities: make(map[int32]bool)}
+	s.players[player.EntityID] = player
+
+	go s.RunConsoleCommand("kick Loud Too much spam")
+
+	pkt, err := protocol.ReadPacket(c2)
+	if err != nil {
+		t.Fatalf("reading disconnect: %v", err)
+	}
+	if pkt.ID != 0x40 {
+		t.Fatalf("packet ID = 0x%02X, want Disconnect 0x40", pkt.ID)
+	}
+	msg, _ := protocol.ReadString(bytes.NewReader(pkt.Data))
+	if !strings.Contains(msg, "Too much spam") {
+		t.Errorf("kick reason = %s", msg)
+	}
+}
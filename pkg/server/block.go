@@ -78,6 +78,13 @@ func (s *Server) handleBlockBreak(player *Player, x, y, z int32) {
 	// Broadcast block change (air) to all players
 	s.broadcastBlockChange(x, y, z, 0)
 
+	if isChestBlock(blockID) {
+		s.dropChestContents(x, y, z)
+	}
+
+	// Blocks resting on this one (sand, gravel, anvils) may now fall
+	s.updateNeighbors(x, y, z)
+
 	// In creative mode, don't give items on break
 	if !giveItem {
 		return
@@ -94,6 +101,11 @@ func (s *Server) handleBlockBreak(player *Player, x, y, z int32) {
 	vz := (rand.Float64()*0.2 - 0.1)
 	s.SpawnItem(float64(x)+0.5, float64(y)+0.5, float64(z)+0.5, vx, vy, vz, itemID, damage, count)
 
+	// Ores also drop experience
+	if xp := oreExperience(blockID); xp > 0 {
+		s.SpawnExperience(float64(x)+0.5, float64(y)+0.5, float64(z)+0.5, xp)
+	}
+
 	// Fully grown wheat (block 59, metadata 7) also drops 0-3 wheat seeds
 	if blockID == 59 && blockState&0x0F == 7 {
 		seedCount := byte(rand.Intn(4)) // 0-3 seeds
@@ -190,6 +202,8 @@ func (s *Server) handleBlockPlacement(player *Player, r *bytes.Reader) {
 	if clickedBlockID == 58 { // Crafting Table
 		player.mu.Lock()
 		player.OpenWindowID = 1
+		player.OpenContainer = nil
+		player.OpenMerchant = nil
 		for i := range player.CraftTableGrid {
 			player.CraftTableGrid[i] = Slot{ItemID: -1}
 		}
@@ -207,6 +221,11 @@ func (s *Server) handleBlockPlacement(player *Player, r *bytes.Reader) {
 		return
 	}
 
+	if isChestBlock(clickedBlockID) {
+		s.openContainer(player, s.chestContainer(x, y, z), "Chest")
+		return
+	}
+
 	// Handle door right-click interaction (open/close)
 	// Doors: 64, 71, 193-197
 	if clickedBlockID == 64 || clickedBlockID == 71 || (clickedBlockID >= 193 && clickedBlockID <= 197) {
@@ -256,18 +275,7 @@ func (s *Server) handleBlockPlacement(player *Player, r *bytes.Reader) {
 			protocol.WriteInt32(w, 0)
 			protocol.WriteBool(w, false)
 		})
-		s.mu.RLock()
-		for _, p := range s.players {
-			if p.EntityID == player.EntityID {
-				continue
-			}
-			p.mu.Lock()
-			if p.Conn != nil {
-				protocol.WritePacket(p.Conn, soundPkt)
-			}
-			p.mu.Unlock()
-		}
-		s.mu.RUnlock()
+		s.sendToChunkViewers(ChunkPos{x >> 4, z >> 4}, soundPkt, player)
 
 		return // Don't place a block!
 	}
@@ -318,6 +326,41 @@ func (s *Server) handleBlockPlacement(player *Player, r *bytes.Reader) {
 		return
 	}
 
+	// Handle flint and steel: light TNT, or set fire to the clicked face of the block
+	if itemID == 259 {
+		tx, ty, tz := faceOffset(x, y, z, face)
+		if clickedBlockID == 46 {
+			s.PrimeTNT(x, y, z, tntFuseTicks)
+			tx, ty, tz = x, y, z
+		} else if !s.igniteBlock(tx, ty, tz) {
+			return
+		}
+
+		// Damage the flint and steel in survival mode
+		if player.GameMode == GameModeSurvival {
+			player.mu.Lock()
+			slotIndex := 36 + player.ActiveSlot
+			if player.Inventory[slotIndex].ItemID == itemID {
+				player.Inventory[slotIndex].Damage++
+				if player.Inventory[slotIndex].Damage >= 64 {
+					player.Inventory[slotIndex] = Slot{ItemID: -1}
+				}
+				slot := player.Inventory[slotIndex]
+				pkt := protocol.MarshalPacket(0x2F, func(w *bytes.Buffer) {
+					protocol.WriteByte(w, 0)
+					protocol.WriteInt16(w, int16(slotIndex))
+					protocol.WriteSlotData(w, slot.ItemID, slot.Count, slot.Damage)
+				})
+				if player.Conn != nil {
+					protocol.WritePacket(player.Conn, pkt)
+				}
+			}
+			player.mu.Unlock()
+		}
+		log.Printf("Player %s set fire at (%d, %d, %d)", player.Username, tx, ty, tz)
+		return
+	}
+
 	// Handle bonemeal on crops and saplings
 	if itemID == 351 && damage == 15 {
 		isCrop := clickedBlockID == 59 || clickedBlockID == 141 || clickedBlockID == 142 || clickedBlockID == 104 || clickedBlockID == 105
@@ -612,6 +655,9 @@ func (s *Server) handleBlockPlacement(player *Player, r *bytes.Reader) {
 		s.broadcastBlockChange(tx, ty+1, tz, topBlockState)
 	}
 
+	// Gravity blocks placed over air fall immediately
+	s.updateNeighbors(tx, ty, tz)
+
 	// Decrement the item stack if survival
 	if player.GameMode == GameModeSurvival {
 		player.mu.Lock()
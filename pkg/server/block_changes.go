@@ -0,0 +1,101 @@
+package server
+
+import (
+	"bytes"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// maxMultiBlockChanges is the number of blocks that can change in a chunk in
+// one tick before the whole chunk is resent instead, as in vanilla.
+const maxMultiBlockChanges = 64
+
+// blockChange is a block state set at a position, as sent in Multi Block Change.
+type blockChange struct {
+	Pos   world.BlockPos
+	State uint16
+}
+
+// broadcastBlockChange queues a block change for the players who have its
+// chunk loaded. Queued changes are sent at the end of the tick by
+// flushBlockChanges.
+func (s *Server) broadcastBlockChange(x, y, z int32, blockState uint16) {
+	s.queueBlockChange(world.BlockPos{X: x, Y: y, Z: z}, blockState)
+}
+
+// queueBlockChange records a block change to send at the end of the tick.
+// Only the last state set at a position during a tick is sent.
+func (s *Server) queueBlockChange(pos world.BlockPos, state uint16) {
+	cp := ChunkPos{pos.X >> 4, pos.Z >> 4}
+	s.blockChangeMu.Lock()
+	changes, ok := s.blockChanges[cp]
+	if !ok {
+		changes = make(map[world.BlockPos]uint16)
+		s.blockChanges[cp] = changes
+	}
+	changes[pos] = state
+	s.blockChangeMu.Unlock()
+}
+
+// flushBlockChanges sends the block changes queued this tick, one packet per
+// changed chunk: Block Change for a single block, Multi Block Change for up
+// to maxMultiBlockChanges blocks and the whole chunk for more.
+func (s *Server) flushBlockChanges() {
+	s.blockChangeMu.Lock()
+	pending := s.blockChanges
+	if len(pending) == 0 {
+		s.blockChangeMu.Unlock()
+		return
+	}
+	s.blockChanges = make(map[ChunkPos]map[world.BlockPos]uint16)
+	s.blockChangeMu.Unlock()
+
+	for cp, changes := range pending {
+		viewers := s.interest.chunkViewers(cp)
+		if len(viewers) == 0 {
+			continue
+		}
+		var pkt *protocol.Packet
+		switch {
+		case len(changes) == 1:
+			for pos, state := range changes {
+				pkt = protocol.MarshalPacket(0x23, func(w *bytes.Buffer) {
+					protocol.WritePosition(w, pos.X, pos.Y, pos.Z)
+					protocol.WriteVarInt(w, int32(state))
+				})
+			}
+		case len(changes) <= maxMultiBlockChanges:
+			pkt = multiBlockChangePacket(cp, changes)
+		default:
+			pkt = s.chunkDataPacket(cp.X, cp.Z)
+		}
+
+		for _, p := range viewers {
+			p.mu.Lock()
+			if p.Conn != nil && p.loadedChunks[cp] {
+				protocol.WritePacket(p.Conn, pkt)
+			}
+			p.mu.Unlock()
+			if len(changes) > maxMultiBlockChanges {
+				// A resent chunk loses its block entities on the client
+				s.sendChunkSpawners(p, cp.X, cp.Z)
+			}
+		}
+	}
+}
+
+// multiBlockChangePacket builds a Multi Block Change packet for changes
+// inside chunk cp.
+func multiBlockChangePacket(cp ChunkPos, changes map[world.BlockPos]uint16) *protocol.Packet {
+	return protocol.MarshalPacket(0x22, func(w *bytes.Buffer) {
+		protocol.WriteInt32(w, cp.X)
+		protocol.WriteInt32(w, cp.Z)
+		protocol.WriteVarInt(w, int32(len(changes)))
+		for pos, state := range changes {
+			protocol.WriteByte(w, byte(pos.X&15)<<4|byte(pos.Z&15))
+			protocol.WriteByte(w, byte(pos.Y))
+			protocol.WriteVarInt(w, int32(state))
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+// addPacketTestPlayer adds an online player with chunk (0, 0) loaded whose
+// packets are delivered on the returned channel.
+func addPacketTestPlayer(t *testing.T, s *Server, eid int32, name string) (*Player, chan *protocol.Packet) {
+	t.Helper()
+	c1, c2 := net.Pipe()
+	t.Cleanup(func() {
+		c1.Close()
+		c2.Close()
+	})
+	packets := make(chan *protocol.Packet, 16)
+	go func() {
+		for {
+			pkt, err := protocol.ReadPacket(c2)
+			if err != nil {
+				return
+			}
+			packets <- pkt
+		}
+	}()
+	p := &Player{EntityID: eid, Username: name, Conn: c1, trackedEntities: make(map[int32]bool), loadedChunks: map[ChunkPos]bool{{0, 0}: true}}
+	s.players[eid] = p
+	s.interest.addChunk(p, ChunkPos{0, 0})
+	return p, packets
+}
+
+func nextPacket(t *testing.T, packets chan *protocol.Packet) *protocol.Packet {
+	t.Helper()
+	select {
+	case pkt := <-packets:
+		return pkt
+	case <-time.After(time.Second):
+		t.Fatal("no packet received")
+		return nil
+	}
+}
+
+func TestFlushBlockChanges(t *testing.T) {
+	s := newCommandTestServer(t)
+	_, packets := addPacketTestPlayer(t, s, 1, "Viewer")
+	far := addCommandTestPlayer(t, s, 2, "Faraway")
+	far.loadedChunks[ChunkPos{5, 5}] = true
+	s.interest.addChunk(far, ChunkPos{5, 5})
+
+	// A single change is sent as Block Change, to the chunk's viewers only
+	s.broadcastBlockChange(3, 70, 4, 1<<4)
+	s.broadcastBlockChange(3, 70, 4, 2<<4) // Only the last state is sent
+	if viewers := s.interest.chunkViewers(ChunkPos{0, 0}); len(viewers) != 1 || viewers[0].Username != "Viewer" {
+		t.Errorf("chunk (0, 0) viewers = %v, want only Viewer", viewers)
+	}
+	s.flushBlockChanges()
+	pkt := nextPacket(t, packets)
+	if pkt.ID != 0x23 {
+		t.Fatalf("packet 0x%02X for one change, want Block Change", pkt.ID)
+	}
+
+	// A few changes in a chunk share one Multi Block Change
+	for x := int32(0); x < 10; x++ {
+		s.broadcastBlockChange(x, 71, 0, 1<<4)
+	}
+	s.flushBlockChanges()
+	pkt = nextPacket(t, packets)
+	if pkt.ID != 0x22 {
+		t.Fatalf("packet 0x%02X for ten changes, want Multi Block Change", pkt.ID)
+	}
+	r := bytes.NewReader(pkt.Data)
+	cx, _ := protocol.ReadInt32(r)
+	cz, _ := protocol.ReadInt32(r)
+	count, _, _ := protocol.ReadVarInt(r)
+	if cx != 0 || cz != 0 || count != 10 {
+		t.Errorf("Multi Block Change for chunk (%d, %d) with %d records, want (0, 0) with 10", cx, cz, count)
+	}
+
+	// Past the threshold the whole chunk is resent
+	for i := int32(0); i <= maxMultiBlockChanges; i++ {
+		s.broadcastBlockChange(i&15, 72+i>>4, 0, 1<<4)
+	}
+	s.flushBlockChanges()
+	if pkt = nextPacket(t, packets); pkt.ID != 0x21 {
+		t.Fatalf("packet 0x%02X for %d changes, want Chunk Data", pkt.ID, maxMultiBlockChanges+1)
+	}
+
+	// Nothing is sent once the queue is empty
+	s.flushBlockChanges()
+	select {
+	case pkt := <-packets:
+		t.Errorf("unexpected packet 0x%02X after an empty tick", pkt.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"time"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// blockTickLoop processes scheduled block updates at 20 ticks per second and
+// sends the block changes made during each tick.
+func (s *Server) blockTickLoop() {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.runScheduledTicks()
+			s.flushBlockChanges()
+		}
+	}
+}
+
+// scheduleBlockTick requests a block update at (x, y, z) after delay game ticks.
+// If an earlier update is already pending for that position it is kept.
+func (s *Server) scheduleBlockTick(x, y, z int32, delay int) {
+	pos := world.BlockPos{X: x, Y: y, Z: z}
+	s.tickMu.Lock()
+	due := s.currentTick + int64(delay)
+	if existing, ok := s.scheduledTicks[pos]; !ok || due < existing {
+		s.scheduledTicks[pos] = due
+	}
+	s.tickMu.Unlock()
+}
+
+// runScheduledTicks advances the game tick counter and runs all block updates that are due.
+func (s *Server) runScheduledTicks() {
+	s.tickMu.Lock()
+	s.currentTick++
+	var due []world.BlockPos
+	for pos, tick := range s.scheduledTicks {
+		if tick <= s.currentTick {
+			due = append(due, pos)
+			delete(s.scheduledTicks, pos)
+		}
+	}
+	s.tickMu.Unlock()
+
+	for _, pos := range due {
+		s.tickBlock(pos.X, pos.Y, pos.Z)
+	}
+}
+
+// tickBlock runs the scheduled update logic for the block at (x, y, z).
+func (s *Server) tickBlock(x, y, z int32) {
+	state := s.world.GetBlock(x, y, z)
+	switch state >> 4 {
+	case 51: // Fire
+		s.tickFire(x, y, z, state)
+	}
+}
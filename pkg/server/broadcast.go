@@ -37,25 +37,6 @@ func (s *Server) sendChatToPlayer(player *Player, msg chat.Message) {
 	player.mu.Unlock()
 }
 
-func (s *Server) broadcastBlockChange(x, y, z int32, blockState uint16) {
-	pkt := protocol.MarshalPacket(0x23, func(w *bytes.Buffer) {
-		protocol.WritePosition(w, x, y, z)
-		protocol.WriteVarInt(w, int32(blockState))
-	})
-
-	pos := ChunkPos{x >> 4, z >> 4}
-
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, p := range s.players {
-		p.mu.Lock()
-		if p.Conn != nil && p.loadedChunks[pos] {
-			protocol.WritePacket(p.Conn, pkt)
-		}
-		p.mu.Unlock()
-	}
-}
-
 func (s *Server) broadcastBlockBreakEffect(breaker *Player, x, y, z int32, blockState uint16) {
 	blockID := blockState >> 4
 	metadata := blockState & 0x0F
@@ -76,20 +57,21 @@ func (s *Server) broadcastBlockBreakEffect(breaker *Player, x, y, z int32, block
 		protocol.WriteBool(w, false) // Disable relative volume
 	})
 
-	pos := ChunkPos{x >> 4, z >> 4}
+	// Breaking player already sees the effect client-side
+	s.sendToChunkViewers(ChunkPos{x >> 4, z >> 4}, pkt, breaker)
+}
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, p := range s.players {
-		if p.EntityID == breaker.EntityID {
-			continue // Breaking player already sees the effect client-side
-		}
-		p.mu.Lock()
-		if p.Conn != nil && p.loadedChunks[pos] {
-			protocol.WritePacket(p.Conn, pkt)
-		}
-		p.mu.Unlock()
-	}
+// broadcastEffect sends an Effect packet (0x28) to every player that has the
+// chunk containing (x, y, z) loaded.
+func (s *Server) broadcastEffect(effectID int32, x, y, z int32, data int32) {
+	pkt := protocol.MarshalPacket(0x28, func(w *bytes.Buffer) {
+		protocol.WriteInt32(w, effectID)
+		protocol.WritePosition(w, x, y, z)
+		protocol.WriteInt32(w, data)
+		protocol.WriteBool(w, false) // Disable relative volume
+	})
+
+	s.sendToChunkViewers(ChunkPos{x >> 4, z >> 4}, pkt, nil)
 }
 
 func (s *Server) broadcastEntityTeleportByID(entityID int32, x, y, z float64, yaw, pitch float32, onGround bool) {
@@ -103,15 +85,7 @@ func (s *Server) broadcastEntityTeleportByID(entityID int32, x, y, z float64, ya
 		protocol.WriteBool(w, onGround)
 	})
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, p := range s.players {
-		p.mu.Lock()
-		if p.Conn != nil && p.trackedEntities[entityID] {
-			protocol.WritePacket(p.Conn, pkt)
-		}
-		p.mu.Unlock()
-	}
+	s.sendToEntityViewers(entityID, pkt)
 }
 
 func (s *Server) broadcastEntityVelocity(entityID int32, vx, vy, vz float64) {
@@ -122,15 +96,7 @@ func (s *Server) broadcastEntityVelocity(entityID int32, vx, vy, vz float64) {
 		protocol.WriteInt16(w, int16(vz*8000))
 	})
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, p := range s.players {
-		p.mu.Lock()
-		if p.Conn != nil && p.trackedEntities[entityID] {
-			protocol.WritePacket(p.Conn, pkt)
-		}
-		p.mu.Unlock()
-	}
+	s.sendToEntityViewers(entityID, pkt)
 }
 
 func (s *Server) broadcastEntityTeleport(player *Player) {
@@ -154,18 +120,7 @@ func (s *Server) broadcastEntityTeleport(player *Player) {
 		protocol.WriteBool(w, onGround)
 	})
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, other := range s.players {
-		if other.EntityID == entityID {
-			continue
-		}
-		other.mu.Lock()
-		if other.trackedEntities[entityID] {
-			protocol.WritePacket(other.Conn, pkt)
-		}
-		other.mu.Unlock()
-	}
+	s.sendToEntityViewers(entityID, pkt)
 }
 
 func (s *Server) broadcastEntityLook(player *Player) {
@@ -188,19 +143,7 @@ func (s *Server) broadcastEntityLook(player *Player) {
 		protocol.WriteByte(w, byte(yaw*256/360))
 	})
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, other := range s.players {
-		if other.EntityID == entityID {
-			continue
-		}
-		other.mu.Lock()
-		if other.trackedEntities[entityID] {
-			protocol.WritePacket(other.Conn, pkt)
-			protocol.WritePacket(other.Conn, headRotation)
-		}
-		other.mu.Unlock()
-	}
+	s.sendToEntityViewers(entityID, pkt, headRotation)
 }
 
 func (s *Server) broadcastAnimation(player *Player, animationID byte) {
@@ -213,18 +156,7 @@ func (s *Server) broadcastAnimation(player *Player, animationID byte) {
 		protocol.WriteByte(w, animationID)
 	})
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, other := range s.players {
-		if other.EntityID == entityID {
-			continue
-		}
-		other.mu.Lock()
-		if other.trackedEntities[entityID] {
-			protocol.WritePacket(other.Conn, pkt)
-		}
-		other.mu.Unlock()
-	}
+	s.sendToEntityViewers(entityID, pkt)
 }
 
 func (s *Server) broadcastDestroyEntity(entityID int32) {
@@ -233,16 +165,12 @@ func (s *Server) broadcastDestroyEntity(entityID int32) {
 		protocol.WriteVarInt(w, entityID)
 	})
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, p := range s.players {
+	for _, p := range s.interest.entityViewers(entityID) {
 		p.mu.Lock()
-		if p.Conn != nil {
-			if p.trackedEntities[entityID] {
-				protocol.WritePacket(p.Conn, pkt)
-			}
-			delete(p.trackedEntities, entityID)
+		if p.Conn != nil && p.trackedEntities[entityID] {
+			protocol.WritePacket(p.Conn, pkt)
 		}
+		s.untrackEntity(p, entityID)
 		p.mu.Unlock()
 	}
 }
@@ -253,15 +181,7 @@ func (s *Server) broadcastEntityStatus(entityID int32, status byte) {
 		protocol.WriteByte(w, status)
 	})
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, p := range s.players {
-		p.mu.Lock()
-		if p.Conn != nil && p.trackedEntities[entityID] {
-			protocol.WritePacket(p.Conn, pkt)
-		}
-		p.mu.Unlock()
-	}
+	s.sendToEntityViewers(entityID, pkt)
 }
 
 // broadcastHeldItem sends an Entity Equipment packet (0x04) to all other
@@ -287,18 +207,7 @@ func (s *Server) broadcastHeldItem(player *Player) {
 		protocol.WriteSlotData(w, slot.ItemID, slot.Count, slot.Damage)
 	})
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, other := range s.players {
-		if other.EntityID == entityID {
-			continue
-		}
-		other.mu.Lock()
-		if other.Conn != nil && other.trackedEntities[entityID] {
-			protocol.WritePacket(other.Conn, pkt)
-		}
-		other.mu.Unlock()
-	}
+	s.sendToEntityViewers(entityID, pkt)
 }
 
 // broadcastPlayerListRemove sends a Player List Item (action=4, Remove Player)
@@ -371,7 +280,7 @@ func (s *Server) spawnPlayerForOthers(player *Player) {
 		if s.shouldTrack(other, px, py, pz) {
 			s.sendSpawnPlayer(other, player)
 			other.mu.Lock()
-			other.trackedEntities[eid] = true
+			s.trackEntity(other, eid)
 			other.mu.Unlock()
 		}
 	}
@@ -393,7 +302,7 @@ func (s *Server) spawnOthersForPlayer(player *Player) {
 		if s.shouldTrack(player, ox, oy, oz) {
 			s.sendSpawnPlayer(player, other)
 			player.mu.Lock()
-			player.trackedEntities[oeid] = true
+			s.trackEntity(player, oeid)
 			player.mu.Unlock()
 		}
 	}
@@ -406,7 +315,8 @@ func (s *Server) sendSpawnPlayer(viewer *Player, target *Player) {
 	z := target.Z
 	yaw := target.Yaw
 	pitch := target.Pitch
-	targetGameMode := target.GameMode
+	entityFlags := playerEntityFlags(target)
+	skinParts := target.SkinParts
 	// Determine the item ID currently held in the target's hand so the viewer
 	// immediately sees the correct held item model.
 	currentItemID := int16(0)
@@ -437,12 +347,6 @@ func (s *Server) sendSpawnPlayer(viewer *Player, target *Player) {
 	}
 	viewer.mu.Unlock()
 
-	// Entity flags: set invisible for spectators
-	var entityFlags byte
-	if targetGameMode == GameModeSpectator {
-		entityFlags = EntityFlagInvisible
-	}
-
 	// Spawn Player - 0x0C
 	spawnPlayer := protocol.MarshalPacket(0x0C, func(w *bytes.Buffer) {
 		protocol.WriteVarInt(w, target.EntityID)
@@ -458,8 +362,11 @@ func (s *Server) sendSpawnPlayer(viewer *Player, target *Player) {
 		// DataWatcher list for spawned players:
 		// Index 0, type 0 (byte) = entity flags
 		protocol.WriteByte(w, 0x00)        // header: (type 0 << 5) | index 0
-		protocol.WriteByte(w, entityFlags) // flags (EntityFlagInvisible for spectators)
-		protocol.WriteByte(w, 0x7F)        // Metadata terminator
+		protocol.WriteByte(w, entityFlags) // flags (invisible for spectators, on fire when burning)
+		// Index 10, type 0 (byte) = displayed skin parts
+		protocol.WriteByte(w, EntityMetaSkinParts)
+		protocol.WriteByte(w, skinParts)
+		protocol.WriteByte(w, 0x7F) // Metadata terminator
 	})
 	viewer.mu.Lock()
 	if viewer.Conn != nil {
@@ -491,7 +398,8 @@ func (s *Server) shouldTrack(viewer *Player, ex, ey, ez float64) bool {
 	dx := vx - ex
 	dy := vy - ey
 	dz := vz - ez
-	return (dx*dx + dy*dy + dz*dz) <= (EntityTrackingRange * EntityTrackingRange)
+	trackingRange := s.config.EntityTrackingRange
+	return (dx*dx + dy*dy + dz*dz) <= (trackingRange * trackingRange)
 }
 
 // updateEntityTracking updates which entities are visible to the given player.
@@ -509,6 +417,22 @@ func (s *Server) updateEntityTracking(player *Player) {
 	for _, m := range s.mobEntities {
 		mobs = append(mobs, m)
 	}
+	fallingBlocks := make([]*FallingBlockEntity, 0, len(s.fallingBlocks))
+	for _, fb := range s.fallingBlocks {
+		fallingBlocks = append(fallingBlocks, fb)
+	}
+	primedTNT := make([]*PrimedTNTEntity, 0, len(s.primedTNT))
+	for _, tnt := range s.primedTNT {
+		primedTNT = append(primedTNT, tnt)
+	}
+	xpOrbs := make([]*ExperienceOrb, 0, len(s.xpOrbs))
+	for _, orb := range s.xpOrbs {
+		xpOrbs = append(xpOrbs, orb)
+	}
+	minecarts := make([]*MinecartEntity, 0, len(s.minecarts))
+	for _, cart := range s.minecarts {
+		minecarts = append(minecarts, cart)
+	}
 	s.mu.RUnlock()
 
 	// Check players
@@ -531,12 +455,12 @@ func (s *Server) updateEntityTracking(player *Player) {
 		if shouldTrack && !tracking {
 			s.sendSpawnPlayer(player, other)
 			player.mu.Lock()
-			player.trackedEntities[oeid] = true
+			s.trackEntity(player, oeid)
 			player.mu.Unlock()
 		} else if !shouldTrack && tracking {
 			s.sendDestroyEntity(player, oeid)
 			player.mu.Lock()
-			delete(player.trackedEntities, oeid)
+			s.untrackEntity(player, oeid)
 			player.mu.Unlock()
 		}
 	}
@@ -552,12 +476,12 @@ func (s *Server) updateEntityTracking(player *Player) {
 		if shouldTrack && !tracking {
 			s.sendItemToPlayer(player, item)
 			player.mu.Lock()
-			player.trackedEntities[item.EntityID] = true
+			s.trackEntity(player, item.EntityID)
 			player.mu.Unlock()
 		} else if !shouldTrack && tracking {
 			s.sendDestroyEntity(player, item.EntityID)
 			player.mu.Lock()
-			delete(player.trackedEntities, item.EntityID)
+			s.untrackEntity(player, item.EntityID)
 			player.mu.Unlock()
 		}
 	}
@@ -573,12 +497,96 @@ func (s *Server) updateEntityTracking(player *Player) {
 		if shouldTrack && !tracking {
 			s.sendMobToPlayer(player, mob)
 			player.mu.Lock()
-			player.trackedEntities[mob.EntityID] = true
+			s.trackEntity(player, mob.EntityID)
 			player.mu.Unlock()
 		} else if !shouldTrack && tracking {
 			s.sendDestroyEntity(player, mob.EntityID)
 			player.mu.Lock()
-			delete(player.trackedEntities, mob.EntityID)
+			s.untrackEntity(player, mob.EntityID)
+			player.mu.Unlock()
+		}
+	}
+
+	// Check falling blocks
+	for _, fb := range fallingBlocks {
+		player.mu.Lock()
+		tracking := player.trackedEntities[fb.EntityID]
+		player.mu.Unlock()
+
+		shouldTrack := s.shouldTrack(player, fb.X, fb.Y, fb.Z)
+
+		if shouldTrack && !tracking {
+			s.sendFallingBlockToPlayer(player, fb)
+			player.mu.Lock()
+			s.trackEntity(player, fb.EntityID)
+			player.mu.Unlock()
+		} else if !shouldTrack && tracking {
+			s.sendDestroyEntity(player, fb.EntityID)
+			player.mu.Lock()
+			s.untrackEntity(player, fb.EntityID)
+			player.mu.Unlock()
+		}
+	}
+
+	// Check primed TNT
+	for _, tnt := range primedTNT {
+		player.mu.Lock()
+		tracking := player.trackedEntities[tnt.EntityID]
+		player.mu.Unlock()
+
+		shouldTrack := s.shouldTrack(player, tnt.X, tnt.Y, tnt.Z)
+
+		if shouldTrack && !tracking {
+			s.sendPrimedTNTToPlayer(player, tnt)
+			player.mu.Lock()
+			s.trackEntity(player, tnt.EntityID)
+			player.mu.Unlock()
+		} else if !shouldTrack && tracking {
+			s.sendDestroyEntity(player, tnt.EntityID)
+			player.mu.Lock()
+			s.untrackEntity(player, tnt.EntityID)
+			player.mu.Unlock()
+		}
+	}
+
+	// Check experience orbs
+	for _, orb := range xpOrbs {
+		player.mu.Lock()
+		tracking := player.trackedEntities[orb.EntityID]
+		player.mu.Unlock()
+
+		shouldTrack := s.shouldTrack(player, orb.X, orb.Y, orb.Z)
+
+		if shouldTrack && !tracking {
+			s.sendExperienceOrbToPlayer(player, orb)
+			player.mu.Lock()
+			s.trackEntity(player, orb.EntityID)
+			player.mu.Unlock()
+		} else if !shouldTrack && tracking {
+			s.sendDestroyEntity(player, orb.EntityID)
+			player.mu.Lock()
+			s.untrackEntity(player, orb.EntityID)
+			player.mu.Unlock()
+		}
+	}
+
+	// Check chest minecarts
+	for _, cart := range minecarts {
+		player.mu.Lock()
+		tracking := player.trackedEntities[cart.EntityID]
+		player.mu.Unlock()
+
+		shouldTrack := s.shouldTrack(player, cart.X, cart.Y, cart.Z)
+
+		if shouldTrack && !tracking {
+			s.sendMinecartToPlayer(player, cart)
+			player.mu.Lock()
+			s.trackEntity(player, cart.EntityID)
+			player.mu.Unlock()
+		} else if !shouldTrack && tracking {
+			s.sendDestroyEntity(player, cart.EntityID)
+			player.mu.Lock()
+			s.untrackEntity(player, cart.EntityID)
 			player.mu.Unlock()
 		}
 	}
@@ -2,25 +2,42 @@ package server
 
 import (
 	"bytes"
+	"log"
 	"sort"
+	"time"
 
 	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
 )
 
+// Chunks that leave every player's view area stay cached for chunkEvictionGrace
+// before being dropped, so players walking back and forth don't regenerate them.
+const (
+	chunkEvictionGrace    = 30 * time.Second
+	chunkEvictionInterval = 10 * time.Second
+)
+
 func (s *Server) sendSpawnChunks(player *Player) {
 	playerChunkX := int32(int(player.X) >> 4)
 	playerChunkZ := int32(int(player.Z) >> 4)
 
 	player.mu.Lock()
+	viewDistance := s.viewDistanceFor(player)
+	for pos := range player.loadedChunks {
+		s.world.ReleaseChunk(pos.X, pos.Z)
+		s.interest.removeChunk(player, pos)
+	}
 	player.loadedChunks = make(map[ChunkPos]bool)
 	player.lastChunkX = playerChunkX
 	player.lastChunkZ = playerChunkZ
+	player.loadedViewDist = viewDistance
 
 	var toQueue []ChunkPos
-	for cx := playerChunkX - ViewDistance; cx <= playerChunkX+ViewDistance; cx++ {
-		for cz := playerChunkZ - ViewDistance; cz <= playerChunkZ+ViewDistance; cz++ {
+	for cx := playerChunkX - viewDistance; cx <= playerChunkX+viewDistance; cx++ {
+		for cz := playerChunkZ - viewDistance; cz <= playerChunkZ+viewDistance; cz++ {
 			pos := ChunkPos{cx, cz}
 			player.loadedChunks[pos] = true
+			s.world.RetainChunk(cx, cz)
+			s.interest.addChunk(player, pos)
 			toQueue = append(toQueue, pos)
 		}
 	}
@@ -55,15 +72,7 @@ func (s *Server) sendChunkColumn(player *Player, cx, cz int32) bool {
 	}
 	player.mu.Unlock()
 
-	chunkData, primaryBitMask := s.world.GetChunkData(cx, cz)
-	pkt := protocol.MarshalPacket(0x21, func(w *bytes.Buffer) {
-		protocol.WriteInt32(w, cx)                     // Chunk X
-		protocol.WriteInt32(w, cz)                     // Chunk Z
-		protocol.WriteBool(w, true)                    // Ground-up continuous
-		protocol.WriteUint16(w, primaryBitMask)        // Primary bit mask
-		protocol.WriteVarInt(w, int32(len(chunkData))) // Size
-		w.Write(chunkData)                             // Data
-	})
+	pkt := s.chunkDataPacket(cx, cz)
 
 	player.mu.Lock()
 	conn := player.Conn
@@ -72,31 +81,53 @@ func (s *Server) sendChunkColumn(player *Player, cx, cz int32) bool {
 
 	if conn != nil && stillLoaded {
 		protocol.WritePacket(conn, pkt)
+		s.sendChunkSpawners(player, cx, cz)
+		s.spawnChunkMinecarts(cx, cz)
+		s.spawnStructureMobs(cx, cz)
+		s.spawnChunkVillagers(cx, cz)
 	}
 	return true
 }
 
+// chunkDataPacket builds the Chunk Data packet carrying a whole chunk column.
+func (s *Server) chunkDataPacket(cx, cz int32) *protocol.Packet {
+	chunkData, primaryBitMask := s.world.GetChunkData(cx, cz)
+	return protocol.MarshalPacket(0x21, func(w *bytes.Buffer) {
+		protocol.WriteInt32(w, cx)                     // Chunk X
+		protocol.WriteInt32(w, cz)                     // Chunk Z
+		protocol.WriteBool(w, true)                    // Ground-up continuous
+		protocol.WriteUint16(w, primaryBitMask)        // Primary bit mask
+		protocol.WriteVarInt(w, int32(len(chunkData))) // Size
+		w.Write(chunkData)                             // Data
+	})
+}
+
 // sendChunkUpdates streams new chunks to the player when they cross chunk boundaries
-// and unloads chunks that are too far away.
+// or change their view distance, and unloads chunks that are too far away.
 func (s *Server) sendChunkUpdates(player *Player) {
 	player.mu.Lock()
 	currentChunkX := int32(int(player.X) >> 4)
 	currentChunkZ := int32(int(player.Z) >> 4)
 
-	if currentChunkX == player.lastChunkX && currentChunkZ == player.lastChunkZ {
+	viewDistance := s.viewDistanceFor(player)
+
+	if currentChunkX == player.lastChunkX && currentChunkZ == player.lastChunkZ && viewDistance == player.loadedViewDist {
 		player.mu.Unlock()
 		return
 	}
 
 	player.lastChunkX = currentChunkX
 	player.lastChunkZ = currentChunkZ
+	player.loadedViewDist = viewDistance
 
 	var toQueue []ChunkPos
-	for cx := currentChunkX - ViewDistance; cx <= currentChunkX+ViewDistance; cx++ {
-		for cz := currentChunkZ - ViewDistance; cz <= currentChunkZ+ViewDistance; cz++ {
+	for cx := currentChunkX - viewDistance; cx <= currentChunkX+viewDistance; cx++ {
+		for cz := currentChunkZ - viewDistance; cz <= currentChunkZ+viewDistance; cz++ {
 			pos := ChunkPos{cx, cz}
 			if !player.loadedChunks[pos] {
 				player.loadedChunks[pos] = true
+				s.world.RetainChunk(cx, cz)
+				s.interest.addChunk(player, pos)
 				toQueue = append(toQueue, pos)
 			}
 		}
@@ -106,12 +137,14 @@ func (s *Server) sendChunkUpdates(player *Player) {
 	for pos := range player.loadedChunks {
 		dx := pos.X - currentChunkX
 		dz := pos.Z - currentChunkZ
-		if dx < -ViewDistance || dx > ViewDistance || dz < -ViewDistance || dz > ViewDistance {
+		if dx < -viewDistance || dx > viewDistance || dz < -viewDistance || dz > viewDistance {
 			toUnload = append(toUnload, pos)
 		}
 	}
 	for _, pos := range toUnload {
 		delete(player.loadedChunks, pos)
+		s.world.ReleaseChunk(pos.X, pos.Z)
+		s.interest.removeChunk(player, pos)
 	}
 	player.mu.Unlock()
 
@@ -152,3 +185,33 @@ func (s *Server) sendChunkUpdates(player *Player) {
 	// Update entity tracking (e.g. spawn players who just came into range)
 	s.updateEntityTracking(player)
 }
+
+// releasePlayerChunks drops the player's references to the chunks in their
+// view area. Called when the player disconnects.
+func (s *Server) releasePlayerChunks(player *Player) {
+	player.mu.Lock()
+	defer player.mu.Unlock()
+	for pos := range player.loadedChunks {
+		s.world.ReleaseChunk(pos.X, pos.Z)
+		s.interest.removeChunk(player, pos)
+	}
+	player.loadedChunks = make(map[ChunkPos]bool)
+}
+
+// chunkEvictionLoop periodically frees chunks that no player has had in view
+// for chunkEvictionGrace.
+func (s *Server) chunkEvictionLoop() {
+	ticker := time.NewTicker(chunkEvictionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			if n := s.world.EvictIdleChunks(chunkEvictionGrace); n > 0 {
+				log.Printf("Unloaded %d idle chunks (%d still loaded)", n, s.world.LoadedChunkCount())
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package server
+
+import "testing"
+
+func TestChunksReleasedWhenOutOfView(t *testing.T) {
+	s := New(DefaultConfig())
+	player := &Player{EntityID: 1, Username: "Walker", X: 8, Y: 100, Z: 8, ViewDistance: 2,
+		trackedEntities: make(map[int32]bool), ChunkQueue: make(chan ChunkPos, 1024)}
+	s.sendSpawnChunks(player)
+	s.world.GetChunkData(0, 0)
+
+	// Still in view: not evicted
+	s.world.EvictIdleChunks(0)
+	if !s.world.IsChunkLoaded(0, 0) {
+		t.Fatal("chunk in view was evicted")
+	}
+
+	// Walk far enough that chunk (0, 0) leaves the view area
+	player.X = 16 * 10
+	s.sendChunkUpdates(player)
+	s.world.EvictIdleChunks(0)
+	if s.world.IsChunkLoaded(0, 0) {
+		t.Error("chunk out of view was not evicted")
+	}
+
+	s.world.GetChunkData(10, 0)
+	s.releasePlayerChunks(player)
+	s.world.EvictIdleChunks(0)
+	if s.world.IsChunkLoaded(10, 0) {
+		t.Error("chunk of a disconnected player was not evicted")
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"log"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+// MinViewDistance is the smallest view distance the server honours.
+const MinViewDistance = 2
+
+// EntityMetaSkinParts is the player metadata index (type byte) of the displayed skin parts.
+const EntityMetaSkinParts byte = 10
+
+// Defaults used until the client sends its settings.
+const (
+	defaultLocale    = "en_US"
+	defaultSkinParts = 0x7F // All skin layers shown
+)
+
+// viewDistanceFor returns the view distance chunks are loaded with for a player.
+// Caller must hold player.mu.
+func (s *Server) viewDistanceFor(player *Player) int32 {
+	if player.ViewDistance <= 0 {
+		return int32(s.config.ViewDistance)
+	}
+	return int32(player.ViewDistance)
+}
+
+// clampViewDistance limits a requested view distance to [MinViewDistance, server maximum].
+func (s *Server) clampViewDistance(requested int) int {
+	return max(MinViewDistance, min(requested, s.config.ViewDistance))
+}
+
+// handleClientSettings handles Serverbound packet 0x15. It stores the client's
+// locale, chat and skin preferences and reloads chunks if the view distance changed.
+func (s *Server) handleClientSettings(player *Player, r io.Reader) {
+	locale, err := protocol.ReadString(r)
+	if err != nil {
+		return
+	}
+	viewDistance, err := protocol.ReadByte(r)
+	if err != nil {
+		return
+	}
+	chatMode, _ := protocol.ReadByte(r)
+	chatColors, _ := protocol.ReadBool(r)
+	skinParts, _ := protocol.ReadByte(r)
+
+	player.mu.Lock()
+	oldViewDistance := player.ViewDistance
+	oldSkinParts := player.SkinParts
+	player.Locale = locale
+	player.ViewDistance = s.clampViewDistance(int(int8(viewDistance)))
+	player.ChatMode = chatMode
+	player.ChatColors = chatColors
+	player.SkinParts = skinParts
+	newViewDistance := player.ViewDistance
+	player.mu.Unlock()
+
+	if newViewDistance != oldViewDistance {
+		log.Printf("Player %s view distance set to %d", player.Username, newViewDistance)
+		s.sendChunkUpdates(player)
+	}
+	if skinParts != oldSkinParts {
+		s.broadcastSkinParts(player)
+	}
+}
+
+// broadcastSkinParts sends the player's displayed skin parts to the player and
+// everyone tracking them.
+func (s *Server) broadcastSkinParts(player *Player) {
+	player.mu.Lock()
+	entityID := player.EntityID
+	skinParts := player.SkinParts
+	player.mu.Unlock()
+
+	pkt := protocol.MarshalPacket(0x1C, func(w *bytes.Buffer) {
+		protocol.WriteVarInt(w, entityID)
+		protocol.WriteByte(w, EntityMetaSkinParts) // header: (type 0 << 5) | index 10
+		protocol.WriteByte(w, skinParts)
+		protocol.WriteByte(w, 0x7F) // Metadata terminator
+	})
+
+	s.sendToPlayerAndViewers(player, pkt)
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+// clientSettingsPacket builds a Serverbound Client Settings (0x15) packet.
+func clientSettingsPacket(locale string, viewDistance, skinParts byte) *protocol.Packet {
+	return protocol.MarshalPacket(0x15, func(w *bytes.Buffer) {
+		protocol.WriteString(w, locale)
+		protocol.WriteByte(w, viewDistance)
+		protocol.WriteByte(w, 0)    // Chat mode: enabled
+		protocol.WriteBool(w, true) // Chat colors
+		protocol.WriteByte(w, skinParts)
+	})
+}
+
+func TestClientSettingsViewDistance(t *testing.T) {
+	s := New(DefaultConfig())
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	go func() {
+		for {
+			if _, err := protocol.ReadPacket(c2); err != nil {
+				return
+			}
+		}
+	}()
+
+	player := &Player{EntityID: 1, Username: "Viewer", Conn: c1, X: 8, Y: 100, Z: 8, ViewDistance: s.config.ViewDistance,
+		trackedEntities: make(map[int32]bool), ChunkQueue: make(chan ChunkPos, 1024)}
+	s.players[player.EntityID] = player
+	s.sendSpawnChunks(player)
+
+	if n := len(player.loadedChunks); n != 15*15 {
+		t.Fatalf("initially loaded %d chunks, want %d", n, 15*15)
+	}
+
+	s.handlePlayPacket(player, clientSettingsPacket("de_DE", 3, 0x01))
+	if player.ViewDistance != 3 {
+		t.Errorf("ViewDistance = %d, want 3", player.ViewDistance)
+	}
+	if n := len(player.loadedChunks); n != 7*7 {
+		t.Errorf("loaded %d chunks after shrinking view distance, want %d", n, 7*7)
+	}
+	if player.Locale != "de_DE" || player.SkinParts != 0x01 {
+		t.Errorf("locale %q skin parts %#x, want de_DE 0x01", player.Locale, player.SkinParts)
+	}
+
+	// Requests beyond the server maximum are clamped
+	s.handlePlayPacket(player, clientSettingsPacket("de_DE", 32, 0x01))
+	if player.ViewDistance != s.config.ViewDistance {
+		t.Errorf("ViewDistance = %d, want server maximum %d", player.ViewDistance, s.config.ViewDistance)
+	}
+	if n := len(player.loadedChunks); n != 15*15 {
+		t.Errorf("loaded %d chunks after growing view distance, want %d", n, 15*15)
+	}
+}
+
+func TestClampViewDistance(t *testing.T) {
+	s := New(DefaultConfig())
+	tests := []struct{ requested, want int }{
+		{0, MinViewDistance},
+		{-5, MinViewDistance},
+		{4, 4},
+		{64, s.config.ViewDistance},
+	}
+	for _, tt := range tests {
+		if got := s.clampViewDistance(tt.requested); got != tt.want {
+			t.Errorf("clampViewDistance(%d) = %d, want %d", tt.requested, got, tt.want)
+		}
+	}
+}
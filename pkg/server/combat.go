@@ -20,7 +20,17 @@ func (s *Server) handleAttack(attacker *Player, targetID int32) {
 
 	s.mu.RLock()
 	target, ok := s.players[targetID]
+	mob, isMob := s.mobEntities[targetID]
+	cart, isCart := s.minecarts[targetID]
 	s.mu.RUnlock()
+	if isMob {
+		s.attackMob(attacker, mob)
+		return
+	}
+	if isCart {
+		s.breakMinecart(attacker, cart)
+		return
+	}
 	if !ok {
 		return
 	}
@@ -87,6 +97,7 @@ func (s *Server) applyDamage(target *Player, damage float32, deathMessage string
 		// Broadcast death message
 		s.broadcastChat(chat.Colored(target.Username+" "+deathMessage, "red"))
 		log.Printf("Player %s %s", target.Username, deathMessage)
+		s.handlePlayerDeath(target, deathMessage)
 	}
 
 	return isDead
@@ -133,7 +144,7 @@ func (s *Server) handleRespawn(player *Player) {
 		protocol.WriteInt32(w, 0) // Overworld
 		protocol.WriteByte(w, 0)  // Peaceful difficulty
 		protocol.WriteByte(w, player.GameMode)
-		protocol.WriteString(w, "default")
+		protocol.WriteString(w, s.world.Gen.LevelType())
 	})
 	player.mu.Lock()
 	protocol.WritePacket(player.Conn, respawnPkt)
@@ -152,8 +163,9 @@ func (s *Server) handleRespawn(player *Player) {
 	protocol.WritePacket(player.Conn, posLook)
 	player.mu.Unlock()
 
-	// Update health
+	// Update health and experience
 	s.sendHealth(player)
+	s.sendExperience(player)
 
 	// Sync full inventory to prevent desync after respawn
 	player.mu.Lock()
@@ -234,3 +246,85 @@ func (s *Server) updateFallState(player *Player, oldY, newY float64, wasOnGround
 		}
 	}
 }
+
+// mobMaxHealth returns the starting health of a mob type (vanilla values).
+func mobMaxHealth(mobType byte) float32 {
+	switch mobType {
+	case 52, 55, 62: // Spider, Slime, Magma cube
+		return 16
+	case 56: // Ghast
+		return 10
+	case 58: // Enderman
+		return 40
+	case 59: // Cave spider
+		return 12
+	case 60, 95: // Silverfish, Wolf
+		return 8
+	case 65: // Bat
+		return 6
+	case 66: // Witch
+		return 26
+	case 90, 92, 94, 96, 98: // Pig, Cow, Squid, Mooshroom, Ocelot
+		return 10
+	case 91: // Sheep
+		return 8
+	case 93, 97: // Chicken, Snow golem
+		return 4
+	case 99: // Iron golem
+		return 100
+	case 100: // Horse
+		return 15
+	case 101: // Rabbit
+		return 3
+	}
+	return 20
+}
+
+// attackMob handles a player hitting a mob with knockback and damage.
+func (s *Server) attackMob(attacker *Player, mob *MobEntity) {
+	attacker.mu.Lock()
+	ax, az := attacker.X, attacker.Z
+	attacker.mu.Unlock()
+
+	s.mu.Lock()
+	dx := mob.X - ax
+	dz := mob.Z - az
+	if dist := math.Sqrt(dx*dx + dz*dz); dist > 0 {
+		mob.VX = (dx / dist) * 0.4
+		mob.VY = 0.4
+		mob.VZ = (dz / dist) * 0.4
+	}
+	s.mu.Unlock()
+
+	s.damageMob(mob, 2.0, true)
+}
+
+// damageMob reduces a mob's health and removes it when it dies. Experience
+// is only dropped when the mob was killed by a player.
+// Must be called without s.mu held.
+func (s *Server) damageMob(mob *MobEntity, damage float32, byPlayer bool) {
+	s.mu.Lock()
+	if _, alive := s.mobEntities[mob.EntityID]; !alive {
+		s.mu.Unlock()
+		return
+	}
+	mob.Health -= damage
+	isDead := mob.Health <= 0
+	if isDead {
+		delete(s.mobEntities, mob.EntityID)
+	}
+	x, y, z := mob.X, mob.Y, mob.Z
+	s.mu.Unlock()
+
+	if !isDead {
+		s.broadcastEntityStatus(mob.EntityID, 2) // Hurt
+		return
+	}
+
+	s.broadcastEntityStatus(mob.EntityID, 3) // Dead
+	s.broadcastDestroyEntity(mob.EntityID)
+	if byPlayer {
+		s.SpawnExperience(x, y+0.5, z, mobExperience(mob.MobType))
+	}
+	log.Printf("Mob type %d (EID: %d) died at (%.1f, %.1f, %.1f)", mob.MobType, mob.EntityID, x, y, z)
+}
@@ -2,9 +2,10 @@ package server
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"log"
-	"strconv"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,125 +13,496 @@ import (
 	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
 )
 
+// Command describes a command that players or the console can run.
+type Command struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Permission  int // Minimum op level needed to run the command
+	Syntaxes    []CommandSyntax
+}
+
+// CommandSyntax is one form of a command. The first syntax whose arguments
+// parse successfully is run.
+type CommandSyntax struct {
+	Args []Arg
+	Run  func(ctx *CommandContext)
+}
+
+// Arg is a named command argument.
+type Arg struct {
+	Name     string
+	Parser   ArgParser
+	Optional bool // Optional arguments may only be followed by other optional arguments
+}
+
+// Required returns a required argument.
+func Required(name string, parser ArgParser) Arg {
+	return Arg{Name: name, Parser: parser}
+}
+
+// Optional returns an optional argument.
+func Optional(name string, parser ArgParser) Arg {
+	return Arg{Name: name, Parser: parser, Optional: true}
+}
+
+// Usage returns the usage string of a command, listing every syntax.
+func (c *Command) Usage() string {
+	usages := make([]string, 0, len(c.Syntaxes))
+	for _, syn := range c.Syntaxes {
+		usage := "/" + c.Name
+		for _, arg := range syn.Args {
+			name := arg.Name
+			if choice, ok := arg.Parser.(ChoiceArg); ok {
+				name = strings.Join(choice.Options, "|")
+			}
+			if arg.Optional {
+				usage += " [" + name + "]"
+			} else {
+				usage += " <" + name + ">"
+			}
+		}
+		usages = append(usages, usage)
+	}
+	return strings.Join(usages, " or ")
+}
+
+// CommandContext holds the sender and parsed arguments of a command invocation.
+type CommandContext struct {
+	server *Server
+	Sender *Player // Nil when the command was run from the console
+	args   map[string]any
+}
+
+// SenderName returns the name of whoever ran the command.
+func (c *CommandContext) SenderName() string {
+	if c.Sender == nil {
+		return "Server"
+	}
+	return c.Sender.Username
+}
+
+// Reply sends a message to the command sender.
+func (c *CommandContext) Reply(msg chat.Message) {
+	if c.Sender == nil {
+		log.Print(msg.PlainText())
+		return
+	}
+	c.server.sendChatToPlayer(c.Sender, msg)
+}
+
+// Info sends a gray feedback message to the command sender.
+func (c *CommandContext) Info(text string) {
+	c.Reply(chat.Colored(text, "gray"))
+}
+
+// Error sends a red error message to the command sender.
+func (c *CommandContext) Error(text string) {
+	c.Reply(chat.Colored(text, "red"))
+}
+
+// Has returns true if the named argument was given.
+func (c *CommandContext) Has(name string) bool {
+	_, ok := c.args[name]
+	return ok
+}
+
+// String returns a string argument, or "" if it was not given.
+func (c *CommandContext) String(name string) string {
+	v, _ := c.args[name].(string)
+	return v
+}
+
+// Int returns an integer argument, or 0 if it was not given.
+func (c *CommandContext) Int(name string) int {
+	v, _ := c.args[name].(int)
+	return v
+}
+
+// Float returns a floating point argument, or 0 if it was not given.
+func (c *CommandContext) Float(name string) float64 {
+	v, _ := c.args[name].(float64)
+	return v
+}
+
+// Bool returns a boolean argument, or false if it was not given.
+func (c *CommandContext) Bool(name string) bool {
+	v, _ := c.args[name].(bool)
+	return v
+}
+
+// Player returns a player argument, or nil if it was not given.
+func (c *CommandContext) Player(name string) *Player {
+	v, _ := c.args[name].(*Player)
+	return v
+}
+
+// Value returns the raw parsed value of an argument.
+func (c *CommandContext) Value(name string) any {
+	return c.args[name]
+}
+
+// TargetPlayer returns the player argument with the given name, falling back
+// to the sender. Replies with an error and returns nil if neither exists.
+func (c *CommandContext) TargetPlayer(name string) *Player {
+	if p := c.Player(name); p != nil {
+		return p
+	}
+	if c.Sender == nil {
+		c.Error("You must specify a player when running this command from the console")
+	}
+	return c.Sender
+}
+
+// errArgCount is returned when the number of words does not fit a syntax.
+var errArgCount = errors.New("wrong number of arguments")
+
+// parseArgs parses the given words against a syntax's arguments.
+func parseArgs(ctx *CommandContext, args []Arg, words []string) (map[string]any, error) {
+	parsed := make(map[string]any)
+	ctx.args = parsed
+	for i, arg := range args {
+		if i >= len(words) {
+			if arg.Optional {
+				break
+			}
+			return nil, errArgCount
+		}
+		word := words[i]
+		if _, ok := arg.Parser.(greedyParser); ok {
+			word = strings.Join(words[i:], " ")
+			words = words[:i+1]
+		}
+		v, err := arg.Parser.Parse(ctx, word)
+		if err != nil {
+			return nil, err
+		}
+		parsed[arg.Name] = v
+	}
+	if len(words) > len(args) {
+		return nil, errArgCount
+	}
+	return parsed, nil
+}
+
+// RegisterCommand adds a command to the registry, replacing any command with the same name or alias.
+func (s *Server) RegisterCommand(cmd *Command) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands[strings.ToLower(cmd.Name)] = cmd
+	for _, alias := range cmd.Aliases {
+		s.commands[strings.ToLower(alias)] = cmd
+	}
+}
+
+// lookupCommand finds a command by name or alias.
+func (s *Server) lookupCommand(name string) *Command {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.commands[strings.ToLower(name)]
+}
+
+// availableCommands returns the commands the sender may run, sorted by name.
+func (s *Server) availableCommands(sender *Player) []*Command {
+	level := s.PermissionLevel(sender)
+	s.mu.RLock()
+	var cmds []*Command
+	for name, cmd := range s.commands {
+		if name == strings.ToLower(cmd.Name) && cmd.Permission <= level {
+			cmds = append(cmds, cmd)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+	return cmds
+}
+
 // handleCommand dispatches a /-prefixed command from a player.
 func (s *Server) handleCommand(player *Player, message string) {
-	parts := strings.Fields(message)
-	if len(parts) == 0 {
+	log.Printf("Player %s issued command: %s", player.Username, message)
+	s.dispatchCommand(player, message)
+}
+
+// RunConsoleCommand runs a command typed into the server console with full permissions.
+func (s *Server) RunConsoleCommand(line string) {
+	if strings.TrimSpace(line) == "" {
 		return
 	}
-	cmd := strings.ToLower(parts[0])
-	log.Printf("Player %s issued command: %s", player.Username, message)
+	s.dispatchCommand(nil, line)
+}
 
-	switch cmd {
-	case "/gamemode", "/gm":
-		s.handleGamemodeCommand(player, parts[1:])
-	case "/tp", "/teleport":
-		s.handleTpCommand(player, parts[1:])
-	case "/gamerule":
-		s.handleGameruleCommand(player, parts[1:])
-	case "/stop":
-		s.handleStopCommand(player)
-	default:
-		s.sendChatToPlayer(player, chat.Colored("Unknown command: "+cmd, "red"))
-	}
-}
-
-// handleGamemodeCommand handles the /gamemode command.
-// Usage: /gamemode <survival|creative|adventure|spectator|0|1|2|3>
-func (s *Server) handleGamemodeCommand(player *Player, args []string) {
-	if len(args) < 1 {
-		s.sendChatToPlayer(player, chat.Colored("Usage: /gamemode <survival|creative|adventure|spectator|0|1|2|3>", "red"))
+// dispatchCommand parses and runs a command line for the given sender (nil for the console).
+func (s *Server) dispatchCommand(sender *Player, line string) {
+	parts := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "/"))
+	if len(parts) == 0 {
 		return
 	}
+	ctx := &CommandContext{server: s, Sender: sender}
 
-	var mode byte
-	switch strings.ToLower(args[0]) {
-	case "survival", "s", "0":
-		mode = GameModeSurvival
-	case "creative", "c", "1":
-		mode = GameModeCreative
-	case "adventure", "a", "2":
-		mode = GameModeAdventure
-	case "spectator", "sp", "3":
-		mode = GameModeSpectator
-	default:
-		s.sendChatToPlayer(player, chat.Colored("Unknown gamemode: "+args[0], "red"))
+	cmd := s.lookupCommand(parts[0])
+	if cmd == nil {
+		ctx.Error("Unknown command: /" + parts[0] + ". Type /help for a list of commands.")
+		return
+	}
+	if s.PermissionLevel(sender) < cmd.Permission {
+		ctx.Error("You do not have permission to use this command.")
 		return
 	}
 
-	player.mu.Lock()
-	player.GameMode = mode
-	player.NoClip = mode == GameModeSpectator
-	player.mu.Unlock()
+	var parseErr error
+	for _, syn := range cmd.Syntaxes {
+		args, err := parseArgs(ctx, syn.Args, parts[1:])
+		if err == nil {
+			ctx.args = args
+			syn.Run(ctx)
+			return
+		}
+		if parseErr == nil && !errors.Is(err, errArgCount) {
+			parseErr = err
+		}
+	}
+	if parseErr != nil {
+		ctx.Error(strings.ToUpper(parseErr.Error()[:1]) + parseErr.Error()[1:])
+	}
+	ctx.Error("Usage: " + cmd.Usage())
+}
 
-	// Send Change Game State packet (reason=3 = change game mode)
-	changeGameState := protocol.MarshalPacket(0x2B, func(w *bytes.Buffer) {
-		protocol.WriteByte(w, 3)                // Reason: change game mode
-		protocol.WriteFloat32(w, float32(mode)) // Value: new game mode
-	})
-	player.mu.Lock()
-	protocol.WritePacket(player.Conn, changeGameState)
-	player.mu.Unlock()
+// handleTabComplete handles Serverbound packet 0x14 for command tab completion.
+func (s *Server) handleTabComplete(player *Player, text string) {
+	if matches := s.completeCommand(player, text); len(matches) > 0 {
+		s.sendTabComplete(player, matches)
+	}
+}
 
-	// Send updated abilities
-	s.sendPlayerAbilities(player)
+// completeCommand returns completions for a partially typed command line,
+// generated from the registered command definitions.
+func (s *Server) completeCommand(sender *Player, text string) []string {
+	text = strings.TrimPrefix(text, "/")
+	parts := strings.Split(text, " ")
+	prefix := strings.ToLower(parts[len(parts)-1])
 
-	// Update gamemode in player list for all clients
-	s.broadcastPlayerListGamemode(player)
+	var matches []string
+	if len(parts) == 1 {
+		// Command name completion
+		for _, cmd := range s.availableCommands(sender) {
+			if strings.HasPrefix(cmd.Name, prefix) {
+				matches = append(matches, "/"+cmd.Name)
+			}
+		}
+		return matches
+	}
 
-	// Update entity visibility (spectators are invisible)
-	s.broadcastEntityFlags(player)
+	cmd := s.lookupCommand(parts[0])
+	if cmd == nil || s.PermissionLevel(sender) < cmd.Permission {
+		return nil
+	}
 
-	// Feedback
-	modeName := GameModeName(mode)
-	s.sendChatToPlayer(player, chat.Colored("Game mode set to "+modeName, "gray"))
-	log.Printf("Player %s game mode changed to %s", player.Username, modeName)
-}
-
-// handleTpCommand handles the /tp command.
-// Usage: /tp <x> <y> <z> — teleport to coordinates
-// Usage: /tp <player>    — teleport to another player
-func (s *Server) handleTpCommand(player *Player, args []string) {
-	if len(args) == 3 {
-		// /tp <x> <y> <z>
-		x, err1 := strconv.ParseFloat(args[0], 64)
-		y, err2 := strconv.ParseFloat(args[1], 64)
-		z, err3 := strconv.ParseFloat(args[2], 64)
-		if err1 != nil || err2 != nil || err3 != nil {
-			s.sendChatToPlayer(player, chat.Colored("Invalid coordinates. Usage: /tp <x> <y> <z>", "red"))
-			return
+	// Argument completion: suggest values for the argument being typed in
+	// every syntax whose earlier arguments are valid.
+	argIndex := len(parts) - 2
+	seen := make(map[string]bool)
+	for _, syn := range cmd.Syntaxes {
+		if argIndex >= len(syn.Args) {
+			continue
 		}
-		s.teleportPlayer(player, x, y, z)
-		s.sendChatToPlayer(player, chat.Colored(fmt.Sprintf("Teleported to %.1f, %.1f, %.1f", x, y, z), "gray"))
-		log.Printf("Player %s teleported to %.1f, %.1f, %.1f", player.Username, x, y, z)
-	} else if len(args) == 1 {
-		// /tp <player>
-		targetName := args[0]
-		s.mu.RLock()
-		var target *Player
-		for _, p := range s.players {
-			if strings.EqualFold(p.Username, targetName) {
-				target = p
-				break
+		ctx := &CommandContext{server: s, Sender: sender}
+		if _, err := parseArgs(ctx, syn.Args[:argIndex], parts[1:len(parts)-1]); err != nil {
+			continue
+		}
+		for _, suggestion := range syn.Args[argIndex].Parser.Suggest(ctx) {
+			if !seen[suggestion] && strings.HasPrefix(strings.ToLower(suggestion), prefix) {
+				seen[suggestion] = true
+				matches = append(matches, suggestion)
 			}
 		}
-		s.mu.RUnlock()
+	}
+	sort.Strings(matches)
+	return matches
+}
 
-		if target == nil {
-			s.sendChatToPlayer(player, chat.Colored("Player not found: "+targetName, "red"))
-			return
+// findPlayer returns the online player with the given name (case-insensitive), or nil.
+func (s *Server) findPlayer(name string) *Player {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.players {
+		if strings.EqualFold(p.Username, name) {
+			return p
 		}
+	}
+	return nil
+}
+
+// onlinePlayerNames returns the names of all online players.
+func (s *Server) onlinePlayerNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.players))
+	for _, p := range s.players {
+		names = append(names, p.Username)
+	}
+	return names
+}
+
+// registerDefaultCommands registers the built-in commands.
+func (s *Server) registerDefaultCommands() {
+	s.RegisterCommand(&Command{
+		Name:        "help",
+		Aliases:     []string{"?"},
+		Description: "Lists commands or shows how to use one",
+		Permission:  PermissionAll,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Optional("command", commandNameArg{})},
+			Run:  s.runHelpCommand,
+		}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "gamemode",
+		Aliases:     []string{"gm"},
+		Description: "Changes a player's game mode",
+		Permission:  PermissionGameMode,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Required("mode", GameModeArg{}), Optional("player", PlayerArg{})},
+			Run:  s.runGamemodeCommand,
+		}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "tp",
+		Aliases:     []string{"teleport"},
+		Description: "Teleports a player to coordinates or another player",
+		Permission:  PermissionGameMode,
+		Syntaxes: []CommandSyntax{
+			{
+				Args: []Arg{Required("x", CoordinateArg{'x'}), Required("y", CoordinateArg{'y'}), Required("z", CoordinateArg{'z'})},
+				Run:  s.runTpCommand,
+			},
+			{
+				Args: []Arg{Required("player", PlayerArg{}), Required("x", CoordinateArg{'x'}), Required("y", CoordinateArg{'y'}), Required("z", CoordinateArg{'z'})},
+				Run:  s.runTpCommand,
+			},
+			{
+				Args: []Arg{Required("destination", PlayerArg{})},
+				Run:  s.runTpCommand,
+			},
+			{
+				Args: []Arg{Required("player", PlayerArg{}), Required("destination", PlayerArg{})},
+				Run:  s.runTpCommand,
+			},
+		},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "gamerule",
+		Description: "Lists, shows or changes gamerules",
+		Permission:  PermissionGameMode,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Optional("rule", GameRuleArg{}), Optional("value", GameRuleValueArg{Rule: "rule"})},
+			Run:  s.runGameruleCommand,
+		}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "stop",
+		Description: "Stops the server",
+		Permission:  PermissionOwner,
+		Syntaxes:    []CommandSyntax{{Run: s.runStopCommand}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "deathpos",
+		Description: "Lists where and how a player recently died",
+		Permission:  PermissionGameMode,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Optional("player", PlayerNameArg{})},
+			Run:  s.runDeathPosCommand,
+		}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "op",
+		Description: "Makes a player a server operator",
+		Permission:  PermissionAdmin,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Required("player", PlayerNameArg{}), Optional("level", IntArg{Min: 1, Max: 4})},
+			Run:  s.runOpCommand,
+		}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "deop",
+		Description: "Removes a player's operator status",
+		Permission:  PermissionAdmin,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Required("player", PlayerNameArg{})},
+			Run:  s.runDeopCommand,
+		}},
+	})
+}
+
+// commandNameArg accepts the name of a registered command.
+type commandNameArg struct{}
+
+func (commandNameArg) Parse(ctx *CommandContext, input string) (any, error) {
+	if cmd := ctx.server.lookupCommand(strings.TrimPrefix(input, "/")); cmd != nil {
+		return cmd, nil
+	}
+	return nil, fmt.Errorf("unknown command: %s", input)
+}
+
+func (commandNameArg) Suggest(ctx *CommandContext) []string {
+	var names []string
+	for _, cmd := range ctx.server.availableCommands(ctx.Sender) {
+		names = append(names, cmd.Name)
+	}
+	return names
+}
+
+// runHelpCommand handles /help [command].
+func (s *Server) runHelpCommand(ctx *CommandContext) {
+	if cmd, ok := ctx.Value("command").(*Command); ok {
+		ctx.Info(cmd.Description)
+		ctx.Info("Usage: " + cmd.Usage())
+		return
+	}
+	ctx.Info("Available commands:")
+	for _, cmd := range s.availableCommands(ctx.Sender) {
+		ctx.Info("/" + cmd.Name + " - " + cmd.Description)
+	}
+}
+
+// runGamemodeCommand handles /gamemode <mode> [player].
+func (s *Server) runGamemodeCommand(ctx *CommandContext) {
+	target := ctx.TargetPlayer("player")
+	if target == nil {
+		return
+	}
+	mode := ctx.Value("mode").(byte)
+	s.switchGameMode(target, mode)
+
+	modeName := GameModeName(mode)
+	s.sendChatToPlayer(target, chat.Colored("Game mode set to "+modeName, "gray"))
+	if target != ctx.Sender {
+		ctx.Info(fmt.Sprintf("Set %s's game mode to %s", target.Username, modeName))
+	}
+}
+
+// runTpCommand handles all forms of /tp:
+// /tp <x> <y> <z>, /tp <player> <x> <y> <z>, /tp <destination> and /tp <player> <destination>.
+func (s *Server) runTpCommand(ctx *CommandContext) {
+	target := ctx.TargetPlayer("player")
+	if target == nil {
+		return
+	}
 
-		target.mu.Lock()
-		tx, ty, tz := target.X, target.Y, target.Z
-		target.mu.Unlock()
+	if dest := ctx.Player("destination"); dest != nil {
+		dest.mu.Lock()
+		tx, ty, tz := dest.X, dest.Y, dest.Z
+		dest.mu.Unlock()
 
-		s.teleportPlayer(player, tx, ty, tz)
-		s.sendChatToPlayer(player, chat.Colored("Teleported to "+target.Username, "gray"))
-		log.Printf("Player %s teleported to %s (%.1f, %.1f, %.1f)", player.Username, target.Username, tx, ty, tz)
-	} else {
-		s.sendChatToPlayer(player, chat.Colored("Usage: /tp <x> <y> <z> or /tp <player>", "red"))
+		s.teleportPlayer(target, tx, ty, tz)
+		ctx.Info(fmt.Sprintf("Teleported %s to %s", target.Username, dest.Username))
+		log.Printf("%s teleported %s to %s (%.1f, %.1f, %.1f)", ctx.SenderName(), target.Username, dest.Username, tx, ty, tz)
+		return
 	}
+
+	x, y, z := ctx.Float("x"), ctx.Float("y"), ctx.Float("z")
+	s.teleportPlayer(target, x, y, z)
+	ctx.Info(fmt.Sprintf("Teleported %s to %.1f, %.1f, %.1f", target.Username, x, y, z))
+	log.Printf("%s teleported %s to %.1f, %.1f, %.1f", ctx.SenderName(), target.Username, x, y, z)
 }
 
 // teleportPlayer moves a player to the given coordinates and syncs the change.
@@ -183,9 +555,34 @@ func (s *Server) teleportPlayer(player *Player, x, y, z float64) {
 	}
 }
 
-// handleStopCommand handles the /stop command.
-func (s *Server) handleStopCommand(player *Player) {
-	log.Printf("Player %s issued /stop command, shutting down server...", player.Username)
+// runGameruleCommand handles /gamerule [rule] [value].
+func (s *Server) runGameruleCommand(ctx *CommandContext) {
+	if !ctx.Has("rule") {
+		rules := GameRuleArg{}.Suggest(ctx)
+		sort.Strings(rules)
+		ctx.Info("Gamerules: " + strings.Join(rules, ", "))
+		return
+	}
+
+	rule := ctx.String("rule")
+	if !ctx.Has("value") {
+		ctx.Info(rule + " = " + s.GameRule(rule))
+		return
+	}
+
+	val := ctx.String("value")
+	s.mu.Lock()
+	s.gamerules[rule] = val
+	s.mu.Unlock()
+
+	msg := fmt.Sprintf("Gamerule %s updated to %s", rule, val)
+	log.Printf("%s: %s", ctx.SenderName(), msg)
+	s.broadcastChat(chat.Colored(msg, "gray"))
+}
+
+// runStopCommand handles /stop.
+func (s *Server) runStopCommand(ctx *CommandContext) {
+	log.Printf("%s issued /stop command, shutting down server...", ctx.SenderName())
 	s.broadcastChat(chat.Colored("Server is stopping...", "red"))
 
 	// Give a small delay for the message to propagate if needed,
@@ -196,125 +593,49 @@ func (s *Server) handleStopCommand(player *Player) {
 	}()
 }
 
-// handleGameruleCommand handles the /gamerule command.
-func (s *Server) handleGameruleCommand(player *Player, args []string) {
-	if len(args) == 0 {
-		s.mu.RLock()
-		var rules []string
-		for k := range s.gamerules {
-			rules = append(rules, k)
-		}
-		s.mu.RUnlock()
-		s.sendChatToPlayer(player, chat.Colored("Gamerules: "+strings.Join(rules, ", "), "gray"))
+// runOpCommand handles /op <player> [level].
+// Operators can't grant a level above their own, nor change the level of
+// someone above them.
+func (s *Server) runOpCommand(ctx *CommandContext) {
+	name := ctx.String("player")
+	senderLevel := s.PermissionLevel(ctx.Sender)
+	level := min(DefaultOpLevel, senderLevel)
+	if ctx.Has("level") {
+		level = ctx.Int("level")
+	}
+	if level > senderLevel {
+		ctx.Error(fmt.Sprintf("You can't grant a level above your own (%d)", senderLevel))
 		return
 	}
-
-	rule := args[0]
-	if len(args) == 1 {
-		s.mu.RLock()
-		val, ok := s.gamerules[rule]
-		s.mu.RUnlock()
-		if !ok {
-			s.sendChatToPlayer(player, chat.Colored("Unknown gamerule: "+rule, "red"))
-		} else {
-			s.sendChatToPlayer(player, chat.Colored(rule+" = "+val, "gray"))
-		}
+	if s.opLevel(name) > senderLevel {
+		ctx.Error(fmt.Sprintf("%s has a higher operator level than you", name))
 		return
 	}
-
-	val := args[1]
-
-	s.mu.Lock()
-	if _, ok := s.gamerules[rule]; !ok {
-		s.mu.Unlock()
-		s.sendChatToPlayer(player, chat.Colored("Unknown gamerule: "+rule, "red"))
+	if err := s.SetOp(name, level); err != nil {
+		ctx.Error("Failed to save ops.json: " + err.Error())
 		return
 	}
-	s.gamerules[rule] = val
-	s.mu.Unlock()
-
-	msg := fmt.Sprintf("Gamerule %s updated to %s", rule, val)
-	log.Printf("Player %s: %s", player.Username, msg)
-	s.broadcastChat(chat.Colored(msg, "gray"))
+	ctx.Info(fmt.Sprintf("Made %s a server operator (level %d)", name, level))
+	log.Printf("%s opped %s at level %d", ctx.SenderName(), name, level)
 }
 
-// handleTabComplete handles Serverbound packet 0x14 for command tab completion.
-func (s *Server) handleTabComplete(player *Player, text string) {
-	text = strings.TrimPrefix(text, "/")
-	parts := strings.Split(text, " ")
-
-	var matches []string
-
-	if len(parts) == 1 {
-		// Command name completion
-		cmds := []string{"gamemode", "tp", "gamerule", "stop"}
-		prefix := strings.ToLower(parts[0])
-		for _, cmd := range cmds {
-			if strings.HasPrefix(cmd, prefix) {
-				matches = append(matches, "/"+cmd)
-			}
-		}
-	} else if len(parts) > 1 {
-		// Argument completion
-		cmd := strings.ToLower(parts[0])
-		prefix := strings.ToLower(parts[len(parts)-1])
-
-		switch cmd {
-		case "gamemode", "gm":
-			if len(parts) == 2 {
-				modes := []string{"survival", "creative", "adventure", "spectator"}
-				for _, mode := range modes {
-					if strings.HasPrefix(mode, prefix) {
-						matches = append(matches, mode)
-					}
-				}
-			}
-		case "tp", "teleport":
-			if len(parts) == 2 {
-				s.mu.RLock()
-				for _, p := range s.players {
-					name := p.Username
-					if strings.HasPrefix(strings.ToLower(name), prefix) {
-						matches = append(matches, name)
-					}
-				}
-				s.mu.RUnlock()
-			}
-		case "gamerule":
-			if len(parts) == 2 {
-				s.mu.RLock()
-				for rule := range s.gamerules {
-					if strings.HasPrefix(strings.ToLower(rule), prefix) {
-						matches = append(matches, rule)
-					}
-				}
-				s.mu.RUnlock()
-			} else if len(parts) == 3 {
-				ruleName := parts[1]
-				s.mu.RLock()
-				var currentVal string
-				for k, v := range s.gamerules {
-					if strings.EqualFold(k, ruleName) {
-						currentVal = v
-						break
-					}
-				}
-				s.mu.RUnlock()
-
-				// Only suggest true/false if the rule is currently a boolean
-				if currentVal == "true" || currentVal == "false" {
-					opts := []string{"true", "false"}
-					for _, opt := range opts {
-						if strings.HasPrefix(opt, prefix) {
-							matches = append(matches, opt)
-						}
-					}
-				}
-			}
-		}
+// runDeopCommand handles /deop <player>.
+// Operators can't de-op someone at a higher level than their own.
+func (s *Server) runDeopCommand(ctx *CommandContext) {
+	name := ctx.String("player")
+	if s.opLevel(name) > s.PermissionLevel(ctx.Sender) {
+		ctx.Error(fmt.Sprintf("%s has a higher operator level than you", name))
+		return
 	}
-
-	if len(matches) > 0 {
-		s.sendTabComplete(player, matches)
+	removed, err := s.RemoveOp(name)
+	if err != nil {
+		ctx.Error("Failed to save ops.json: " + err.Error())
+		return
+	}
+	if !removed {
+		ctx.Error(name + " is not an operator")
+		return
 	}
+	ctx.Info("Made " + name + " no longer a server operator")
+	log.Printf("%s de-opped %s", ctx.SenderName(), name)
 }
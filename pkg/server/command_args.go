@@ -0,0 +1,202 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ArgParser parses a single command argument and suggests completions for it.
+type ArgParser interface {
+	// Parse converts the raw argument into a typed value.
+	Parse(ctx *CommandContext, input string) (any, error)
+	// Suggest returns candidate values for tab completion. The caller filters them by prefix.
+	Suggest(ctx *CommandContext) []string
+}
+
+// greedyParser is implemented by parsers that consume the rest of the command line.
+type greedyParser interface {
+	greedy()
+}
+
+// StringArg accepts any single word.
+type StringArg struct{}
+
+func (StringArg) Parse(ctx *CommandContext, input string) (any, error) { return input, nil }
+func (StringArg) Suggest(ctx *CommandContext) []string                 { return nil }
+
+// GreedyStringArg accepts all remaining words joined by spaces. It must be the last argument.
+type GreedyStringArg struct{}
+
+func (GreedyStringArg) Parse(ctx *CommandContext, input string) (any, error) { return input, nil }
+func (GreedyStringArg) Suggest(ctx *CommandContext) []string                 { return nil }
+func (GreedyStringArg) greedy()                                              {}
+
+// IntArg accepts an integer between Min and Max (inclusive). A zero range means unbounded.
+type IntArg struct {
+	Min, Max int
+}
+
+func (a IntArg) Parse(ctx *CommandContext, input string) (any, error) {
+	v, err := strconv.Atoi(input)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid number", input)
+	}
+	if (a.Min != 0 || a.Max != 0) && (v < a.Min || v > a.Max) {
+		return nil, fmt.Errorf("number must be between %d and %d, got %d", a.Min, a.Max, v)
+	}
+	return v, nil
+}
+
+func (IntArg) Suggest(ctx *CommandContext) []string { return nil }
+
+// BoolArg accepts true or false.
+type BoolArg struct{}
+
+func (BoolArg) Parse(ctx *CommandContext, input string) (any, error) {
+	switch strings.ToLower(input) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return nil, fmt.Errorf("%q is not true or false", input)
+}
+
+func (BoolArg) Suggest(ctx *CommandContext) []string { return []string{"true", "false"} }
+
+// CoordinateArg accepts an absolute coordinate or a "~"-prefixed offset from
+// the sender's position along the given axis ('x', 'y' or 'z').
+type CoordinateArg struct {
+	Axis byte
+}
+
+func (a CoordinateArg) Parse(ctx *CommandContext, input string) (any, error) {
+	if !strings.HasPrefix(input, "~") {
+		v, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid coordinate", input)
+		}
+		return v, nil
+	}
+
+	if ctx.Sender == nil {
+		return nil, fmt.Errorf("relative coordinates can only be used by players")
+	}
+	offset := 0.0
+	if rest := input[1:]; rest != "" {
+		v, err := strconv.ParseFloat(rest, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid coordinate", input)
+		}
+		offset = v
+	}
+	ctx.Sender.mu.Lock()
+	defer ctx.Sender.mu.Unlock()
+	switch a.Axis {
+	case 'x':
+		return ctx.Sender.X + offset, nil
+	case 'y':
+		return ctx.Sender.Y + offset, nil
+	default:
+		return ctx.Sender.Z + offset, nil
+	}
+}
+
+func (CoordinateArg) Suggest(ctx *CommandContext) []string { return []string{"~"} }
+
+// PlayerArg accepts the name of an online player and resolves it to the *Player.
+type PlayerArg struct{}
+
+func (PlayerArg) Parse(ctx *CommandContext, input string) (any, error) {
+	if p := ctx.server.findPlayer(input); p != nil {
+		return p, nil
+	}
+	return nil, fmt.Errorf("player not found: %s", input)
+}
+
+func (PlayerArg) Suggest(ctx *CommandContext) []string { return ctx.server.onlinePlayerNames() }
+
+// PlayerNameArg accepts any player name, online or not. Online players are suggested.
+type PlayerNameArg struct{}
+
+func (PlayerNameArg) Parse(ctx *CommandContext, input string) (any, error) { return input, nil }
+func (PlayerNameArg) Suggest(ctx *CommandContext) []string                 { return ctx.server.onlinePlayerNames() }
+
+// ChoiceArg accepts one of a fixed set of words (case-insensitive) and returns it as written in Options.
+type ChoiceArg struct {
+	Options []string
+}
+
+func (a ChoiceArg) Parse(ctx *CommandContext, input string) (any, error) {
+	for _, opt := range a.Options {
+		if strings.EqualFold(opt, input) {
+			return opt, nil
+		}
+	}
+	return nil, fmt.Errorf("%q must be one of: %s", input, strings.Join(a.Options, ", "))
+}
+
+func (a ChoiceArg) Suggest(ctx *CommandContext) []string { return a.Options }
+
+// GameModeArg accepts a gamemode name, abbreviation or number.
+type GameModeArg struct{}
+
+func (GameModeArg) Parse(ctx *CommandContext, input string) (any, error) {
+	if mode, ok := ParseGameMode(input); ok {
+		return mode, nil
+	}
+	return nil, fmt.Errorf("unknown gamemode: %s", input)
+}
+
+func (GameModeArg) Suggest(ctx *CommandContext) []string {
+	return []string{"survival", "creative", "adventure", "spectator"}
+}
+
+// GameRuleArg accepts the name of an existing gamerule.
+type GameRuleArg struct{}
+
+func (GameRuleArg) Parse(ctx *CommandContext, input string) (any, error) {
+	ctx.server.mu.RLock()
+	defer ctx.server.mu.RUnlock()
+	for rule := range ctx.server.gamerules {
+		if strings.EqualFold(rule, input) {
+			return rule, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown gamerule: %s", input)
+}
+
+func (GameRuleArg) Suggest(ctx *CommandContext) []string {
+	ctx.server.mu.RLock()
+	defer ctx.server.mu.RUnlock()
+	rules := make([]string, 0, len(ctx.server.gamerules))
+	for rule := range ctx.server.gamerules {
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// GameRuleValueArg accepts a new value for the gamerule parsed into the argument named Rule.
+// Boolean rules only accept and suggest true or false.
+type GameRuleValueArg struct {
+	Rule string
+}
+
+func (a GameRuleValueArg) Parse(ctx *CommandContext, input string) (any, error) {
+	if current := ctx.server.GameRule(ctx.String(a.Rule)); current == "true" || current == "false" {
+		v, err := BoolArg{}.Parse(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return strconv.FormatBool(v.(bool)), nil
+	}
+	return input, nil
+}
+
+func (a GameRuleValueArg) Suggest(ctx *CommandContext) []string {
+	if current := ctx.server.GameRule(ctx.String(a.Rule)); current == "true" || current == "false" {
+		return []string{"true", "false"}
+	}
+	return nil
+}
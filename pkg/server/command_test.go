@@ -0,0 +1,225 @@
+package server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+// newCommandTestServer returns a server storing its data files in a temporary directory.
+func newCommandTestServer(t *testing.T) *Server {
+	t.Helper()
+	config := DefaultConfig()
+	config.DataDir = t.TempDir()
+	return New(config)
+}
+
+// addCommandTestPlayer adds an online player whose packets are discarded.
+func addCommandTestPlayer(t *testing.T, s *Server, eid int32, name string) *Player {
+	t.Helper()
+	c1, c2 := net.Pipe()
+	t.Cleanup(func() {
+		c1.Close()
+		c2.Close()
+	})
+	go func() {
+		for {
+			if _, err := protocol.ReadPacket(c2); err != nil {
+				return
+			}
+		}
+	}()
+	p := &Player{EntityID: eid, Username: name, UUID: offlineUUID(name), Conn: c1, X: 8, Y: 100, Z: 8, trackedEntities: make(map[int32]bool), loadedChunks: make(map[ChunkPos]bool)}
+	s.players[eid] = p
+	return p
+}
+
+func TestCommandUsage(t *testing.T) {
+	s := newCommandTestServer(t)
+	got := s.lookupCommand("gamemode").Usage()
+	if want := "/gamemode <mode> [player]"; got != want {
+		t.Errorf("Usage() = %q, want %q", got, want)
+	}
+	if s.lookupCommand("gm") != s.lookupCommand("gamemode") {
+		t.Error("alias gm should resolve to /gamemode")
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	s := newCommandTestServer(t)
+	ctx := &CommandContext{server: s}
+	args := []Arg{Required("count", IntArg{Min: 1, Max: 10}), Optional("message", GreedyStringArg{})}
+
+	parsed, err := parseArgs(ctx, args, []string{"3", "hello", "world"})
+	if err != nil {
+		t.Fatalf("parseArgs: %v", err)
+	}
+	if parsed["count"] != 3 || parsed["message"] != "hello world" {
+		t.Errorf("parsed = %v", parsed)
+	}
+
+	if _, err := parseArgs(ctx, args, []string{"11"}); err == nil {
+		t.Error("out of range integer should fail to parse")
+	}
+	if _, err := parseArgs(ctx, args, nil); err == nil {
+		t.Error("missing required argument should fail to parse")
+	}
+}
+
+func TestRelativeCoordinates(t *testing.T) {
+	s := newCommandTestServer(t)
+	player := addCommandTestPlayer(t, s, 1, "Walker")
+	ctx := &CommandContext{server: s, Sender: player}
+
+	v, err := CoordinateArg{Axis: 'y'}.Parse(ctx, "~5")
+	if err != nil || v != 105.0 {
+		t.Errorf("~5 on y = %v, %v; want 105", v, err)
+	}
+	if _, err := (CoordinateArg{Axis: 'x'}).Parse(&CommandContext{server: s}, "~"); err == nil {
+		t.Error("relative coordinates from the console should fail")
+	}
+}
+
+func TestCommandPermissions(t *testing.T) {
+	s := newCommandTestServer(t)
+	player := addCommandTestPlayer(t, s, 1, "Guest")
+
+	s.handleCommand(player, "/gamerule keepInventory true")
+	if s.GameRuleBool("keepInventory") {
+		t.Fatal("non-op should not be able to change gamerules")
+	}
+
+	if err := s.SetOp("Guest", PermissionGameMode); err != nil {
+		t.Fatalf("SetOp: %v", err)
+	}
+	s.handleCommand(player, "/gamerule keepInventory true")
+	if !s.GameRuleBool("keepInventory") {
+		t.Error("level 2 op should be able to change gamerules")
+	}
+
+	s.handleCommand(player, "/op Guest 4")
+	if level := s.PermissionLevel(player); level != PermissionGameMode {
+		t.Errorf("level 2 op promoted themselves to %d", level)
+	}
+}
+
+func TestOpCannotExceedOwnLevel(t *testing.T) {
+	s := newCommandTestServer(t)
+	admin := addCommandTestPlayer(t, s, 1, "Admin")
+	if err := s.SetOp("Admin", PermissionAdmin); err != nil {
+		t.Fatalf("SetOp: %v", err)
+	}
+	if err := s.SetOp("Owner", PermissionOwner); err != nil {
+		t.Fatalf("SetOp: %v", err)
+	}
+
+	s.handleCommand(admin, "/op Admin 4")
+	if level := s.PermissionLevel(admin); level != PermissionAdmin {
+		t.Errorf("level 3 op promoted themselves to %d", level)
+	}
+	s.handleCommand(admin, "/op Helper 4")
+	if level := s.opLevel("Helper"); level != PermissionAll {
+		t.Errorf("level 3 op made Helper level %d", level)
+	}
+
+	// Without a level, a lower op grants their own level rather than the default
+	s.handleCommand(admin, "/op Helper")
+	if level := s.opLevel("Helper"); level != PermissionAdmin {
+		t.Errorf("Helper level = %d, want %d", level, PermissionAdmin)
+	}
+
+	// The owner can't be demoted or de-opped by a lower op
+	s.handleCommand(admin, "/op Owner 1")
+	s.handleCommand(admin, "/deop Owner")
+	if level := s.opLevel("Owner"); level != PermissionOwner {
+		t.Errorf("Owner level = %d after a level 3 op tried to demote them, want %d", level, PermissionOwner)
+	}
+
+	// The console can still grant any level and de-op anyone
+	s.RunConsoleCommand("op Helper 4")
+	if level := s.opLevel("Helper"); level != PermissionOwner {
+		t.Errorf("Helper level = %d after console /op, want %d", level, PermissionOwner)
+	}
+	s.RunConsoleCommand("deop Owner")
+	if level := s.opLevel("Owner"); level != PermissionAll {
+		t.Errorf("Owner level = %d after console /deop, want 0", level)
+	}
+}
+
+func TestConsoleCommand(t *testing.T) {
+	s := newCommandTestServer(t)
+	player := addCommandTestPlayer(t, s, 1, "Builder")
+
+	s.RunConsoleCommand("gamemode creative Builder")
+	if player.GameMode != GameModeCreative {
+		t.Errorf("game mode = %d, want creative", player.GameMode)
+	}
+
+	s.RunConsoleCommand("tp Builder 100 70 -20")
+	if player.X != 100 || player.Y != 70 || player.Z != -20 {
+		t.Errorf("position = %v, %v, %v; want 100, 70, -20", player.X, player.Y, player.Z)
+	}
+}
+
+func TestOpsPersisted(t *testing.T) {
+	s := newCommandTestServer(t)
+	s.RunConsoleCommand("op Notch")
+
+	data, err := os.ReadFile(filepath.Join(s.config.DataDir, "ops.json"))
+	if err != nil {
+		t.Fatalf("ops.json not written: %v", err)
+	}
+	if !strings.Contains(string(data), `"name": "Notch"`) || !strings.Contains(string(data), `"level": 4`) {
+		t.Errorf("unexpected ops.json:\n%s", data)
+	}
+
+	// A fresh server reading the same directory sees the operator
+	s2 := New(s.config)
+	if err := s2.loadOps(); err != nil {
+		t.Fatalf("loadOps: %v", err)
+	}
+	if level := s2.PermissionLevel(&Player{Username: "Notch", UUID: offlineUUID("Notch")}); level != PermissionOwner {
+		t.Errorf("reloaded level = %d, want %d", level, PermissionOwner)
+	}
+
+	s2.RunConsoleCommand("deop Notch")
+	if level := s2.PermissionLevel(&Player{Username: "Notch"}); level != PermissionAll {
+		t.Errorf("level after deop = %d, want 0", level)
+	}
+}
+
+func TestTabCompletion(t *testing.T) {
+	s := newCommandTestServer(t)
+	player := addCommandTestPlayer(t, s, 1, "Alice")
+	addCommandTestPlayer(t, s, 2, "Bob")
+
+	// Non-ops only see commands they can run
+	if got := s.completeCommand(player, "/"); !reflect.DeepEqual(got, []string{"/help"}) {
+		t.Errorf("non-op command completion = %v, want [/help]", got)
+	}
+
+	if err := s.SetOp("Alice", PermissionOwner); err != nil {
+		t.Fatalf("SetOp: %v", err)
+	}
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"/game", []string{"/gamemode", "/gamerule"}},
+		{"/gamemode cr", []string{"creative"}},
+		{"/gamemode creative b", []string{"Bob"}},
+		{"/gamerule keepInventory ", []string{"false", "true"}},
+		{"/tp ~ ", []string{"~"}},
+		{"/tp Bob ", []string{"Alice", "Bob", "~"}},
+	}
+	for _, tt := range tests {
+		if got := s.completeCommand(player, tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("completeCommand(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"bytes"
+	"math/rand"
+	"sync"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// chestWindowID is the window ID used for chest and chest minecart windows.
+// Crafting tables use window 1.
+const chestWindowID = 2
+
+// Container is the inventory of a chest or chest minecart, shared by every
+// player who opens it.
+type Container struct {
+	mu    sync.Mutex
+	Slots [world.ChestSize]Slot
+}
+
+// newContainer creates an empty container.
+func newContainer() *Container {
+	c := &Container{}
+	for i := range c.Slots {
+		c.Slots[i].ItemID = -1
+	}
+	return c
+}
+
+// newLootContainer creates a container filled from a generated chest's loot table.
+func newLootContainer(chest world.LootChest) *Container {
+	c := newContainer()
+	table, ok := world.LootTables[chest.Table]
+	if !ok {
+		return c
+	}
+	for i, stack := range table.Fill(chest.Seed) {
+		if stack.ItemID != -1 {
+			c.Slots[i] = Slot{ItemID: stack.ItemID, Count: stack.Count, Damage: stack.Damage}
+		}
+	}
+	return c
+}
+
+// isChestBlock returns true for chests and trapped chests.
+func isChestBlock(blockID uint16) bool {
+	return blockID == 54 || blockID == 146
+}
+
+// chestContainer returns the inventory of the chest at (x, y, z), creating it
+// the first time the chest is opened. Generated chests are filled from their
+// loot table at that point, once: a chest broken and placed again in the same
+// spot starts empty.
+func (s *Server) chestContainer(x, y, z int32) *Container {
+	pos := world.BlockPos{X: x, Y: y, Z: z}
+	s.mu.RLock()
+	c, ok := s.containers[pos]
+	s.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	chest, hasLoot := s.world.LootChestAt(x, y, z)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.containers[pos]; ok {
+		return existing
+	}
+	if hasLoot && !s.lootedChests[pos] {
+		c = newLootContainer(chest)
+		s.lootedChests[pos] = true
+	} else {
+		c = newContainer()
+	}
+	s.containers[pos] = c
+	return c
+}
+
+// dropChestContents removes the inventory of a broken chest, closes it for
+// anyone looking inside and spills its items where the chest was.
+func (s *Server) dropChestContents(x, y, z int32) {
+	c := s.chestContainer(x, y, z)
+	s.mu.Lock()
+	delete(s.containers, world.BlockPos{X: x, Y: y, Z: z})
+	s.mu.Unlock()
+
+	s.closeContainerViewers(c)
+	s.spillContainer(c, float64(x)+0.5, float64(y)+0.5, float64(z)+0.5)
+}
+
+// spillContainer empties a container, dropping its items at (x, y, z).
+func (s *Server) spillContainer(c *Container, x, y, z float64) {
+	c.mu.Lock()
+	var items []Slot
+	for i, sl := range c.Slots {
+		if sl.ItemID != -1 {
+			items = append(items, sl)
+			c.Slots[i] = Slot{ItemID: -1}
+		}
+	}
+	c.mu.Unlock()
+
+	for _, item := range items {
+		vx := rand.Float64()*0.2 - 0.1
+		vz := rand.Float64()*0.2 - 0.1
+		s.SpawnItem(x, y, z, vx, 0.2, vz, item.ItemID, item.Damage, item.Count)
+	}
+}
+
+// openContainer shows a container to the player in a chest window.
+func (s *Server) openContainer(player *Player, c *Container, title string) {
+	player.mu.Lock()
+	defer player.mu.Unlock()
+	player.OpenWindowID = chestWindowID
+	player.OpenContainer = c
+	player.OpenMerchant = nil
+	if player.Conn == nil {
+		return
+	}
+	openPkt := protocol.MarshalPacket(0x2D, func(w *bytes.Buffer) {
+		protocol.WriteByte(w, chestWindowID)
+		protocol.WriteString(w, "minecraft:chest")
+		protocol.WriteString(w, `{"text":"`+title+`"}`)
+		protocol.WriteByte(w, world.ChestSize)
+	})
+	protocol.WritePacket(player.Conn, openPkt)
+	protocol.WritePacket(player.Conn, windowItemsPacket(player))
+}
+
+// closeContainerViewers closes the window of every player looking into c.
+func (s *Server) closeContainerViewers(c *Container) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.players {
+		p.mu.Lock()
+		if p.OpenContainer == c {
+			windowID := p.OpenWindowID
+			p.OpenContainer = nil
+			p.OpenWindowID = 0
+			if p.Conn != nil {
+				protocol.WritePacket(p.Conn, protocol.MarshalPacket(0x2E, func(w *bytes.Buffer) {
+					protocol.WriteByte(w, windowID)
+				}))
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+// syncContainerViewers resends the window contents to every player other
+// than except who is looking into c, after except changed it.
+func (s *Server) syncContainerViewers(c *Container, except *Player) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.players {
+		if p == except {
+			continue
+		}
+		p.mu.Lock()
+		if p.OpenContainer == c && p.Conn != nil {
+			protocol.WritePacket(p.Conn, windowItemsPacket(p))
+		}
+		p.mu.Unlock()
+	}
+}
+
+// windowLayout describes the slots of the player's open window: containerSlots
+// window slots belonging to the crafting table, chest or trade window,
+// followed by the player's 27 main inventory slots and 9 hotbar slots.
+func windowLayout(player *Player) (containerSlots, totalSlots int16) {
+	if player.OpenContainer != nil {
+		containerSlots = world.ChestSize
+	} else if player.OpenMerchant != nil {
+		containerSlots = 3 // Two inputs and the output
+	} else {
+		containerSlots = 10 // Crafting output and 3x3 grid
+	}
+	return containerSlots, containerSlots + 36
+}
+
+// windowSlot returns window slot n of the player's open window.
+// Must be called with player.mu and the open container's lock held.
+func windowSlot(player *Player, n int16) Slot {
+	containerSlots, totalSlots := windowLayout(player)
+	switch {
+	case n < 0 || n >= totalSlots:
+		return Slot{ItemID: -1}
+	case n >= containerSlots:
+		return player.Inventory[n-containerSlots+9]
+	case player.OpenContainer != nil:
+		return player.OpenContainer.Slots[n]
+	case player.OpenMerchant != nil:
+		return player.MerchantSlots[n]
+	case n == 0:
+		return player.CraftTableOutput
+	}
+	return player.CraftTableGrid[n-1]
+}
+
+// setWindowSlot sets window slot n of the player's open window.
+// Must be called with player.mu and the open container's lock held.
+func setWindowSlot(player *Player, n int16, sl Slot) {
+	containerSlots, totalSlots := windowLayout(player)
+	switch {
+	case n < 0 || n >= totalSlots:
+	case n >= containerSlots:
+		player.Inventory[n-containerSlots+9] = sl
+	case player.OpenContainer != nil:
+		player.OpenContainer.Slots[n] = sl
+	case player.OpenMerchant != nil:
+		player.MerchantSlots[n] = sl
+	case n == 0:
+		player.CraftTableOutput = sl
+	default:
+		player.CraftTableGrid[n-1] = sl
+	}
+}
+
+// windowItemsPacket builds a Window Items packet with the full contents of
+// the player's open window. Must be called with player.mu held and the open
+// container's lock not held.
+func windowItemsPacket(player *Player) *protocol.Packet {
+	_, totalSlots := windowLayout(player)
+	if c := player.OpenContainer; c != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	return protocol.MarshalPacket(0x30, func(w *bytes.Buffer) {
+		protocol.WriteByte(w, player.OpenWindowID)
+		protocol.WriteInt16(w, totalSlots)
+		for i := int16(0); i < totalSlots; i++ {
+			sl := windowSlot(player, i)
+			protocol.WriteSlotData(w, sl.ItemID, sl.Count, sl.Damage)
+		}
+	})
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// addContainerTestPlayer adds an online survival player with an empty inventory.
+func addContainerTestPlayer(t *testing.T, s *Server, eid int32, name string) *Player {
+	t.Helper()
+	p := addCommandTestPlayer(t, s, eid, name)
+	for i := range p.Inventory {
+		p.Inventory[i] = Slot{ItemID: -1}
+	}
+	p.Cursor = Slot{ItemID: -1}
+	return p
+}
+
+// findDungeon returns a generated dungeon chest and spawner of the server's world.
+func findDungeon(t *testing.T, s *Server) (world.LootChest, world.MobSpawner) {
+	t.Helper()
+	for cx := int32(-8); cx < 8; cx++ {
+		for cz := int32(-8); cz < 8; cz++ {
+			f := s.world.Features(cx, cz)
+			for _, sp := range f.Spawners {
+				if sp.Mob != "CaveSpider" && len(f.Chests) > 0 {
+					return f.Chests[0], sp
+				}
+			}
+		}
+	}
+	t.Fatal("no dungeon with a chest found")
+	return world.LootChest{}, world.MobSpawner{}
+}
+
+func TestChestLootFilledOnce(t *testing.T) {
+	config := DefaultConfig()
+	config.DataDir = t.TempDir()
+	config.Seed = 12345
+	s := New(config)
+	chest, _ := findDungeon(t, s)
+
+	c := s.chestContainer(chest.Pos.X, chest.Pos.Y, chest.Pos.Z)
+	want := world.LootTables[chest.Table].Fill(chest.Seed)
+	for i, stack := range want {
+		got := c.Slots[i]
+		if got.ItemID != stack.ItemID || (stack.ItemID != -1 && (got.Count != stack.Count || got.Damage != stack.Damage)) {
+			t.Errorf("slot %d = %+v, want %+v", i, got, stack)
+		}
+	}
+
+	// Taken loot stays taken
+	c.Slots[0] = Slot{ItemID: -1}
+	if again := s.chestContainer(chest.Pos.X, chest.Pos.Y, chest.Pos.Z); again != c {
+		t.Error("chest container recreated on second open")
+	}
+
+	// Breaking the chest and placing it again does not refill it
+	s.dropChestContents(chest.Pos.X, chest.Pos.Y, chest.Pos.Z)
+	for _, sl := range s.chestContainer(chest.Pos.X, chest.Pos.Y, chest.Pos.Z).Slots {
+		if sl.ItemID != -1 {
+			t.Fatalf("replaced loot chest refilled with %+v", sl)
+		}
+	}
+
+	// A player-placed chest starts empty
+	for _, sl := range s.chestContainer(100, 70, 100).Slots {
+		if sl.ItemID != -1 {
+			t.Fatalf("new chest has item %+v", sl)
+		}
+	}
+}
+
+func TestChestWindowClick(t *testing.T) {
+	s := newCommandTestServer(t)
+	p := addContainerTestPlayer(t, s, 1, "Looter")
+	c := s.chestContainer(0, 70, 0)
+	c.Slots[3] = Slot{ItemID: 264, Count: 5} // Diamonds
+	s.openContainer(p, c, "Chest")
+	if p.OpenWindowID != chestWindowID || p.OpenContainer != c {
+		t.Fatal("chest window not opened")
+	}
+
+	// Shift-click moves the diamonds into the player's inventory
+	s.handleWindowClick(p, chestWindowID, 3, 0, 1, 1)
+	if c.Slots[3].ItemID != -1 {
+		t.Errorf("chest slot 3 = %+v, want empty", c.Slots[3])
+	}
+	found := -1
+	for i, sl := range p.Inventory {
+		if sl.ItemID == 264 && sl.Count == 5 {
+			found = i
+		}
+	}
+	if found < 9 {
+		t.Fatalf("diamonds not in inventory (slot %d)", found)
+	}
+
+	// Pick them up from the inventory and put them in chest slot 0
+	winSlot := int16(found - 9 + world.ChestSize)
+	s.handleWindowClick(p, chestWindowID, winSlot, 0, 2, 0)
+	s.handleWindowClick(p, chestWindowID, 0, 0, 3, 0)
+	if c.Slots[0].ItemID != 264 || c.Slots[0].Count != 5 {
+		t.Errorf("chest slot 0 = %+v, want 5 diamonds", c.Slots[0])
+	}
+	if p.Cursor.ItemID != -1 {
+		t.Errorf("cursor = %+v, want empty", p.Cursor)
+	}
+
+	// Closing the window leaves the items in the chest
+	s.handleCloseWindow(p, bytes.NewReader([]byte{chestWindowID}))
+	if p.OpenContainer != nil || p.OpenWindowID != 0 {
+		t.Error("chest window still open")
+	}
+	if c.Slots[0].ItemID != 264 {
+		t.Error("closing the window emptied the chest")
+	}
+}
+
+func TestChestSharedSlotClick(t *testing.T) {
+	s := newCommandTestServer(t)
+	c := s.chestContainer(0, 70, 0)
+	players := []*Player{
+		addContainerTestPlayer(t, s, 1, "Looter"),
+		addContainerTestPlayer(t, s, 2, "Rival"),
+	}
+	for _, p := range players {
+		s.openContainer(p, c, "Chest")
+	}
+	for range 1000 {
+		c.Slots[0] = Slot{ItemID: 264, Count: 1}
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		for _, p := range players {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				s.handleWindowClick(p, chestWindowID, 0, 0, 1, 1) // Both shift-click the diamond
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		total := 0
+		for _, p := range players {
+			for i, sl := range p.Inventory {
+				if sl.ItemID == 264 {
+					total += int(sl.Count)
+					p.Inventory[i] = Slot{ItemID: -1}
+				}
+			}
+		}
+		if total != 1 || c.Slots[0].ItemID != -1 {
+			t.Fatalf("one diamond became %d in inventories, chest slot %+v", total, c.Slots[0])
+		}
+	}
+}
+
+func TestBreakChestDropsContents(t *testing.T) {
+	s := newCommandTestServer(t)
+	p := addContainerTestPlayer(t, s, 1, "Looter")
+	s.world.SetBlock(0, 70, 0, 54<<4)
+	c := s.chestContainer(0, 70, 0)
+	c.Slots[0] = Slot{ItemID: 264, Count: 5}
+	c.Slots[10] = Slot{ItemID: 1, Count: 64}
+	s.openContainer(p, c, "Chest")
+
+	s.handleBlockBreak(p, 0, 70, 0)
+
+	if p.OpenContainer != nil {
+		t.Error("window of broken chest still open")
+	}
+	if _, ok := s.containers[world.BlockPos{X: 0, Y: 70, Z: 0}]; ok {
+		t.Error("broken chest still has a container")
+	}
+	items := make(map[int16]int)
+	for _, e := range s.entities {
+		items[e.ItemID] += int(e.Count)
+	}
+	if items[264] != 5 || items[1] != 64 || items[54] != 1 {
+		t.Errorf("dropped items = %v, want 5 diamonds, 64 stone and the chest", items)
+	}
+}
+
+func TestChestMinecart(t *testing.T) {
+	s := newCommandTestServer(t)
+	p := addContainerTestPlayer(t, s, 1, "Looter")
+	cart := &MinecartEntity{EntityID: 500, X: 8.5, Y: 100, Z: 8.5, Container: newLootContainer(world.LootChest{Table: world.LootMineshaft, Seed: 7})}
+	s.minecarts[cart.EntityID] = cart
+
+	s.handleInteractEntity(p, cart.EntityID)
+	if p.OpenContainer != cart.Container {
+		t.Fatal("interacting did not open the minecart's chest")
+	}
+	loot := 0
+	for _, sl := range cart.Container.Slots {
+		if sl.ItemID != -1 {
+			loot += int(sl.Count)
+		}
+	}
+
+	s.handleAttack(p, cart.EntityID)
+	if _, ok := s.minecarts[cart.EntityID]; ok {
+		t.Fatal("minecart not removed when attacked")
+	}
+	if p.OpenContainer != nil {
+		t.Error("window of broken minecart still open")
+	}
+	dropped := 0
+	for _, e := range s.entities {
+		dropped += int(e.Count)
+	}
+	if want := loot + 2; dropped != want { // Loot, minecart and chest
+		t.Errorf("dropped %d items, want %d", dropped, want)
+	}
+}
+
+func TestSpawnerSpawnsMobs(t *testing.T) {
+	config := DefaultConfig()
+	config.DataDir = t.TempDir()
+	config.Seed = 12345
+	s := New(config)
+	_, sp := findDungeon(t, s)
+	p := addContainerTestPlayer(t, s, 1, "Looter")
+	p.X, p.Y, p.Z = float64(sp.Pos.X)+0.5, float64(sp.Pos.Y), float64(sp.Pos.Z)+2.5
+
+	for i := 0; i < 10 && len(s.mobEntities) == 0; i++ {
+		s.tickSpawners(spawnerMaxDelay)
+	}
+	if len(s.mobEntities) == 0 {
+		t.Fatal("spawner did not spawn any mobs")
+	}
+	for _, mob := range s.mobEntities {
+		if mob.MobType != spawnerMobTypes[sp.Mob] {
+			t.Errorf("spawned mob type %d, want %s", mob.MobType, sp.Mob)
+		}
+	}
+
+	// Nobody nearby: the spawner stops
+	p.X += 100
+	before := len(s.mobEntities)
+	s.tickSpawners(spawnerMaxDelay)
+	if len(s.mobEntities) != before {
+		t.Error("spawner spawned mobs with no player nearby")
+	}
+}
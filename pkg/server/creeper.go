@@ -0,0 +1,89 @@
+package server
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+// MobTypeCreeper is the Spawn Mob type ID of creepers.
+const MobTypeCreeper byte = 50
+
+// Creeper behaviour (vanilla values).
+const (
+	creeperFuseTicks   = 30   // Ticks of swelling before the creeper explodes
+	creeperSwellRange  = 3.0  // Distance at which a creeper starts to swell
+	creeperCancelRange = 7.0  // Distance at which a swelling creeper calms down
+	creeperChaseRange  = 16.0 // Distance within which a creeper follows players
+	creeperWalkSpeed   = 0.15
+)
+
+// creeperAI is the AIFunc for creepers. It follows the nearest vulnerable
+// player and swells when close, exploding once the fuse runs out.
+// Called from tickEntityPhysics with s.mu held.
+func creeperAI(mob *MobEntity, s *Server) {
+	found := false
+	best := creeperChaseRange * creeperChaseRange
+	var tx, tz float64
+	for _, p := range s.players {
+		p.mu.Lock()
+		if !p.IsDead && (p.GameMode == GameModeSurvival || p.GameMode == GameModeAdventure) {
+			dx, dy, dz := p.X-mob.X, p.Y-mob.Y, p.Z-mob.Z
+			if d := dx*dx + dy*dy + dz*dz; d < best {
+				best, tx, tz, found = d, p.X, p.Z, true
+			}
+		}
+		p.mu.Unlock()
+	}
+
+	if !found {
+		mob.Swelling = false
+	} else {
+		dist := math.Sqrt(best)
+		dx, dz := tx-mob.X, tz-mob.Z
+		mob.Yaw = float32(math.Atan2(dz, dx)*180/math.Pi) - 90
+
+		if horizontal := math.Sqrt(dx*dx + dz*dz); horizontal > 1.5 && mob.OnGround && !mob.Swelling {
+			mob.VX = dx / horizontal * creeperWalkSpeed
+			mob.VZ = dz / horizontal * creeperWalkSpeed
+		}
+
+		if dist < creeperSwellRange {
+			mob.Swelling = true
+		} else if dist > creeperCancelRange {
+			mob.Swelling = false
+		}
+	}
+
+	if mob.Swelling {
+		mob.Fuse++
+	} else if mob.Fuse > 0 {
+		mob.Fuse--
+	}
+}
+
+// broadcastCreeperState sends the creeper swell state (metadata index 16) so
+// clients show the creeper flashing and growing before it explodes.
+func (s *Server) broadcastCreeperState(entityID int32, swelling bool) {
+	state := int8(-1)
+	if swelling {
+		state = 1
+	}
+	pkt := protocol.MarshalPacket(0x1C, func(w *bytes.Buffer) {
+		protocol.WriteVarInt(w, entityID)
+		protocol.WriteByte(w, (0<<5)|16) // Index 16, type 0 (byte)
+		protocol.WriteByte(w, byte(state))
+		protocol.WriteByte(w, 0x7F) // Metadata terminator
+	})
+
+	s.sendToEntityViewers(entityID, pkt)
+}
+
+// detonateCreeper removes a creeper whose fuse ran out and explodes it.
+// Blocks are only destroyed when the mobGriefing gamerule is enabled.
+// Must be called without s.mu held.
+func (s *Server) detonateCreeper(mob *MobEntity) {
+	s.broadcastDestroyEntity(mob.EntityID)
+	s.Explode(mob.X, mob.Y, mob.Z, CreeperExplosionPower, s.GameRuleBool("mobGriefing"), "was blown up by Creeper")
+}
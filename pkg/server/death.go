@@ -0,0 +1,136 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// DeathRecord describes where and how a player died.
+type DeathRecord struct {
+	X, Y, Z float64
+	Cause   string
+	Time    time.Time
+}
+
+// maxDeathRecords is the number of deaths remembered per player.
+const maxDeathRecords = 10
+
+// handlePlayerDeath records a player's death and, unless the keepInventory
+// gamerule is set, scatters their inventory and part of their experience
+// at the death location. Called from applyDamage once the player is dead.
+func (s *Server) handlePlayerDeath(player *Player, cause string) {
+	player.mu.Lock()
+	x, y, z := player.X, player.Y, player.Z
+	name := player.Username
+	player.mu.Unlock()
+
+	s.recordDeath(name, DeathRecord{X: x, Y: y, Z: z, Cause: cause, Time: time.Now()})
+
+	if s.GameRuleBool("keepInventory") {
+		return
+	}
+
+	s.dropInventory(player)
+	s.dropDeathExperience(player)
+}
+
+// recordDeath stores a death for the given player, discarding the oldest records beyond maxDeathRecords.
+func (s *Server) recordDeath(username string, record DeathRecord) {
+	key := strings.ToLower(username)
+	s.mu.Lock()
+	records := append(s.deaths[key], record)
+	if len(records) > maxDeathRecords {
+		records = records[len(records)-maxDeathRecords:]
+	}
+	s.deaths[key] = records
+	s.mu.Unlock()
+}
+
+// Deaths returns the recorded deaths of a player, oldest first.
+func (s *Server) Deaths(username string) []DeathRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	records := s.deaths[strings.ToLower(username)]
+	return append([]DeathRecord(nil), records...)
+}
+
+// dropInventory scatters every item the player holds (inventory, armour,
+// crafting grids, trade inputs and cursor) around them and clears the inventory.
+func (s *Server) dropInventory(player *Player) {
+	var drops []Slot
+
+	player.mu.Lock()
+	x, y, z := player.X, player.Y, player.Z
+	// Slot 0 is the crafting output, which is not a real item
+	for i := 1; i < len(player.Inventory); i++ {
+		if player.Inventory[i].ItemID > 0 {
+			drops = append(drops, player.Inventory[i])
+		}
+		player.Inventory[i] = Slot{ItemID: -1}
+	}
+	player.Inventory[0] = Slot{ItemID: -1}
+	for i := range player.CraftTableGrid {
+		if player.CraftTableGrid[i].ItemID > 0 {
+			drops = append(drops, player.CraftTableGrid[i])
+		}
+		player.CraftTableGrid[i] = Slot{ItemID: -1}
+	}
+	player.CraftTableOutput = Slot{ItemID: -1}
+	for i := range player.MerchantSlots[:2] {
+		if player.MerchantSlots[i].ItemID > 0 {
+			drops = append(drops, player.MerchantSlots[i])
+		}
+	}
+	player.MerchantSlots = [3]Slot{{ItemID: -1}, {ItemID: -1}, {ItemID: -1}}
+	if player.Cursor.ItemID > 0 {
+		drops = append(drops, player.Cursor)
+	}
+	player.Cursor = Slot{ItemID: -1}
+	player.mu.Unlock()
+
+	for _, slot := range drops {
+		// Fling each stack in a random direction, like vanilla
+		speed := rand.Float64() * 0.5
+		angle := rand.Float64() * 2 * math.Pi
+		vx := -math.Sin(angle) * speed
+		vz := math.Cos(angle) * speed
+		s.SpawnItem(x, y+1.32, z, vx, 0.2, vz, slot.ItemID, slot.Damage, slot.Count)
+	}
+
+	// Others should no longer see the dropped item in the player's hand
+	s.broadcastHeldItem(player)
+
+	if len(drops) > 0 {
+		log.Printf("Player %s dropped %d item stacks on death at (%.1f, %.1f, %.1f)", player.Username, len(drops), x, y, z)
+	}
+}
+
+// runDeathPosCommand handles /deathpos [player], listing the most recent
+// deaths of a player (default: the sender).
+func (s *Server) runDeathPosCommand(ctx *CommandContext) {
+	name := ctx.String("player")
+	if name == "" {
+		if ctx.Sender == nil {
+			ctx.Error("You must specify a player when running this command from the console")
+			return
+		}
+		name = ctx.Sender.Username
+	}
+
+	records := s.Deaths(name)
+	if len(records) == 0 {
+		ctx.Info("No deaths recorded for " + name)
+		return
+	}
+
+	ctx.Info(fmt.Sprintf("Deaths of %s (most recent first):", name))
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+		ago := time.Since(r.Time).Round(time.Second)
+		ctx.Info(fmt.Sprintf("%.1f, %.1f, %.1f - %s (%s ago)", r.X, r.Y, r.Z, r.Cause, ago))
+	}
+}
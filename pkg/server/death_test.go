@@ -0,0 +1,103 @@
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+// newDyingPlayer returns a connected survival player holding a few items and one hit point left.
+func newDyingPlayer(t *testing.T, s *Server) *Player {
+	c1, c2 := net.Pipe()
+	t.Cleanup(func() {
+		c1.Close()
+		c2.Close()
+	})
+	go func() {
+		for {
+			if _, err := protocol.ReadPacket(c2); err != nil {
+				return
+			}
+		}
+	}()
+
+	p := &Player{EntityID: 1, Username: "Unlucky", Health: 1, GameMode: GameModeSurvival, Conn: c1, X: 8, Y: 200, Z: 8, trackedEntities: make(map[int32]bool)}
+	for i := range p.Inventory {
+		p.Inventory[i] = Slot{ItemID: -1}
+	}
+	p.Inventory[36] = Slot{ItemID: 276, Count: 1} // Diamond sword in the hotbar
+	p.Inventory[20] = Slot{ItemID: 4, Count: 64}  // Cobblestone in the main inventory
+	p.Inventory[5] = Slot{ItemID: 310, Count: 1}  // Diamond helmet
+	s.players[p.EntityID] = p
+	return p
+}
+
+func TestDeathDropsInventory(t *testing.T) {
+	s := New(DefaultConfig())
+	p := newDyingPlayer(t, s)
+
+	s.applyDamage(p, 5, "fell from a high place")
+
+	p.mu.Lock()
+	for i, slot := range p.Inventory {
+		if slot.ItemID > 0 {
+			t.Errorf("slot %d should be empty after death, has item %d", i, slot.ItemID)
+		}
+	}
+	p.mu.Unlock()
+
+	s.mu.RLock()
+	dropped := len(s.entities)
+	s.mu.RUnlock()
+	if dropped != 3 {
+		t.Errorf("dropped %d item entities, want 3", dropped)
+	}
+
+	deaths := s.Deaths("unlucky")
+	if len(deaths) != 1 {
+		t.Fatalf("recorded %d deaths, want 1", len(deaths))
+	}
+	if d := deaths[0]; d.X != 8 || d.Y != 200 || d.Z != 8 || d.Cause != "fell from a high place" {
+		t.Errorf("unexpected death record %+v", d)
+	}
+}
+
+func TestKeepInventory(t *testing.T) {
+	s := New(DefaultConfig())
+	s.gamerules["keepInventory"] = "true"
+	p := newDyingPlayer(t, s)
+
+	s.applyDamage(p, 5, "died")
+
+	p.mu.Lock()
+	sword := p.Inventory[36]
+	p.mu.Unlock()
+	if sword.ItemID != 276 {
+		t.Errorf("keepInventory should keep the sword, slot has item %d", sword.ItemID)
+	}
+	s.mu.RLock()
+	dropped := len(s.entities)
+	s.mu.RUnlock()
+	if dropped != 0 {
+		t.Errorf("keepInventory should not drop items, dropped %d", dropped)
+	}
+	if len(s.Deaths("Unlucky")) != 1 {
+		t.Error("deaths should be recorded even with keepInventory")
+	}
+}
+
+func TestDeathRecordsAreCapped(t *testing.T) {
+	s := New(DefaultConfig())
+	for i := 0; i < maxDeathRecords+5; i++ {
+		s.recordDeath("Steve", DeathRecord{Y: float64(i)})
+	}
+
+	deaths := s.Deaths("steve")
+	if len(deaths) != maxDeathRecords {
+		t.Fatalf("kept %d deaths, want %d", len(deaths), maxDeathRecords)
+	}
+	if deaths[len(deaths)-1].Y != maxDeathRecords+4 {
+		t.Errorf("most recent death should be kept, got Y=%v", deaths[len(deaths)-1].Y)
+	}
+}
@@ -0,0 +1,391 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// Limits for the region editing commands.
+const (
+	maxEditVolume    = 256 * 256 * 16 // Blocks in one /copy or /paste
+	editHistoryLimit = maxEditVolume  // Replaced blocks each player keeps for /undo
+	schematicDir     = "schematics"   // Directory under the data dir holding .schematic files
+)
+
+// schematicNamePattern restricts schematic names so they cannot leave the schematic directory.
+var schematicNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// registerEditCommands registers the region selection, clipboard and
+// schematic commands.
+func (s *Server) registerEditCommands() {
+	for i, name := range []string{"pos1", "pos2"} {
+		corner := i
+		run := func(ctx *CommandContext) { s.runPosCommand(ctx, corner) }
+		s.RegisterCommand(&Command{
+			Name:        name,
+			Description: fmt.Sprintf("Sets corner %d of your selection to your position or the given block", corner+1),
+			Permission:  PermissionAdmin,
+			Syntaxes: []CommandSyntax{
+				{Run: run},
+				{
+					Args: []Arg{Required("x", CoordinateArg{'x'}), Required("y", CoordinateArg{'y'}), Required("z", CoordinateArg{'z'})},
+					Run:  run,
+				},
+			},
+		})
+	}
+	s.RegisterCommand(&Command{
+		Name:        "copy",
+		Description: "Copies the selected region to your clipboard",
+		Permission:  PermissionAdmin,
+		Syntaxes:    []CommandSyntax{{Run: s.runCopyCommand}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "paste",
+		Description: "Pastes your clipboard where you are standing",
+		Permission:  PermissionAdmin,
+		Syntaxes:    []CommandSyntax{{Run: s.runPasteCommand}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "rotate",
+		Description: "Turns your clipboard clockwise",
+		Permission:  PermissionAdmin,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Optional("degrees", ChoiceArg{Options: []string{"90", "180", "270"}})},
+			Run:  s.runRotateCommand,
+		}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "undo",
+		Description: "Undoes your last paste",
+		Permission:  PermissionAdmin,
+		Syntaxes:    []CommandSyntax{{Run: s.runUndoCommand}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "schematic",
+		Description: "Saves your clipboard to or loads it from a .schematic file",
+		Permission:  PermissionAdmin,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Required("action", ChoiceArg{Options: []string{"save", "load"}}), Required("name", StringArg{})},
+			Run:  s.runSchematicCommand,
+		}},
+	})
+}
+
+// editSender returns the player running a region editing command, replying
+// with an error when it was run from the console.
+func editSender(ctx *CommandContext) *Player {
+	if ctx.Sender == nil {
+		ctx.Error("This command can only be run by a player")
+	}
+	return ctx.Sender
+}
+
+// playerBlockPos returns the block the player's feet are in.
+func playerBlockPos(p *Player) world.BlockPos {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return world.BlockPos{X: int32(math.Floor(p.X)), Y: int32(math.Floor(p.Y)), Z: int32(math.Floor(p.Z))}
+}
+
+// runPosCommand handles /pos1 and /pos2.
+func (s *Server) runPosCommand(ctx *CommandContext, corner int) {
+	player := editSender(ctx)
+	if player == nil {
+		return
+	}
+	pos := playerBlockPos(player)
+	if ctx.Has("x") {
+		pos = world.BlockPos{X: int32(math.Floor(ctx.Float("x"))), Y: int32(math.Floor(ctx.Float("y"))), Z: int32(math.Floor(ctx.Float("z")))}
+	}
+	pos.Y = max(0, min(255, pos.Y))
+
+	player.mu.Lock()
+	player.Selection[corner] = &pos
+	other := player.Selection[1-corner]
+	player.mu.Unlock()
+
+	msg := fmt.Sprintf("Position %d set to %d, %d, %d", corner+1, pos.X, pos.Y, pos.Z)
+	if other != nil {
+		msg += fmt.Sprintf(" (%d blocks selected)", selectionVolume(pos, *other))
+	}
+	ctx.Info(msg)
+}
+
+// selectionVolume returns the number of blocks in the box with corners a and b.
+func selectionVolume(a, b world.BlockPos) int {
+	dx := abs32(a.X-b.X) + 1
+	dy := abs32(a.Y-b.Y) + 1
+	dz := abs32(a.Z-b.Z) + 1
+	return int(dx) * int(dy) * int(dz)
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// runCopyCommand handles /copy, storing the selection in the clipboard
+// relative to the sender's position.
+func (s *Server) runCopyCommand(ctx *CommandContext) {
+	player := editSender(ctx)
+	if player == nil {
+		return
+	}
+	player.mu.Lock()
+	a, b := player.Selection[0], player.Selection[1]
+	player.mu.Unlock()
+	if a == nil || b == nil {
+		ctx.Error("Set both corners of the region with /pos1 and /pos2 first")
+		return
+	}
+	if n := selectionVolume(*a, *b); n > maxEditVolume {
+		ctx.Error(fmt.Sprintf("The selection has %d blocks, at most %d can be copied", n, maxEditVolume))
+		return
+	}
+
+	minX, minY, minZ := min(a.X, b.X), min(a.Y, b.Y), min(a.Z, b.Z)
+	schem := newSchematic(int(abs32(a.X-b.X))+1, int(abs32(a.Y-b.Y))+1, int(abs32(a.Z-b.Z))+1)
+	for y := 0; y < schem.Height; y++ {
+		for z := 0; z < schem.Length; z++ {
+			for x := 0; x < schem.Width; x++ {
+				schem.Set(x, y, z, s.world.GetBlock(minX+int32(x), minY+int32(y), minZ+int32(z)))
+			}
+		}
+	}
+	origin := playerBlockPos(player)
+	schem.OffsetX, schem.OffsetY, schem.OffsetZ = int(minX-origin.X), int(minY-origin.Y), int(minZ-origin.Z)
+
+	player.mu.Lock()
+	player.Clipboard = schem
+	player.mu.Unlock()
+	ctx.Info(fmt.Sprintf("Copied %d blocks to your clipboard", len(schem.Blocks)))
+}
+
+// runPasteCommand handles /paste, placing the clipboard at the sender's
+// position and remembering the replaced blocks for /undo.
+func (s *Server) runPasteCommand(ctx *CommandContext) {
+	player := editSender(ctx)
+	if player == nil {
+		return
+	}
+	player.mu.Lock()
+	schem := player.Clipboard
+	player.mu.Unlock()
+	if schem == nil {
+		ctx.Error("Your clipboard is empty, /copy a region first")
+		return
+	}
+
+	origin := playerBlockPos(player)
+	baseX := origin.X + int32(schem.OffsetX)
+	baseY := origin.Y + int32(schem.OffsetY)
+	baseZ := origin.Z + int32(schem.OffsetZ)
+	changes := make([]blockChange, 0, len(schem.Blocks))
+	for y := 0; y < schem.Height; y++ {
+		wy := baseY + int32(y)
+		if wy < 0 || wy > 255 {
+			continue
+		}
+		for z := 0; z < schem.Length; z++ {
+			for x := 0; x < schem.Width; x++ {
+				pos := world.BlockPos{X: baseX + int32(x), Y: wy, Z: baseZ + int32(z)}
+				changes = append(changes, blockChange{Pos: pos, State: schem.At(x, y, z)})
+			}
+		}
+	}
+	undo := s.setBlocks(changes)
+
+	player.mu.Lock()
+	player.editHistory = append(player.editHistory, undo)
+	trimEditHistory(player)
+	player.mu.Unlock()
+	ctx.Info(fmt.Sprintf("Pasted %d blocks", len(changes)))
+	log.Printf("%s pasted %d blocks at %d, %d, %d", player.Username, len(changes), baseX, baseY, baseZ)
+}
+
+// trimEditHistory drops the player's oldest pastes until the blocks kept for
+// /undo fit in editHistoryLimit. The newest paste is always kept.
+// Must be called with player.mu held.
+func trimEditHistory(player *Player) {
+	total := 0
+	for _, edit := range player.editHistory {
+		total += len(edit)
+	}
+	for len(player.editHistory) > 1 && total > editHistoryLimit {
+		total -= len(player.editHistory[0])
+		player.editHistory[0] = nil
+		player.editHistory = player.editHistory[1:]
+	}
+}
+
+// releaseEditState frees a disconnecting player's clipboard and undo history.
+func (s *Server) releaseEditState(player *Player) {
+	player.mu.Lock()
+	player.Clipboard = nil
+	player.editHistory = nil
+	player.Selection = [2]*world.BlockPos{}
+	player.mu.Unlock()
+}
+
+// runRotateCommand handles /rotate [degrees].
+func (s *Server) runRotateCommand(ctx *CommandContext) {
+	player := editSender(ctx)
+	if player == nil {
+		return
+	}
+	turns := 1
+	switch ctx.String("degrees") {
+	case "180":
+		turns = 2
+	case "270":
+		turns = 3
+	}
+
+	player.mu.Lock()
+	schem := player.Clipboard
+	if schem != nil {
+		player.Clipboard = schem.Rotate(turns)
+	}
+	player.mu.Unlock()
+	if schem == nil {
+		ctx.Error("Your clipboard is empty, /copy a region first")
+		return
+	}
+	ctx.Info(fmt.Sprintf("Rotated your clipboard %d degrees clockwise", turns*90))
+}
+
+// runUndoCommand handles /undo, restoring the blocks replaced by the
+// sender's last paste.
+func (s *Server) runUndoCommand(ctx *CommandContext) {
+	player := editSender(ctx)
+	if player == nil {
+		return
+	}
+	player.mu.Lock()
+	n := len(player.editHistory)
+	if n == 0 {
+		player.mu.Unlock()
+		ctx.Error("Nothing to undo")
+		return
+	}
+	last := player.editHistory[n-1]
+	player.editHistory = player.editHistory[:n-1]
+	player.mu.Unlock()
+
+	s.setBlocks(last)
+	ctx.Info(fmt.Sprintf("Restored %d blocks", len(last)))
+}
+
+// runSchematicCommand handles /schematic save|load <name>.
+func (s *Server) runSchematicCommand(ctx *CommandContext) {
+	player := editSender(ctx)
+	if player == nil {
+		return
+	}
+	name := ctx.String("name")
+	if !schematicNamePattern.MatchString(name) {
+		ctx.Error("Schematic names may only contain letters, digits, '-' and '_'")
+		return
+	}
+	path := filepath.Join(s.dataPath(schematicDir), name+".schematic")
+
+	if ctx.String("action") == "save" {
+		player.mu.Lock()
+		schem := player.Clipboard
+		player.mu.Unlock()
+		if schem == nil {
+			ctx.Error("Your clipboard is empty, /copy a region first")
+			return
+		}
+		if err := saveSchematic(path, schem); err != nil {
+			log.Printf("Failed to save schematic %s: %v", path, err)
+			ctx.Error("Failed to save the schematic: " + err.Error())
+			return
+		}
+		ctx.Info(fmt.Sprintf("Saved your clipboard as %s", name))
+		return
+	}
+
+	schem, err := loadSchematic(path)
+	if errors.Is(err, os.ErrNotExist) {
+		ctx.Error(fmt.Sprintf("There is no schematic called %s", name))
+		return
+	}
+	if err != nil {
+		ctx.Error("Failed to load the schematic: " + err.Error())
+		return
+	}
+	player.mu.Lock()
+	player.Clipboard = schem
+	player.mu.Unlock()
+	ctx.Info(fmt.Sprintf("Loaded %s (%dx%dx%d) into your clipboard", name, schem.Width, schem.Height, schem.Length))
+}
+
+// saveSchematic writes a schematic file, creating its directory if needed.
+func saveSchematic(path string, schem *Schematic) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := schem.WriteSchematic(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadSchematic reads a schematic file, refusing ones too big to paste.
+func loadSchematic(path string) (*Schematic, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSchematic(f, maxEditVolume)
+}
+
+// setBlocks sets many blocks at once. Their changes are batched per chunk at
+// the end of the tick like any other block change. Chests that are replaced
+// lose their contents. It returns the blocks that were there before, so the
+// edit can be undone.
+func (s *Server) setBlocks(changes []blockChange) []blockChange {
+	previous := make([]blockChange, 0, len(changes))
+	for _, c := range changes {
+		old := s.world.GetBlock(c.Pos.X, c.Pos.Y, c.Pos.Z)
+		if old == c.State {
+			continue
+		}
+		previous = append(previous, blockChange{Pos: c.Pos, State: old})
+		if isChestBlock(old >> 4) {
+			s.forgetContainer(c.Pos)
+		}
+		s.world.SetBlock(c.Pos.X, c.Pos.Y, c.Pos.Z, c.State)
+		s.queueBlockChange(c.Pos, c.State)
+	}
+	return previous
+}
+
+// forgetContainer discards the inventory of a chest that was replaced
+// without being broken, closing it for anyone looking inside.
+func (s *Server) forgetContainer(pos world.BlockPos) {
+	s.mu.Lock()
+	c, ok := s.containers[pos]
+	delete(s.containers, pos)
+	s.mu.Unlock()
+	if ok {
+		s.closeContainerViewers(c)
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"testing"
+)
+
+func TestCopyPasteUndo(t *testing.T) {
+	s := newCommandTestServer(t)
+	p := addCommandTestPlayer(t, s, 1, "Builder")
+	if err := s.SetOp("Builder", PermissionAdmin); err != nil {
+		t.Fatalf("SetOp: %v", err)
+	}
+
+	// A 3x2x2 build next to the player, who stands at (8, 100, 8)
+	for x := int32(0); x < 3; x++ {
+		for z := int32(0); z < 2; z++ {
+			s.world.SetBlock(x, 100, z, 1<<4)
+			s.world.SetBlock(x, 101, z, 5<<4|uint16(x))
+		}
+	}
+	s.handleCommand(p, "/pos1 0 100 0")
+	s.handleCommand(p, "/pos2 2 101 1")
+	s.handleCommand(p, "/copy")
+	if p.Clipboard == nil || len(p.Clipboard.Blocks) != 12 {
+		t.Fatalf("clipboard = %+v, want 12 blocks", p.Clipboard)
+	}
+
+	// Pasting from 40 blocks east puts the copy 40 blocks east of the original
+	before := s.world.GetBlock(41, 101, 1)
+	p.X = 48.5
+	s.handleCommand(p, "/paste")
+	for x := int32(0); x < 3; x++ {
+		for z := int32(0); z < 2; z++ {
+			if got := s.world.GetBlock(40+x, 100, z); got != 1<<4 {
+				t.Errorf("pasted block at (%d, 100, %d) = %d, want stone", 40+x, z, got)
+			}
+		}
+	}
+	if got := s.world.GetBlock(41, 101, 1); got != 5<<4|1 {
+		t.Errorf("pasted planks = %d:%d, want 5:1", got>>4, got&15)
+	}
+
+	s.handleCommand(p, "/undo")
+	if got := s.world.GetBlock(41, 101, 1); got != before {
+		t.Errorf("block after undo = %d, want %d", got, before)
+	}
+	if len(p.editHistory) != 0 {
+		t.Errorf("%d edits left to undo, want 0", len(p.editHistory))
+	}
+
+	// Rotating turns the clipboard about the player
+	s.handleCommand(p, "/rotate 90")
+	if p.Clipboard.Width != 2 || p.Clipboard.Length != 3 {
+		t.Errorf("rotated clipboard is %dx%d, want 2x3", p.Clipboard.Width, p.Clipboard.Length)
+	}
+}
+
+func TestSchematicSaveLoad(t *testing.T) {
+	s := newCommandTestServer(t)
+	p := addCommandTestPlayer(t, s, 1, "Builder")
+	if err := s.SetOp("Builder", PermissionAdmin); err != nil {
+		t.Fatalf("SetOp: %v", err)
+	}
+
+	saved := newSchematic(2, 2, 2)
+	saved.Set(1, 1, 1, 89<<4)
+	saved.OffsetY = 3
+	p.Clipboard = saved
+	s.handleCommand(p, "/schematic save tower")
+
+	p.Clipboard = nil
+	s.handleCommand(p, "/schematic load tower")
+	if p.Clipboard == nil || p.Clipboard.At(1, 1, 1) != 89<<4 || p.Clipboard.OffsetY != 3 {
+		t.Fatalf("loaded clipboard = %+v", p.Clipboard)
+	}
+
+	// Names cannot reach outside the schematic directory
+	p.Clipboard = nil
+	s.handleCommand(p, "/schematic load ../ops")
+	if p.Clipboard != nil {
+		t.Error("loaded a schematic from outside the schematic directory")
+	}
+}
+
+func TestEditHistoryBoundedByBlocks(t *testing.T) {
+	s := newCommandTestServer(t)
+	p := addCommandTestPlayer(t, s, 1, "Builder")
+	p.editHistory = [][]blockChange{
+		make([]blockChange, editHistoryLimit/2),
+		make([]blockChange, editHistoryLimit/2),
+	}
+	p.editHistory = append(p.editHistory, make([]blockChange, 10))
+	trimEditHistory(p)
+	if len(p.editHistory) != 2 || len(p.editHistory[1]) != 10 {
+		t.Errorf("history has %d pastes after trimming, want the 2 newest", len(p.editHistory))
+	}
+
+	// A paste as big as the limit on its own is still kept
+	p.editHistory = append(p.editHistory, make([]blockChange, editHistoryLimit))
+	trimEditHistory(p)
+	if len(p.editHistory) != 1 || len(p.editHistory[0]) != editHistoryLimit {
+		t.Errorf("history has %d pastes, want only the newest", len(p.editHistory))
+	}
+
+	s.releaseEditState(p)
+	if p.editHistory != nil || p.Clipboard != nil {
+		t.Error("edit state kept after release")
+	}
+}
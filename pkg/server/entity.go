@@ -29,6 +29,10 @@ type MobEntity struct {
 	Yaw, Pitch float32
 	HeadPitch  float32
 	OnGround   bool
+	Fuse       int  // Creeper: ticks spent swelling towards an explosion
+	Swelling   bool // Creeper: whether the creeper is currently swelling
+	Health     float32
+	Villager   *Villager // Villager: trades and wandering state
 	// AIFunc is an optional AI callback invoked each tick. Can be nil.
 	AIFunc func(mob *MobEntity, s *Server)
 }
@@ -237,13 +241,29 @@ func (s *Server) tickEntityPhysics() {
 	}
 	var movedMobs []movedMob
 
+	type creeperSwell struct {
+		entityID int32
+		swelling bool
+	}
+	var swellChanged []creeperSwell
+	var explodedCreepers []*MobEntity
+
 	for _, mob := range s.mobEntities {
 		const mobWidth = 0.6
 		const mobHeight = 1.8
 
+		wasSwelling := mob.Swelling
 		if mob.AIFunc != nil {
 			mob.AIFunc(mob, s)
 		}
+		if mob.Swelling != wasSwelling {
+			swellChanged = append(swellChanged, creeperSwell{mob.EntityID, mob.Swelling})
+		}
+		if mob.MobType == MobTypeCreeper && mob.Fuse >= creeperFuseTicks {
+			delete(s.mobEntities, mob.EntityID)
+			explodedCreepers = append(explodedCreepers, mob)
+			continue
+		}
 
 		blockAtMob := s.world.GetBlock(int32(math.Floor(mob.X)), int32(math.Floor(mob.Y)), int32(math.Floor(mob.Z)))
 		mobCenterID := blockAtMob >> 4
@@ -312,8 +332,62 @@ func (s *Server) tickEntityPhysics() {
 		movedMobs = append(movedMobs, movedMob{mob.EntityID, mob.X, mob.Y, mob.Z, mob.VX, mob.VY, mob.VZ, mob.Yaw, mob.Pitch, mob.OnGround})
 	}
 
+	type movedFallingBlock struct {
+		entityID   int32
+		x, y, z    float64
+		vx, vy, vz float64
+	}
+	var movedBlocks []movedFallingBlock
+	fallingBlocks, landings := s.tickFallingBlocks()
+	for _, fb := range fallingBlocks {
+		movedBlocks = append(movedBlocks, movedFallingBlock{fb.EntityID, fb.X, fb.Y, fb.Z, fb.VX, fb.VY, fb.VZ})
+	}
+
+	type movedTNT struct {
+		entityID   int32
+		x, y, z    float64
+		vx, vy, vz float64
+	}
+	var movedTNTs []movedTNT
+	primedTNT, explodedTNT := s.tickPrimedTNT()
+	for _, tnt := range primedTNT {
+		movedTNTs = append(movedTNTs, movedTNT{tnt.EntityID, tnt.X, tnt.Y, tnt.Z, tnt.VX, tnt.VY, tnt.VZ})
+	}
+
+	type movedOrb struct {
+		entityID   int32
+		x, y, z    float64
+		vx, vy, vz float64
+	}
+	var movedOrbs []movedOrb
+	orbs, expiredOrbs := s.tickExperienceOrbs()
+	for _, orb := range orbs {
+		movedOrbs = append(movedOrbs, movedOrb{orb.EntityID, orb.X, orb.Y, orb.Z, orb.VX, orb.VY, orb.VZ})
+	}
+
 	s.mu.Unlock()
 
+	// Place (or drop) falling blocks that landed this tick
+	for _, l := range landings {
+		s.landFallingBlock(l)
+	}
+
+	for _, c := range swellChanged {
+		s.broadcastCreeperState(c.entityID, c.swelling)
+	}
+
+	// Explosions may prime more TNT, which is picked up on the next tick
+	for _, tnt := range explodedTNT {
+		s.detonateTNT(tnt)
+	}
+	for _, mob := range explodedCreepers {
+		s.detonateCreeper(mob)
+	}
+
+	for _, eid := range expiredOrbs {
+		s.broadcastDestroyEntity(eid)
+	}
+
 	// Destroy items that touched cactus
 	for _, eid := range cactusDestroyed {
 		s.mu.Lock()
@@ -334,6 +408,18 @@ func (s *Server) tickEntityPhysics() {
 		s.broadcastEntityTeleportByID(m.entityID, m.x, m.y, m.z, m.yaw, m.pitch, m.onGround)
 		s.broadcastEntityVelocity(m.entityID, m.vx, m.vy, m.vz)
 	}
+	for _, m := range movedBlocks {
+		s.broadcastEntityTeleportByID(m.entityID, m.x, m.y, m.z, 0, 0, false)
+		s.broadcastEntityVelocity(m.entityID, m.vx, m.vy, m.vz)
+	}
+	for _, m := range movedTNTs {
+		s.broadcastEntityTeleportByID(m.entityID, m.x, m.y, m.z, 0, 0, false)
+		s.broadcastEntityVelocity(m.entityID, m.vx, m.vy, m.vz)
+	}
+	for _, m := range movedOrbs {
+		s.broadcastEntityTeleportByID(m.entityID, m.x, m.y, m.z, 0, 0, false)
+		s.broadcastEntityVelocity(m.entityID, m.vx, m.vy, m.vz)
+	}
 }
 
 // SpawnItem creates an item entity at the given position and broadcasts it.
@@ -405,7 +491,7 @@ func (s *Server) broadcastSpawnItem(item *ItemEntity) {
 				protocol.WritePacket(p.Conn, spawnObj)
 				protocol.WritePacket(p.Conn, velocityPkt)
 				protocol.WritePacket(p.Conn, metadata)
-				p.trackedEntities[item.EntityID] = true
+				s.trackEntity(p, item.EntityID)
 			}
 			p.mu.Unlock()
 		}
@@ -414,27 +500,29 @@ func (s *Server) broadcastSpawnItem(item *ItemEntity) {
 
 // SpawnMob creates a mob entity at the given position and broadcasts it to all players.
 func (s *Server) SpawnMob(x, y, z float64, mobType byte) {
+	s.spawnMobEntity(&MobEntity{MobType: mobType, X: x, Y: y, Z: z})
+}
+
+// spawnMobEntity gives a new mob its entity ID and health, adds it to the
+// world and broadcasts it to all players.
+func (s *Server) spawnMobEntity(mob *MobEntity) {
 	s.mu.Lock()
-	eid := s.nextEID
+	mob.EntityID = s.nextEID
 	s.nextEID++
-
-	mob := &MobEntity{
-		EntityID: eid,
-		MobType:  mobType,
-		X:        x,
-		Y:        y,
-		Z:        z,
+	mob.Health = mobMaxHealth(mob.MobType)
+	if mob.MobType == MobTypeCreeper {
+		mob.AIFunc = creeperAI
 	}
-	s.mobEntities[eid] = mob
+	s.mobEntities[mob.EntityID] = mob
 	s.mu.Unlock()
 
 	s.broadcastSpawnMob(mob)
-	log.Printf("Spawned mob type %d (EID: %d) at (%.1f, %.1f, %.1f)", mobType, eid, x, y, z)
+	log.Printf("Spawned mob type %d (EID: %d) at (%.1f, %.1f, %.1f)", mob.MobType, mob.EntityID, mob.X, mob.Y, mob.Z)
 }
 
-func (s *Server) broadcastSpawnMob(mob *MobEntity) {
-	// Spawn Mob packet - 0x0F
-	pkt := protocol.MarshalPacket(0x0F, func(w *bytes.Buffer) {
+// spawnMobPacket builds the Spawn Mob (0x0F) packet for a mob.
+func spawnMobPacket(mob *MobEntity) *protocol.Packet {
+	return protocol.MarshalPacket(0x0F, func(w *bytes.Buffer) {
 		protocol.WriteVarInt(w, mob.EntityID)
 		protocol.WriteByte(w, mob.MobType)
 		protocol.WriteInt32(w, int32(mob.X*32))
@@ -446,8 +534,16 @@ func (s *Server) broadcastSpawnMob(mob *MobEntity) {
 		protocol.WriteInt16(w, int16(mob.VX*8000))
 		protocol.WriteInt16(w, int16(mob.VY*8000))
 		protocol.WriteInt16(w, int16(mob.VZ*8000))
-		protocol.WriteByte(w, 0x7F) // Metadata terminator (no extra metadata)
+		if mob.Villager != nil {
+			protocol.WriteByte(w, (2<<5)|16) // Index 16, type 2 (int): profession
+			protocol.WriteInt32(w, int32(mob.Villager.Profession))
+		}
+		protocol.WriteByte(w, 0x7F) // Metadata terminator
 	})
+}
+
+func (s *Server) broadcastSpawnMob(mob *MobEntity) {
+	pkt := spawnMobPacket(mob)
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -456,7 +552,7 @@ func (s *Server) broadcastSpawnMob(mob *MobEntity) {
 			p.mu.Lock()
 			if p.Conn != nil {
 				protocol.WritePacket(p.Conn, pkt)
-				p.trackedEntities[mob.EntityID] = true
+				s.trackEntity(p, mob.EntityID)
 			}
 			p.mu.Unlock()
 		}
@@ -471,7 +567,7 @@ func (s *Server) spawnEntitiesForPlayer(player *Player) {
 		if s.shouldTrack(player, entity.X, entity.Y, entity.Z) {
 			s.sendItemToPlayer(player, entity)
 			player.mu.Lock()
-			player.trackedEntities[entity.EntityID] = true
+			s.trackEntity(player, entity.EntityID)
 			player.mu.Unlock()
 		}
 	}
@@ -520,27 +616,14 @@ func (s *Server) spawnMobEntitiesForPlayer(player *Player) {
 		if s.shouldTrack(player, mob.X, mob.Y, mob.Z) {
 			s.sendMobToPlayer(player, mob)
 			player.mu.Lock()
-			player.trackedEntities[mob.EntityID] = true
+			s.trackEntity(player, mob.EntityID)
 			player.mu.Unlock()
 		}
 	}
 }
 
 func (s *Server) sendMobToPlayer(player *Player, mob *MobEntity) {
-	pkt := protocol.MarshalPacket(0x0F, func(w *bytes.Buffer) {
-		protocol.WriteVarInt(w, mob.EntityID)
-		protocol.WriteByte(w, mob.MobType)
-		protocol.WriteInt32(w, int32(mob.X*32))
-		protocol.WriteInt32(w, int32(mob.Y*32))
-		protocol.WriteInt32(w, int32(mob.Z*32))
-		protocol.WriteByte(w, byte(mob.Yaw*256/360))
-		protocol.WriteByte(w, byte(mob.Pitch*256/360))
-		protocol.WriteByte(w, byte(mob.HeadPitch*256/360))
-		protocol.WriteInt16(w, int16(mob.VX*8000))
-		protocol.WriteInt16(w, int16(mob.VY*8000))
-		protocol.WriteInt16(w, int16(mob.VZ*8000))
-		protocol.WriteByte(w, 0x7F) // Metadata terminator
-	})
+	pkt := spawnMobPacket(mob)
 
 	player.mu.Lock()
 	if player.Conn != nil {
@@ -554,16 +637,7 @@ func (s *Server) broadcastCollectItem(collectedID, collectorID int32) {
 		protocol.WriteVarInt(w, collectedID)
 		protocol.WriteVarInt(w, collectorID)
 	})
-
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, p := range s.players {
-		p.mu.Lock()
-		if p.Conn != nil {
-			protocol.WritePacket(p.Conn, pkt)
-		}
-		p.mu.Unlock()
-	}
+	s.sendToEntityViewers(collectedID, pkt)
 }
 
 func (s *Server) itemPickupLoop(player *Player, stop chan struct{}) {
@@ -15,8 +15,8 @@ func TestShouldTrack(t *testing.T) {
 		want       bool
 	}{
 		{"In range", 10, 0, 0, true},
-		{"Exactly at range", EntityTrackingRange, 0, 0, true},
-		{"Out of range", EntityTrackingRange + 1, 0, 0, false},
+		{"Exactly at range", DefaultEntityTrackingRange, 0, 0, true},
+		{"Out of range", DefaultEntityTrackingRange + 1, 0, 0, false},
 		{"Far away", 100, 100, 100, false},
 	}
 
@@ -57,7 +57,7 @@ func TestUpdateEntityTracking_Despawn(t *testing.T) {
 	srv := New(DefaultConfig())
 
 	p1 := &Player{EntityID: 1, X: 0, Y: 0, Z: 0, trackedEntities: make(map[int32]bool), loadedChunks: make(map[ChunkPos]bool)}
-	p2 := &Player{EntityID: 2, X: EntityTrackingRange + 10, Y: 0, Z: 0, trackedEntities: make(map[int32]bool), loadedChunks: make(map[ChunkPos]bool)}
+	p2 := &Player{EntityID: 2, X: DefaultEntityTrackingRange + 10, Y: 0, Z: 0, trackedEntities: make(map[int32]bool), loadedChunks: make(map[ChunkPos]bool)}
 
 	// Pre-set p1 tracking p2 even though p2 is far away
 	p1.trackedEntities[2] = true
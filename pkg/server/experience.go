@@ -0,0 +1,316 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+// ExperienceOrb represents an experience orb entity (Spawn Experience Orb 0x11).
+type ExperienceOrb struct {
+	EntityID   int32
+	X, Y, Z    float64
+	VX, VY, VZ float64
+	Value      int16
+	SpawnTime  time.Time
+}
+
+// Experience orb behaviour (vanilla values).
+const (
+	xpOrbAttractRange = 8.0             // Orbs fly towards players within this distance
+	xpOrbPickupRange  = 1.5             // Orbs within this distance are collected
+	xpOrbLifetime     = 5 * time.Minute // Orbs despawn after this long
+	maxDeathXP        = 100             // Most experience a player can drop on death
+)
+
+// xpOrbSizes are the values experience is split into when spawning orbs, largest first.
+var xpOrbSizes = []int{2477, 1237, 617, 307, 149, 73, 37, 17, 7, 3, 1}
+
+// xpBarCap returns the experience needed to advance from the given level to the next.
+func xpBarCap(level int32) int32 {
+	switch {
+	case level >= 30:
+		return 112 + (level-30)*9
+	case level >= 15:
+		return 37 + (level-15)*5
+	}
+	return 7 + level*2
+}
+
+// oreExperience returns a random experience amount for mining the given block, or 0.
+func oreExperience(blockID uint16) int {
+	switch blockID {
+	case 16: // Coal ore
+		return rand.Intn(3)
+	case 56, 129: // Diamond ore, Emerald ore
+		return 3 + rand.Intn(5)
+	case 21, 153: // Lapis ore, Nether quartz ore
+		return 2 + rand.Intn(4)
+	case 73, 74: // Redstone ore (lit and unlit)
+		return 1 + rand.Intn(5)
+	}
+	return 0
+}
+
+// mobExperience returns a random experience amount for killing the given mob type.
+func mobExperience(mobType byte) int {
+	switch {
+	case mobType == 61: // Blaze
+		return 10
+	case mobType == 65: // Bat
+		return 1 + rand.Intn(3)
+	case mobType >= 50 && mobType <= 68: // Hostile mobs
+		return 5
+	case mobType >= 90 && mobType <= 101 && mobType != 97 && mobType != 99: // Animals (not golems)
+		return 1 + rand.Intn(3)
+	}
+	return 0
+}
+
+// addExperience gives a player experience points, levelling up as needed.
+// Must be called with player.mu held.
+func addExperience(player *Player, amount int32) {
+	player.XPTotal += amount
+	player.XPProgress += float32(amount) / float32(xpBarCap(player.XPLevel))
+	for player.XPProgress >= 1 {
+		player.XPProgress = (player.XPProgress - 1) * float32(xpBarCap(player.XPLevel))
+		player.XPLevel++
+		player.XPProgress /= float32(xpBarCap(player.XPLevel))
+	}
+}
+
+// sendExperience sends a Set Experience packet (0x1F) with the player's current level and progress.
+func (s *Server) sendExperience(player *Player) {
+	player.mu.Lock()
+	defer player.mu.Unlock()
+	pkt := protocol.MarshalPacket(0x1F, func(w *bytes.Buffer) {
+		protocol.WriteFloat32(w, player.XPProgress)
+		protocol.WriteVarInt(w, player.XPLevel)
+		protocol.WriteVarInt(w, player.XPTotal)
+	})
+	if player.Conn != nil {
+		protocol.WritePacket(player.Conn, pkt)
+	}
+}
+
+// dropDeathExperience drops part of a dead player's experience as orbs
+// (7 points per level, capped at 100) and resets their experience to zero.
+func (s *Server) dropDeathExperience(player *Player) {
+	player.mu.Lock()
+	amount := min(int(player.XPLevel)*7, maxDeathXP)
+	x, y, z := player.X, player.Y, player.Z
+	player.XPLevel = 0
+	player.XPProgress = 0
+	player.XPTotal = 0
+	player.mu.Unlock()
+
+	s.sendExperience(player)
+	s.SpawnExperience(x, y, z, amount)
+}
+
+// SpawnExperience spawns experience orbs worth the given total at the given position.
+// Large amounts are split into several orbs like vanilla does.
+func (s *Server) SpawnExperience(x, y, z float64, total int) {
+	for total > 0 {
+		value := 1
+		for _, size := range xpOrbSizes {
+			if total >= size {
+				value = size
+				break
+			}
+		}
+		total -= value
+		s.spawnExperienceOrb(x, y, z, int16(value))
+	}
+}
+
+func (s *Server) spawnExperienceOrb(x, y, z float64, value int16) {
+	s.mu.Lock()
+	eid := s.nextEID
+	s.nextEID++
+
+	orb := &ExperienceOrb{
+		EntityID:  eid,
+		X:         x,
+		Y:         y,
+		Z:         z,
+		VX:        (rand.Float64()*0.2 - 0.1) * 2,
+		VY:        rand.Float64() * 0.2 * 2,
+		VZ:        (rand.Float64()*0.2 - 0.1) * 2,
+		Value:     value,
+		SpawnTime: time.Now(),
+	}
+	s.xpOrbs[eid] = orb
+	s.mu.Unlock()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.players {
+		if s.shouldTrack(p, orb.X, orb.Y, orb.Z) {
+			s.sendExperienceOrbToPlayer(p, orb)
+			p.mu.Lock()
+			s.trackEntity(p, orb.EntityID)
+			p.mu.Unlock()
+		}
+	}
+}
+
+func (s *Server) sendExperienceOrbToPlayer(player *Player, orb *ExperienceOrb) {
+	// Spawn Experience Orb - 0x11
+	pkt := protocol.MarshalPacket(0x11, func(w *bytes.Buffer) {
+		protocol.WriteVarInt(w, orb.EntityID)
+		protocol.WriteInt32(w, int32(orb.X*32))
+		protocol.WriteInt32(w, int32(orb.Y*32))
+		protocol.WriteInt32(w, int32(orb.Z*32))
+		protocol.WriteInt16(w, orb.Value)
+	})
+
+	player.mu.Lock()
+	if player.Conn != nil {
+		protocol.WritePacket(player.Conn, pkt)
+	}
+	player.mu.Unlock()
+}
+
+func (s *Server) spawnExperienceOrbsForPlayer(player *Player) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, orb := range s.xpOrbs {
+		if s.shouldTrack(player, orb.X, orb.Y, orb.Z) {
+			s.sendExperienceOrbToPlayer(player, orb)
+			player.mu.Lock()
+			s.trackEntity(player, orb.EntityID)
+			player.mu.Unlock()
+		}
+	}
+}
+
+// tickExperienceOrbs advances all experience orbs by one tick, pulling them
+// towards the nearest living player within range.
+// Must be called with s.mu held. Returns the orbs that moved and those that expired.
+func (s *Server) tickExperienceOrbs() (moved []*ExperienceOrb, expired []int32) {
+	const gravity = 0.03
+	const drag = 0.98
+	const size = 0.5
+
+	for eid, orb := range s.xpOrbs {
+		if time.Since(orb.SpawnTime) > xpOrbLifetime {
+			delete(s.xpOrbs, eid)
+			expired = append(expired, eid)
+			continue
+		}
+
+		orb.VY -= gravity
+
+		// Fly towards the closest player
+		best := xpOrbAttractRange
+		var tx, ty, tz float64
+		found := false
+		for _, p := range s.players {
+			p.mu.Lock()
+			if !p.IsDead && p.GameMode != GameModeSpectator {
+				dx, dy, dz := p.X-orb.X, p.Y+0.81-orb.Y, p.Z-orb.Z
+				if d := math.Sqrt(dx*dx + dy*dy + dz*dz); d < best {
+					best, tx, ty, tz, found = d, dx, dy, dz, true
+				}
+			}
+			p.mu.Unlock()
+		}
+		if found && best > 0 {
+			pull := 1 - best/xpOrbAttractRange
+			pull *= pull
+			orb.VX += tx / best * pull * 0.1
+			orb.VY += ty / best * pull * 0.1
+			orb.VZ += tz / best * pull * 0.1
+		}
+
+		if !s.checkEntityCollision(orb.X+orb.VX, orb.Y, orb.Z, size, size) {
+			orb.X += orb.VX
+		} else {
+			orb.VX = 0
+		}
+		onGround := false
+		if !s.checkEntityCollision(orb.X, orb.Y+orb.VY, orb.Z, size, size) {
+			orb.Y += orb.VY
+		} else {
+			onGround = orb.VY < 0
+			orb.VY = 0
+		}
+		if !s.checkEntityCollision(orb.X, orb.Y, orb.Z+orb.VZ, size, size) {
+			orb.Z += orb.VZ
+		} else {
+			orb.VZ = 0
+		}
+
+		f := drag
+		if onGround {
+			f = 0.58
+		}
+		orb.VX *= f
+		orb.VY *= drag
+		orb.VZ *= f
+
+		moved = append(moved, orb)
+	}
+	return moved, expired
+}
+
+// experiencePickupLoop collects experience orbs that reach the player.
+func (s *Server) experiencePickupLoop(player *Player, stop chan struct{}) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.collectExperienceOrbs(player)
+		}
+	}
+}
+
+// collectExperienceOrbs gives the player every experience orb within pickup range.
+func (s *Server) collectExperienceOrbs(player *Player) {
+	player.mu.Lock()
+	px, py, pz := player.X, player.Y+0.81, player.Z
+	canCollect := !player.IsDead && player.GameMode != GameModeSpectator
+	player.mu.Unlock()
+	if !canCollect {
+		return
+	}
+
+	var collected []*ExperienceOrb
+	s.mu.Lock()
+	for eid, orb := range s.xpOrbs {
+		dx, dy, dz := orb.X-px, orb.Y-py, orb.Z-pz
+		if dx*dx+dy*dy+dz*dz < xpOrbPickupRange*xpOrbPickupRange {
+			delete(s.xpOrbs, eid)
+			collected = append(collected, orb)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(collected) == 0 {
+		return
+	}
+
+	total := int32(0)
+	for _, orb := range collected {
+		s.broadcastCollectItem(orb.EntityID, player.EntityID)
+		s.broadcastDestroyEntity(orb.EntityID)
+		total += int32(orb.Value)
+	}
+
+	player.mu.Lock()
+	addExperience(player, total)
+	level := player.XPLevel
+	player.mu.Unlock()
+	s.sendExperience(player)
+	log.Printf("Player %s collected %d experience (level %d)", player.Username, total, level)
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+func TestAddExperienceLevelsUp(t *testing.T) {
+	p := &Player{}
+
+	addExperience(p, 7) // Exactly one level from 0
+	if p.XPLevel != 1 || p.XPProgress != 0 {
+		t.Errorf("after 7 XP: level %d progress %v, want level 1 progress 0", p.XPLevel, p.XPProgress)
+	}
+
+	addExperience(p, 14) // 9 to reach level 2, 5 of the 11 needed for level 3
+	if p.XPLevel != 2 {
+		t.Errorf("after 21 XP: level %d, want 2", p.XPLevel)
+	}
+	if want := float32(5) / 11; p.XPProgress < want-0.001 || p.XPProgress > want+0.001 {
+		t.Errorf("after 21 XP: progress %v, want %v", p.XPProgress, want)
+	}
+	if p.XPTotal != 21 {
+		t.Errorf("XPTotal = %d, want 21", p.XPTotal)
+	}
+}
+
+func TestSpawnExperienceSplitsOrbs(t *testing.T) {
+	s := New(DefaultConfig())
+	s.SpawnExperience(8, 200, 8, 20) // 17 + 3
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.xpOrbs) != 2 {
+		t.Fatalf("orb count = %d, want 2", len(s.xpOrbs))
+	}
+	total := 0
+	for _, orb := range s.xpOrbs {
+		total += int(orb.Value)
+	}
+	if total != 20 {
+		t.Errorf("total orb value = %d, want 20", total)
+	}
+}
+
+func TestCollectExperienceOrbs(t *testing.T) {
+	s := New(DefaultConfig())
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	go func() {
+		for {
+			if _, err := protocol.ReadPacket(c2); err != nil {
+				return
+			}
+		}
+	}()
+
+	player := &Player{EntityID: 1, Username: "Collector", GameMode: GameModeSurvival, Conn: c1, X: 8, Y: 200, Z: 8, trackedEntities: make(map[int32]bool)}
+	s.players[player.EntityID] = player
+
+	s.SpawnExperience(8, 200.5, 8, 7)
+	s.collectExperienceOrbs(player)
+
+	player.mu.Lock()
+	level := player.XPLevel
+	player.mu.Unlock()
+	if level != 1 {
+		t.Errorf("level after collecting 7 XP = %d, want 1", level)
+	}
+	s.mu.RLock()
+	remaining := len(s.xpOrbs)
+	s.mu.RUnlock()
+	if remaining != 0 {
+		t.Errorf("%d orbs left after collection, want 0", remaining)
+	}
+}
+
+func TestOreDropsExperience(t *testing.T) {
+	s := New(DefaultConfig())
+	player := &Player{EntityID: 1, Username: "Miner", GameMode: GameModeSurvival, trackedEntities: make(map[int32]bool)}
+	s.world.SetBlock(8, 200, 8, 56<<4) // Diamond ore
+
+	s.handleBlockBreak(player, 8, 200, 8)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.xpOrbs) == 0 {
+		t.Error("mining diamond ore should drop experience orbs")
+	}
+}
+
+func TestDeathDropsExperience(t *testing.T) {
+	s := New(DefaultConfig())
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	go func() {
+		for {
+			if _, err := protocol.ReadPacket(c2); err != nil {
+				return
+			}
+		}
+	}()
+
+	player := &Player{EntityID: 1, Username: "Victim", Health: 1, GameMode: GameModeSurvival, Conn: c1, X: 8, Y: 200, Z: 8, XPLevel: 5, trackedEntities: make(map[int32]bool)}
+	s.players[player.EntityID] = player
+
+	s.applyDamage(player, 5, "died")
+
+	player.mu.Lock()
+	level := player.XPLevel
+	player.mu.Unlock()
+	if level != 0 {
+		t.Errorf("level after death = %d, want 0", level)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	total := 0
+	for _, orb := range s.xpOrbs {
+		total += int(orb.Value)
+	}
+	if total != 35 {
+		t.Errorf("dropped %d XP, want 35 (7 per level)", total)
+	}
+}
+
+func TestKillingMobDropsExperience(t *testing.T) {
+	s := New(DefaultConfig())
+	attacker := &Player{EntityID: 1, Username: "Hunter", GameMode: GameModeSurvival, X: 7, Y: 200, Z: 8, trackedEntities: make(map[int32]bool)}
+	s.players[attacker.EntityID] = attacker
+	s.SpawnMob(8, 200, 8, 54) // Zombie
+
+	s.mu.RLock()
+	var zombie *MobEntity
+	for _, m := range s.mobEntities {
+		zombie = m
+	}
+	s.mu.RUnlock()
+
+	for i := 0; i < 10; i++ {
+		s.handleAttack(attacker, zombie.EntityID)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, alive := s.mobEntities[zombie.EntityID]; alive {
+		t.Fatal("zombie should die after 10 hits")
+	}
+	if len(s.xpOrbs) == 0 {
+		t.Error("killing a zombie should drop experience orbs")
+	}
+}
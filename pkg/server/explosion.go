@@ -0,0 +1,322 @@
+package server
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// Explosion strengths (vanilla values).
+const (
+	TNTExplosionPower     = 4.0
+	CreeperExplosionPower = 3.0
+)
+
+// blastResistance returns the vanilla blast resistance of a block ID.
+// Explosion rays lose (resistance/5 + 0.3) * 0.3 strength per block they pass.
+func blastResistance(blockID uint16) float64 {
+	switch blockID {
+	case 0: // Air
+		return 0
+	case 7: // Bedrock
+		return 18000000
+	case 8, 9, 10, 11: // Water, Lava
+		return 500
+	case 49, 116, 145: // Obsidian, Enchanting table, Anvil
+		return 6000
+	case 130: // Ender chest
+		return 3000
+	case 121: // End stone
+		return 45
+	case 1, 4, 41, 42, 45, 48, 57, 67, 98, 108, 109, 133, 139, 173: // Stone, cobble, metal blocks, bricks, stone stairs, walls
+		return 30
+	case 172, 159: // Hardened clay
+		return 21
+	case 61, 62: // Furnace
+		return 17.5
+	case 14, 15, 16, 21, 56, 73, 74, 129: // Ores
+		return 15
+	case 5, 53, 85, 107, 125, 126, 134, 135, 136, 163, 164, 183, 184, 185, 186, 187, 188, 189, 190, 191, 192: // Wooden blocks
+		return 15
+	case 64, 193, 194, 195, 196, 197: // Wooden doors
+		return 15
+	case 71: // Iron door
+		return 25
+	case 54, 58: // Chest, Crafting table
+		return 12.5
+	case 17, 162: // Logs
+		return 10
+	case 47: // Bookshelf
+		return 7.5
+	case 24, 128, 35: // Sandstone, Sandstone stairs, Wool
+		return 4
+	case 2, 13, 82: // Grass, Gravel, Clay
+		return 3
+	case 3, 12, 79: // Dirt, Sand, Ice
+		return 2.5
+	case 87: // Netherrack
+		return 2
+	case 20, 89, 102: // Glass, Glowstone, Glass pane
+		return 1.5
+	case 18, 161, 80: // Leaves, Snow block
+		return 1
+	case 78: // Snow layer
+		return 0.5
+	case 46: // TNT
+		return 0
+	}
+	if world.IsInstantBreak(blockID) {
+		return 0
+	}
+	return 10
+}
+
+// explosionTarget is a snapshot of an entity that may be hit by an explosion.
+type explosionTarget struct {
+	player   *Player    // Nil for non-player entities
+	mob      *MobEntity // Nil for non-mob entities
+	entityID int32
+	x, y, z  float64
+	width    float64
+	height   float64
+}
+
+// Explode creates an explosion of the given power centred on (x, y, z).
+// Blocks are destroyed by casting rays outwards that lose strength as they pass
+// through blocks with blast resistance; nearby entities are damaged and knocked back.
+// If breakBlocks is false (e.g. mobGriefing is off) only entities are affected.
+// deathMessage is used for players killed by the blast. Must be called without s.mu held.
+func (s *Server) Explode(x, y, z float64, power float64, breakBlocks bool, deathMessage string) {
+	// Cast 1352 rays from the centre towards the surface of a 16x16x16 cube
+	affected := make(map[world.BlockPos]struct{})
+	if breakBlocks {
+		for i := 0; i < 16; i++ {
+			for j := 0; j < 16; j++ {
+				for k := 0; k < 16; k++ {
+					if i != 0 && i != 15 && j != 0 && j != 15 && k != 0 && k != 15 {
+						continue
+					}
+					dx := float64(i)/15*2 - 1
+					dy := float64(j)/15*2 - 1
+					dz := float64(k)/15*2 - 1
+					length := math.Sqrt(dx*dx + dy*dy + dz*dz)
+					dx /= length
+					dy /= length
+					dz /= length
+
+					intensity := power * (0.7 + rand.Float64()*0.6)
+					px, py, pz := x, y, z
+					for intensity > 0 {
+						bx := int32(math.Floor(px))
+						by := int32(math.Floor(py))
+						bz := int32(math.Floor(pz))
+						if by < 0 || by > 255 {
+							break
+						}
+						blockID := s.world.GetBlock(bx, by, bz) >> 4
+						if blockID != 0 {
+							intensity -= (blastResistance(blockID)/5 + 0.3) * 0.3
+							if intensity > 0 {
+								affected[world.BlockPos{X: bx, Y: by, Z: bz}] = struct{}{}
+							}
+						}
+						px += dx * 0.3
+						py += dy * 0.3
+						pz += dz * 0.3
+						intensity -= 0.225
+					}
+				}
+			}
+		}
+	}
+
+	// Snapshot entities within the blast radius
+	radius := power * 2
+	var targets []explosionTarget
+	s.mu.RLock()
+	for _, p := range s.players {
+		p.mu.Lock()
+		if !p.IsDead && p.GameMode != GameModeCreative && p.GameMode != GameModeSpectator {
+			targets = append(targets, explosionTarget{player: p, entityID: p.EntityID, x: p.X, y: p.Y, z: p.Z, width: 0.6, height: 1.8})
+		}
+		p.mu.Unlock()
+	}
+	for _, m := range s.mobEntities {
+		targets = append(targets, explosionTarget{mob: m, entityID: m.EntityID, x: m.X, y: m.Y, z: m.Z, width: 0.6, height: 1.8})
+	}
+	for _, item := range s.entities {
+		targets = append(targets, explosionTarget{entityID: item.EntityID, x: item.X, y: item.Y, z: item.Z, width: 0.25, height: 0.25})
+	}
+	for _, tnt := range s.primedTNT {
+		targets = append(targets, explosionTarget{entityID: tnt.EntityID, x: tnt.X, y: tnt.Y, z: tnt.Z, width: 0.98, height: 0.98})
+	}
+	s.mu.RUnlock()
+
+	type knockback struct {
+		target     explosionTarget
+		vx, vy, vz float64
+	}
+	var hits []knockback
+	playerMotion := make(map[int32][3]float64)
+	for _, t := range targets {
+		eyeY := t.y
+		if t.player != nil {
+			eyeY += 1.62
+		}
+		dx, dy, dz := t.x-x, eyeY-y, t.z-z
+		dist := math.Sqrt(dx*dx + dy*dy + dz*dz)
+		scaled := math.Sqrt((t.x-x)*(t.x-x)+(t.y-y)*(t.y-y)+(t.z-z)*(t.z-z)) / radius
+		if scaled > 1 || dist == 0 {
+			continue
+		}
+		dx /= dist
+		dy /= dist
+		dz /= dist
+
+		half := t.width / 2
+		exposure := s.blastExposure(x, y, z, t.x-half, t.y, t.z-half, t.x+half, t.y+t.height, t.z+half)
+		impact := (1 - scaled) * exposure
+		hits = append(hits, knockback{t, dx * impact, dy * impact, dz * impact})
+
+		damage := float32((impact*impact+impact)/2*8*radius + 1)
+		if t.player != nil {
+			s.applyDamage(t.player, damage, deathMessage)
+			playerMotion[t.entityID] = [3]float64{dx * impact, dy * impact, dz * impact}
+		} else if t.mob != nil {
+			s.damageMob(t.mob, damage, false)
+		}
+	}
+
+	// Push non-player entities away from the blast
+	s.mu.Lock()
+	for _, h := range hits {
+		if m, ok := s.mobEntities[h.target.entityID]; ok {
+			m.VX += h.vx
+			m.VY += h.vy
+			m.VZ += h.vz
+		} else if item, ok := s.entities[h.target.entityID]; ok {
+			item.VX += h.vx
+			item.VY += h.vy
+			item.VZ += h.vz
+		} else if tnt, ok := s.primedTNT[h.target.entityID]; ok {
+			tnt.VX += h.vx
+			tnt.VY += h.vy
+			tnt.VZ += h.vz
+		}
+	}
+	s.mu.Unlock()
+
+	s.broadcastExplosion(x, y, z, power, affected, playerMotion)
+
+	// Destroy the affected blocks
+	for pos := range affected {
+		state := s.world.GetBlock(pos.X, pos.Y, pos.Z)
+		blockID := state >> 4
+		if blockID == 0 {
+			continue
+		}
+		if blockID == 46 {
+			// TNT caught in a blast goes off with a short random fuse
+			s.PrimeTNT(pos.X, pos.Y, pos.Z, tntFuseTicks/8+rand.Intn(tntFuseTicks/4))
+			continue
+		}
+
+		s.world.SetBlock(pos.X, pos.Y, pos.Z, 0)
+		s.broadcastBlockChange(pos.X, pos.Y, pos.Z, 0)
+		if isChestBlock(blockID) {
+			s.dropChestContents(pos.X, pos.Y, pos.Z)
+		}
+		if rand.Float64() < 1/power {
+			itemID, damage, count := world.BlockToItemID(state)
+			if itemID > 0 {
+				s.SpawnItem(float64(pos.X)+0.5, float64(pos.Y)+0.5, float64(pos.Z)+0.5, 0, 0.2, 0, itemID, damage, count)
+			}
+		}
+	}
+	for pos := range affected {
+		s.updateNeighbors(pos.X, pos.Y, pos.Z)
+	}
+}
+
+// blastExposure returns the fraction (0..1) of points across the given bounding
+// box that have an unobstructed line of sight to the explosion centre.
+func (s *Server) blastExposure(x, y, z, minX, minY, minZ, maxX, maxY, maxZ float64) float64 {
+	stepX := 1 / ((maxX-minX)*2 + 1)
+	stepY := 1 / ((maxY-minY)*2 + 1)
+	stepZ := 1 / ((maxZ-minZ)*2 + 1)
+
+	visible, total := 0, 0
+	for fx := 0.0; fx <= 1; fx += stepX {
+		for fy := 0.0; fy <= 1; fy += stepY {
+			for fz := 0.0; fz <= 1; fz += stepZ {
+				px := minX + (maxX-minX)*fx
+				py := minY + (maxY-minY)*fy
+				pz := minZ + (maxZ-minZ)*fz
+				if !s.rayBlocked(px, py, pz, x, y, z) {
+					visible++
+				}
+				total++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(visible) / float64(total)
+}
+
+// rayBlocked returns true if a solid block lies on the line between the two points.
+func (s *Server) rayBlocked(x1, y1, z1, x2, y2, z2 float64) bool {
+	dx, dy, dz := x2-x1, y2-y1, z2-z1
+	steps := int(math.Ceil(math.Sqrt(dx*dx+dy*dy+dz*dz) / 0.2))
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		bx := int32(math.Floor(x1 + dx*t))
+		by := int32(math.Floor(y1 + dy*t))
+		bz := int32(math.Floor(z1 + dz*t))
+		if isSolidBlock(s.world.GetBlock(bx, by, bz) >> 4) {
+			return true
+		}
+	}
+	return false
+}
+
+// broadcastExplosion sends an Explosion packet (0x27) to players within 64 blocks.
+// Each player receives their own knockback from playerMotion.
+func (s *Server) broadcastExplosion(x, y, z float64, power float64, affected map[world.BlockPos]struct{}, playerMotion map[int32][3]float64) {
+	cx, cy, cz := int32(x), int32(y), int32(z)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.players {
+		p.mu.Lock()
+		dx, dy, dz := p.X-x, p.Y-y, p.Z-z
+		if p.Conn != nil && dx*dx+dy*dy+dz*dz < 64*64 {
+			motion := playerMotion[p.EntityID]
+			pkt := protocol.MarshalPacket(0x27, func(w *bytes.Buffer) {
+				protocol.WriteFloat32(w, float32(x))
+				protocol.WriteFloat32(w, float32(y))
+				protocol.WriteFloat32(w, float32(z))
+				protocol.WriteFloat32(w, float32(power))
+				protocol.WriteInt32(w, int32(len(affected)))
+				for pos := range affected {
+					// Records are offsets relative to the explosion centre
+					protocol.WriteByte(w, byte(int8(pos.X-cx)))
+					protocol.WriteByte(w, byte(int8(pos.Y-cy)))
+					protocol.WriteByte(w, byte(int8(pos.Z-cz)))
+				}
+				protocol.WriteFloat32(w, float32(motion[0]))
+				protocol.WriteFloat32(w, float32(motion[1]))
+				protocol.WriteFloat32(w, float32(motion[2]))
+			})
+			protocol.WritePacket(p.Conn, pkt)
+		}
+		p.mu.Unlock()
+	}
+}
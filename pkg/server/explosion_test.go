@@ -0,0 +1,146 @@
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+// fillCube fills a cube of side 2r+1 centred on (cx, cy, cz) with the given block state.
+func fillCube(s *Server, cx, cy, cz, r int32, state uint16) {
+	for x := cx - r; x <= cx+r; x++ {
+		for y := cy - r; y <= cy+r; y++ {
+			for z := cz - r; z <= cz+r; z++ {
+				s.world.SetBlock(x, y, z, state)
+			}
+		}
+	}
+}
+
+func TestExplosionDestroysBlocks(t *testing.T) {
+	s := New(DefaultConfig())
+	fillCube(s, 8, 200, 8, 2, 3<<4) // Dirt
+
+	s.Explode(8.5, 200.5, 8.5, TNTExplosionPower, true, "blew up")
+
+	if got := s.world.GetBlock(8, 200, 8); got != 0 {
+		t.Errorf("centre block should be destroyed, got block %d", got>>4)
+	}
+	if got := s.world.GetBlock(9, 200, 8); got != 0 {
+		t.Errorf("adjacent dirt should be destroyed, got block %d", got>>4)
+	}
+}
+
+func TestExplosionBlastResistance(t *testing.T) {
+	s := New(DefaultConfig())
+	fillCube(s, 8, 200, 8, 2, 49<<4) // Obsidian
+	s.world.SetBlock(8, 200, 8, 0)
+
+	s.Explode(8.5, 200.5, 8.5, TNTExplosionPower, true, "blew up")
+
+	for _, pos := range [][3]int32{{9, 200, 8}, {7, 200, 8}, {8, 201, 8}, {8, 199, 8}, {8, 200, 9}, {8, 200, 7}} {
+		if got := s.world.GetBlock(pos[0], pos[1], pos[2]) >> 4; got != 49 {
+			t.Errorf("obsidian at %v should survive, got block %d", pos, got)
+		}
+	}
+}
+
+func TestExplosionNoBlockDamage(t *testing.T) {
+	s := New(DefaultConfig())
+	s.gamerules["mobGriefing"] = "false"
+	fillCube(s, 8, 200, 8, 1, 3<<4) // Dirt
+	s.world.SetBlock(8, 200, 8, 0)
+
+	s.SpawnMob(8.5, 200, 8.5, MobTypeCreeper)
+	s.mu.RLock()
+	var creeper *MobEntity
+	for _, m := range s.mobEntities {
+		creeper = m
+	}
+	s.mu.RUnlock()
+	delete(s.mobEntities, creeper.EntityID)
+	s.detonateCreeper(creeper)
+
+	if got := s.world.GetBlock(9, 200, 8) >> 4; got != 3 {
+		t.Errorf("creeper should not destroy blocks with mobGriefing off, got block %d", got)
+	}
+}
+
+func TestTNTChainReaction(t *testing.T) {
+	s := New(DefaultConfig())
+	s.world.SetBlock(8, 199, 8, 1<<4)  // Stone floor
+	s.world.SetBlock(8, 200, 8, 46<<4) // TNT
+	s.world.SetBlock(10, 200, 8, 46<<4)
+
+	s.PrimeTNT(8, 200, 8, 1)
+	if got := s.world.GetBlock(8, 200, 8); got != 0 {
+		t.Fatalf("primed TNT block should be removed, got block %d", got>>4)
+	}
+
+	s.tickEntityPhysics()
+
+	if got := s.world.GetBlock(10, 200, 8); got != 0 {
+		t.Errorf("neighbouring TNT should be ignited by the explosion, got block %d", got>>4)
+	}
+	s.mu.RLock()
+	count := len(s.primedTNT)
+	s.mu.RUnlock()
+	if count != 1 {
+		t.Errorf("primed TNT count = %d, want 1", count)
+	}
+}
+
+func TestExplosionDamagesPlayer(t *testing.T) {
+	s := New(DefaultConfig())
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	go func() {
+		for {
+			if _, err := protocol.ReadPacket(c2); err != nil {
+				return
+			}
+		}
+	}()
+
+	player := &Player{EntityID: 1, Username: "Victim", Health: 20.0, GameMode: GameModeSurvival, Conn: c1, X: 10.5, Y: 200, Z: 8.5}
+	s.players[player.EntityID] = player
+
+	s.Explode(8.5, 200.5, 8.5, TNTExplosionPower, false, "blew up")
+
+	player.mu.Lock()
+	health := player.Health
+	player.mu.Unlock()
+	if health >= 20 {
+		t.Errorf("player near an explosion should take damage, health = %v", health)
+	}
+}
+
+func TestExplosionDamagesMobs(t *testing.T) {
+	s := New(DefaultConfig())
+	s.SpawnMob(10.5, 200, 8.5, 54) // Zombie next to the blast
+	s.SpawnMob(14.5, 200, 8.5, 54) // Zombie at the edge of it
+	var near, far *MobEntity
+	for _, m := range s.mobEntities {
+		if m.X < 12 {
+			near = m
+		} else {
+			far = m
+		}
+	}
+
+	s.Explode(8.5, 200.5, 8.5, TNTExplosionPower, false, "blew up")
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.mobEntities[near.EntityID]; ok {
+		t.Errorf("zombie next to the explosion survived with %v health", near.Health)
+	}
+	if _, ok := s.mobEntities[far.EntityID]; !ok {
+		t.Fatal("zombie at the edge of the explosion died")
+	}
+	if far.Health >= mobMaxHealth(54) {
+		t.Errorf("zombie at the edge of the explosion took no damage, health = %v", far.Health)
+	}
+}
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"math"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// FallingBlockEntity represents a gravity-affected block (sand, gravel, anvil)
+// that is currently falling through the world (Spawn Object type 70).
+type FallingBlockEntity struct {
+	EntityID   int32
+	BlockState uint16
+	X, Y, Z    float64
+	VX, VY, VZ float64
+	Ticks      int // Number of physics ticks the block has been falling
+}
+
+// fallingBlockMaxTicks is how long a falling block may exist before it is
+// forcibly dropped as an item (vanilla uses 600 ticks / 30 seconds).
+const fallingBlockMaxTicks = 600
+
+// hasGravity returns true if the block ID falls when left unsupported.
+func hasGravity(blockID uint16) bool {
+	switch blockID {
+	case 12, 13, 145: // Sand, Gravel, Anvil
+		return true
+	}
+	return false
+}
+
+// canFallInto returns true if a falling block can replace the given block,
+// both when deciding whether it is unsupported and when landing.
+func canFallInto(blockID uint16) bool {
+	switch blockID {
+	case 0: // Air
+		return true
+	case 8, 9, 10, 11: // Water, Lava
+		return true
+	case 51: // Fire
+		return true
+	}
+	return false
+}
+
+// updateNeighbors runs neighbour-change checks after the block at (x, y, z)
+// changed, so that e.g. sand resting on a broken block starts to fall.
+func (s *Server) updateNeighbors(x, y, z int32) {
+	s.checkGravity(x, y, z)
+	s.checkGravity(x, y+1, z)
+}
+
+// checkGravity turns the block at (x, y, z) into a falling block entity if it
+// is affected by gravity and the block beneath it cannot support it.
+func (s *Server) checkGravity(x, y, z int32) {
+	if y <= 0 || y > 255 {
+		return
+	}
+	state := s.world.GetBlock(x, y, z)
+	if !hasGravity(state >> 4) {
+		return
+	}
+	if !canFallInto(s.world.GetBlock(x, y-1, z) >> 4) {
+		return
+	}
+
+	s.world.SetBlock(x, y, z, 0)
+	s.broadcastBlockChange(x, y, z, 0)
+	s.SpawnFallingBlock(float64(x)+0.5, float64(y), float64(z)+0.5, state)
+
+	// The block above may have been resting on this one
+	s.updateNeighbors(x, y, z)
+}
+
+// SpawnFallingBlock creates a falling block entity at the given position and broadcasts it.
+func (s *Server) SpawnFallingBlock(x, y, z float64, blockState uint16) {
+	s.mu.Lock()
+	eid := s.nextEID
+	s.nextEID++
+
+	fb := &FallingBlockEntity{
+		EntityID:   eid,
+		BlockState: blockState,
+		X:          x,
+		Y:          y,
+		Z:          z,
+	}
+	s.fallingBlocks[eid] = fb
+	s.mu.Unlock()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.players {
+		if s.shouldTrack(p, fb.X, fb.Y, fb.Z) {
+			s.sendFallingBlockToPlayer(p, fb)
+			p.mu.Lock()
+			s.trackEntity(p, fb.EntityID)
+			p.mu.Unlock()
+		}
+	}
+}
+
+func (s *Server) sendFallingBlockToPlayer(player *Player, fb *FallingBlockEntity) {
+	// Object data for falling blocks is blockID | (metadata << 12)
+	data := int32(fb.BlockState>>4) | int32(fb.BlockState&0x0F)<<12
+
+	// Spawn Object - 0x0E (Falling Block)
+	spawnObj := protocol.MarshalPacket(0x0E, func(w *bytes.Buffer) {
+		protocol.WriteVarInt(w, fb.EntityID)
+		protocol.WriteByte(w, 70) // Type: Falling Block
+		protocol.WriteInt32(w, int32(fb.X*32))
+		protocol.WriteInt32(w, int32(fb.Y*32))
+		protocol.WriteInt32(w, int32(fb.Z*32))
+		protocol.WriteByte(w, 0) // Pitch
+		protocol.WriteByte(w, 0) // Yaw
+		protocol.WriteInt32(w, data)
+		protocol.WriteInt16(w, int16(fb.VX*8000))
+		protocol.WriteInt16(w, int16(fb.VY*8000))
+		protocol.WriteInt16(w, int16(fb.VZ*8000))
+	})
+
+	player.mu.Lock()
+	if player.Conn != nil {
+		protocol.WritePacket(player.Conn, spawnObj)
+	}
+	player.mu.Unlock()
+}
+
+func (s *Server) spawnFallingBlocksForPlayer(player *Player) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, fb := range s.fallingBlocks {
+		if s.shouldTrack(player, fb.X, fb.Y, fb.Z) {
+			s.sendFallingBlockToPlayer(player, fb)
+			player.mu.Lock()
+			s.trackEntity(player, fb.EntityID)
+			player.mu.Unlock()
+		}
+	}
+}
+
+// fallingBlockLanding records a falling block that finished falling this tick.
+type fallingBlockLanding struct {
+	entityID   int32
+	blockState uint16
+	x, y, z    int32
+	expired    bool // Fell for too long; drop as an item instead of placing
+	outOfWorld bool // Fell below the world; remove without a drop
+}
+
+// tickFallingBlocks advances all falling blocks by one tick.
+// Must be called with s.mu held. Returns the blocks that moved and those that landed.
+func (s *Server) tickFallingBlocks() (moved []*FallingBlockEntity, landed []fallingBlockLanding) {
+	const gravity = 0.04
+	const drag = 0.98
+	const size = 0.98
+
+	for eid, fb := range s.fallingBlocks {
+		fb.Ticks++
+		fb.VY -= gravity
+
+		if fb.Y+fb.VY < 0 {
+			delete(s.fallingBlocks, eid)
+			landed = append(landed, fallingBlockLanding{entityID: eid, outOfWorld: true})
+			continue
+		}
+
+		if !s.checkEntityCollision(fb.X, fb.Y+fb.VY, fb.Z, size, size) {
+			fb.Y += fb.VY
+		} else if fb.VY < 0 {
+			// Hit the ground somewhere within this tick's fall, which can be
+			// more than a block: settle on top of the first solid block below
+			x, y, z := int32(math.Floor(fb.X)), int32(math.Floor(fb.Y)), int32(math.Floor(fb.Z))
+			for y > 0 && !isSolidBlock(s.world.GetBlock(x, y-1, z)>>4) {
+				y--
+			}
+			delete(s.fallingBlocks, eid)
+			landed = append(landed, fallingBlockLanding{
+				entityID:   eid,
+				blockState: fb.BlockState,
+				x:          x,
+				y:          y,
+				z:          z,
+			})
+			continue
+		} else {
+			fb.VY = 0
+		}
+		fb.VY *= drag
+
+		if fb.Ticks > fallingBlockMaxTicks {
+			delete(s.fallingBlocks, eid)
+			landed = append(landed, fallingBlockLanding{
+				entityID:   eid,
+				blockState: fb.BlockState,
+				x:          int32(math.Floor(fb.X)),
+				y:          int32(math.Floor(fb.Y)),
+				z:          int32(math.Floor(fb.Z)),
+				expired:    true,
+			})
+			continue
+		}
+
+		moved = append(moved, fb)
+	}
+	return moved, landed
+}
+
+// landFallingBlock places a landed falling block back into the world, or drops
+// it as an item if the landing cell is occupied by a non-solid block (e.g. a torch).
+// Must be called without s.mu held.
+func (s *Server) landFallingBlock(l fallingBlockLanding) {
+	s.broadcastDestroyEntity(l.entityID)
+	if l.outOfWorld {
+		return
+	}
+
+	current := s.world.GetBlock(l.x, l.y, l.z) >> 4
+	if !l.expired && canFallInto(current) {
+		s.world.SetBlock(l.x, l.y, l.z, l.blockState)
+		s.broadcastBlockChange(l.x, l.y, l.z, l.blockState)
+		if l.blockState>>4 == 145 {
+			s.broadcastEffect(1022, l.x, l.y, l.z, 0) // Anvil land sound
+		}
+		// The block beneath may have vanished while we were falling
+		s.checkGravity(l.x, l.y, l.z)
+		return
+	}
+
+	itemID, damage, count := world.BlockToItemID(l.blockState)
+	if itemID > 0 {
+		s.SpawnItem(float64(l.x)+0.5, float64(l.y)+0.5, float64(l.z)+0.5, 0, 0.2, 0, itemID, damage, count)
+	}
+	log.Printf("Falling block %d dropped as item at (%d, %d, %d)", l.blockState>>4, l.x, l.y, l.z)
+}
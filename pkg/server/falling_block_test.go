@@ -0,0 +1,117 @@
+package server
+
+import (
+	"testing"
+)
+
+func TestFallingBlockLands(t *testing.T) {
+	s := New(DefaultConfig())
+
+	// Floating sand above a stone floor, high in the sky
+	s.world.SetBlock(8, 195, 8, 1<<4) // Stone
+	s.world.SetBlock(8, 200, 8, 12<<4)
+
+	s.checkGravity(8, 200, 8)
+
+	if got := s.world.GetBlock(8, 200, 8); got != 0 {
+		t.Fatalf("sand should be removed when it starts falling, got block %d", got>>4)
+	}
+	s.mu.RLock()
+	count := len(s.fallingBlocks)
+	s.mu.RUnlock()
+	if count != 1 {
+		t.Fatalf("falling block count = %d, want 1", count)
+	}
+
+	for i := 0; i < 200; i++ {
+		s.tickEntityPhysics()
+	}
+
+	s.mu.RLock()
+	count = len(s.fallingBlocks)
+	s.mu.RUnlock()
+	if count != 0 {
+		t.Errorf("falling block should have landed, %d still falling", count)
+	}
+	if got := s.world.GetBlock(8, 196, 8) >> 4; got != 12 {
+		t.Errorf("block on top of stone = %d, want 12 (Sand)", got)
+	}
+}
+
+func TestFallingBlockLandsAfterLongFall(t *testing.T) {
+	s := New(DefaultConfig())
+
+	// Falling from this high, the block moves more than a block per tick by
+	// the time it reaches the ground, wherever within a tick that happens
+	ground := make(map[int32]int32)
+	for x := int32(0); x < 10; x++ {
+		ground[x] = 140 + x
+		s.world.SetBlock(x, ground[x], 8, 1<<4) // Stone
+		s.world.SetBlock(x, 250, 8, 12<<4)      // Sand
+		s.checkGravity(x, 250, 8)
+	}
+
+	// Check where each block first lands, before it is placed
+	for i := 0; i < 400 && len(ground) > 0; i++ {
+		s.mu.Lock()
+		_, landed := s.tickFallingBlocks()
+		s.mu.Unlock()
+		for _, l := range landed {
+			if want := ground[l.x] + 1; l.y != want {
+				t.Errorf("block at x = %d landed at y = %d, want %d on top of the stone", l.x, l.y, want)
+			}
+			delete(ground, l.x)
+		}
+	}
+	if len(ground) > 0 {
+		t.Errorf("%d falling blocks never landed", len(ground))
+	}
+}
+
+func TestFallingBlockColumn(t *testing.T) {
+	s := New(DefaultConfig())
+
+	s.world.SetBlock(8, 200, 8, 1<<4)  // Stone support
+	s.world.SetBlock(8, 201, 8, 13<<4) // Gravel
+	s.world.SetBlock(8, 202, 8, 13<<4) // Gravel
+
+	// Breaking the support should make the whole column fall
+	s.world.SetBlock(8, 200, 8, 0)
+	s.updateNeighbors(8, 200, 8)
+
+	s.mu.RLock()
+	count := len(s.fallingBlocks)
+	s.mu.RUnlock()
+	if count != 2 {
+		t.Errorf("falling block count = %d, want 2", count)
+	}
+}
+
+func TestFallingBlockDropsOnTorch(t *testing.T) {
+	s := New(DefaultConfig())
+
+	s.world.SetBlock(8, 195, 8, 1<<4)    // Stone
+	s.world.SetBlock(8, 196, 8, 50<<4|5) // Floor torch
+	s.world.SetBlock(8, 200, 8, 12<<4)   // Sand
+
+	s.checkGravity(8, 200, 8)
+	for i := 0; i < 200; i++ {
+		s.tickEntityPhysics()
+	}
+
+	if got := s.world.GetBlock(8, 196, 8) >> 4; got != 50 {
+		t.Errorf("torch should remain in place, got block %d", got)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	found := false
+	for _, item := range s.entities {
+		if item.ItemID == 12 {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("sand landing on a torch should drop as an item")
+	}
+}
@@ -0,0 +1,331 @@
+package server
+
+import (
+	"math"
+	"math/rand"
+)
+
+// fireInfo holds the vanilla fire parameters for a flammable block.
+type fireInfo struct {
+	encouragement int // How readily fire spreads into air next to this block
+	flammability  int // How readily this block burns away when next to fire
+}
+
+// flammableBlocks maps block IDs to their fire spread parameters (vanilla values).
+var flammableBlocks = map[uint16]fireInfo{
+	5:   {5, 20},   // Planks
+	17:  {5, 5},    // Log
+	162: {5, 5},    // Log (Acacia, Dark Oak)
+	18:  {30, 60},  // Leaves
+	161: {30, 60},  // Leaves (Acacia, Dark Oak)
+	35:  {30, 60},  // Wool
+	171: {60, 20},  // Carpet
+	47:  {30, 20},  // Bookshelf
+	46:  {15, 100}, // TNT
+	31:  {60, 100}, // Tall grass
+	32:  {60, 100}, // Dead bush
+	37:  {60, 100}, // Dandelion
+	38:  {60, 100}, // Flowers
+	175: {60, 100}, // Double plants
+	106: {15, 100}, // Vines
+	170: {60, 20},  // Hay bale
+	173: {5, 5},    // Block of coal
+	125: {5, 20},   // Wooden double slab
+	126: {5, 20},   // Wooden slab
+	53:  {5, 20},   // Oak stairs
+	134: {5, 20},   // Spruce stairs
+	135: {5, 20},   // Birch stairs
+	136: {5, 20},   // Jungle stairs
+	163: {5, 20},   // Acacia stairs
+	164: {5, 20},   // Dark oak stairs
+	85:  {5, 20},   // Oak fence
+	188: {5, 20},   // Spruce fence
+	189: {5, 20},   // Birch fence
+	190: {5, 20},   // Jungle fence
+	191: {5, 20},   // Dark oak fence
+	192: {5, 20},   // Acacia fence
+	107: {5, 20},   // Oak fence gate
+	183: {5, 20},   // Spruce fence gate
+	184: {5, 20},   // Birch fence gate
+	185: {5, 20},   // Jungle fence gate
+	186: {5, 20},   // Dark oak fence gate
+	187: {5, 20},   // Acacia fence gate
+}
+
+// Burn durations in environment ticks (500ms each) after touching fire or lava.
+const (
+	fireBurnTicks = 16 // 8 seconds
+	lavaBurnTicks = 30 // 15 seconds
+)
+
+// fireNeighbors lists the six face-adjacent offsets checked by fire logic.
+var fireNeighbors = [6][3]int32{{1, 0, 0}, {-1, 0, 0}, {0, -1, 0}, {0, 1, 0}, {0, 0, -1}, {0, 0, 1}}
+
+// isFlammable returns true if fire can burn the given block ID.
+func isFlammable(blockID uint16) bool {
+	_, ok := flammableBlocks[blockID]
+	return ok
+}
+
+// fireTickDelay returns the number of game ticks until a fire block updates again.
+func fireTickDelay() int {
+	return 30 + rand.Intn(10)
+}
+
+// setFire places a fire block with the given age and schedules its first update.
+func (s *Server) setFire(x, y, z int32, age int) {
+	if age > 15 {
+		age = 15
+	}
+	state := uint16(51)<<4 | uint16(age)
+	s.world.SetBlock(x, y, z, state)
+	s.broadcastBlockChange(x, y, z, state)
+	s.scheduleBlockTick(x, y, z, fireTickDelay())
+}
+
+// removeFire turns a fire block back into air.
+func (s *Server) removeFire(x, y, z int32) {
+	s.world.SetBlock(x, y, z, 0)
+	s.broadcastBlockChange(x, y, z, 0)
+}
+
+// hasFlammableNeighbor returns true if any block adjacent to (x, y, z) can burn.
+func (s *Server) hasFlammableNeighbor(x, y, z int32) bool {
+	for _, d := range fireNeighbors {
+		if isFlammable(s.world.GetBlock(x+d[0], y+d[1], z+d[2]) >> 4) {
+			return true
+		}
+	}
+	return false
+}
+
+// canFireStay returns true if fire at (x, y, z) rests on a solid block or touches something flammable.
+func (s *Server) canFireStay(x, y, z int32) bool {
+	return isSolidBlock(s.world.GetBlock(x, y-1, z)>>4) || s.hasFlammableNeighbor(x, y, z)
+}
+
+// igniteBlock places new fire at (x, y, z) if the position is empty and fire can exist there.
+// Returns true if fire was placed.
+func (s *Server) igniteBlock(x, y, z int32) bool {
+	if y < 0 || y > 255 {
+		return false
+	}
+	if s.world.GetBlock(x, y, z)>>4 != 0 || !s.canFireStay(x, y, z) {
+		return false
+	}
+	s.setFire(x, y, z, 0)
+	return true
+}
+
+// extinguishFire puts out a fire on the given face of the block at (x, y, z),
+// as happens when a player punches a burning block. Returns true if a fire was removed.
+func (s *Server) extinguishFire(x, y, z int32, face byte) bool {
+	fx, fy, fz := faceOffset(x, y, z, face)
+	if s.world.GetBlock(fx, fy, fz)>>4 != 51 {
+		return false
+	}
+	s.removeFire(fx, fy, fz)
+	s.broadcastEffect(1004, fx, fy, fz, 0) // Fire extinguish sound
+	return true
+}
+
+// tickFire runs a fire update: the fire ages, burns out, destroys adjacent
+// flammable blocks and spreads into nearby air.
+func (s *Server) tickFire(x, y, z int32, state uint16) {
+	if !s.GameRuleBool("doFireTick") {
+		return
+	}
+	if !s.canFireStay(x, y, z) {
+		s.removeFire(x, y, z)
+		return
+	}
+
+	below := s.world.GetBlock(x, y-1, z) >> 4
+	infinite := below == 87 // Netherrack burns forever
+
+	age := int(state & 0x0F)
+	if age < 15 {
+		age += rand.Intn(3) / 2
+		newState := uint16(51)<<4 | uint16(age)
+		if newState != state {
+			s.world.SetBlock(x, y, z, newState)
+			s.broadcastBlockChange(x, y, z, newState)
+		}
+	}
+	s.scheduleBlockTick(x, y, z, fireTickDelay())
+
+	if !infinite {
+		if !s.hasFlammableNeighbor(x, y, z) {
+			if !isSolidBlock(below) || age > 3 {
+				s.removeFire(x, y, z)
+			}
+			return
+		}
+		if !isFlammable(below) && age == 15 && rand.Intn(4) == 0 {
+			s.removeFire(x, y, z)
+			return
+		}
+	}
+
+	// Burn the blocks directly around the fire
+	for _, d := range fireNeighbors {
+		chance := 300
+		if d[1] != 0 {
+			chance = 250
+		}
+		s.tryCatchFire(x+d[0], y+d[1], z+d[2], chance, age)
+	}
+
+	// Spread into air within a 3x6x3 area, less likely the higher up it is
+	for dx := int32(-1); dx <= 1; dx++ {
+		for dz := int32(-1); dz <= 1; dz++ {
+			for dy := int32(-1); dy <= 4; dy++ {
+				if dx == 0 && dy == 0 && dz == 0 {
+					continue
+				}
+				nx, ny, nz := x+dx, y+dy, z+dz
+				if ny < 0 || ny > 255 || s.world.GetBlock(nx, ny, nz)>>4 != 0 {
+					continue
+				}
+
+				encouragement := s.fireEncouragement(nx, ny, nz)
+				if encouragement <= 0 {
+					continue
+				}
+				chance := 100
+				if dy > 1 {
+					chance += int(dy-1) * 100
+				}
+				spread := (encouragement + 40) / (age + 30)
+				if spread > 0 && rand.Intn(chance) <= spread {
+					s.setFire(nx, ny, nz, age+rand.Intn(5)/4)
+				}
+			}
+		}
+	}
+}
+
+// fireEncouragement returns the highest encouragement of the blocks around (x, y, z).
+func (s *Server) fireEncouragement(x, y, z int32) int {
+	best := 0
+	for _, d := range fireNeighbors {
+		if info, ok := flammableBlocks[s.world.GetBlock(x+d[0], y+d[1], z+d[2])>>4]; ok && info.encouragement > best {
+			best = info.encouragement
+		}
+	}
+	return best
+}
+
+// tryCatchFire may burn away the block at (x, y, z), replacing it with fire or air.
+func (s *Server) tryCatchFire(x, y, z int32, chance, age int) {
+	blockID := s.world.GetBlock(x, y, z) >> 4
+	info, ok := flammableBlocks[blockID]
+	if !ok || rand.Intn(chance) >= info.flammability {
+		return
+	}
+
+	if blockID == 46 {
+		// Burning TNT is lit rather than destroyed
+		s.PrimeTNT(x, y, z, tntFuseTicks)
+	}
+	if rand.Intn(age+10) < 5 {
+		s.setFire(x, y, z, age+rand.Intn(5)/4)
+	} else {
+		s.removeFire(x, y, z)
+	}
+	// Blocks resting on the burnt one (e.g. sand) may now fall
+	s.updateNeighbors(x, y, z)
+}
+
+// tickLavaIgnition runs the lava random tick that sets fire to flammable blocks nearby.
+func (s *Server) tickLavaIgnition(x, y, z int32) {
+	attempts := rand.Intn(3)
+	if attempts > 0 {
+		// Wander upwards looking for air next to something flammable
+		px, py, pz := x, y, z
+		for i := 0; i < attempts; i++ {
+			px += int32(rand.Intn(3) - 1)
+			py++
+			pz += int32(rand.Intn(3) - 1)
+			if py > 255 {
+				return
+			}
+			id := s.world.GetBlock(px, py, pz) >> 4
+			if id == 0 {
+				if s.hasFlammableNeighbor(px, py, pz) {
+					s.setFire(px, py, pz, 0)
+					return
+				}
+			} else if isSolidBlock(id) {
+				return
+			}
+		}
+		return
+	}
+
+	// Set fire on top of flammable blocks at the same level
+	for i := 0; i < 3; i++ {
+		px := x + int32(rand.Intn(3)-1)
+		pz := z + int32(rand.Intn(3)-1)
+		if y+1 <= 255 && s.world.GetBlock(px, y+1, pz)>>4 == 0 && isFlammable(s.world.GetBlock(px, y, pz)>>4) {
+			s.setFire(px, y+1, pz, 0)
+		}
+	}
+}
+
+// tickPlayerFire applies fire and lava contact damage, and keeps players
+// burning for a while after they leave the flames. Water puts them out.
+// Called from environmentLoop every 500ms.
+func (s *Server) tickPlayerFire(player *Player) {
+	player.mu.Lock()
+	wasBurning := player.FireTicks > 0
+	if player.IsDead || player.GameMode == GameModeCreative || player.GameMode == GameModeSpectator {
+		player.FireTicks = 0
+		player.mu.Unlock()
+		if wasBurning {
+			s.broadcastEntityFlags(player)
+		}
+		return
+	}
+	x, y, z := player.X, player.Y, player.Z
+	player.mu.Unlock()
+
+	bx := int32(math.Floor(x))
+	bz := int32(math.Floor(z))
+	blockFeet := s.world.GetBlock(bx, int32(math.Floor(y)), bz) >> 4
+	blockHead := s.world.GetBlock(bx, int32(math.Floor(y+1)), bz) >> 4
+
+	inWater := blockFeet == 8 || blockFeet == 9 || blockHead == 8 || blockHead == 9
+	inLava := blockFeet == 10 || blockFeet == 11 || blockHead == 10 || blockHead == 11
+	inFire := blockFeet == 51 || blockHead == 51
+
+	var damage float32
+	var deathMessage string
+
+	player.mu.Lock()
+	switch {
+	case inLava:
+		damage, deathMessage = 4.0, "tried to swim in lava"
+		player.FireTicks = max(player.FireTicks, lavaBurnTicks)
+	case inWater:
+		player.FireTicks = 0
+	case inFire:
+		damage, deathMessage = 1.0, "went up in flames"
+		player.FireTicks = max(player.FireTicks, fireBurnTicks)
+	case player.FireTicks > 0:
+		// Burning players take 1 damage per second
+		player.FireTicks--
+		if player.FireTicks%2 == 0 {
+			damage, deathMessage = 1.0, "burned to death"
+		}
+	}
+	burning := player.FireTicks > 0
+	player.mu.Unlock()
+
+	if burning != wasBurning {
+		s.broadcastEntityFlags(player)
+	}
+	if damage > 0 {
+		s.applyDamage(player, damage, deathMessage)
+	}
+}
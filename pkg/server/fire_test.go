@@ -0,0 +1,111 @@
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+func TestIgniteBlock(t *testing.T) {
+	s := New(DefaultConfig())
+	s.world.SetBlock(8, 199, 8, 1<<4) // Stone
+
+	if !s.igniteBlock(8, 200, 8) {
+		t.Fatal("fire should be placed on top of stone")
+	}
+	if got := s.world.GetBlock(8, 200, 8) >> 4; got != 51 {
+		t.Fatalf("block = %d, want 51 (Fire)", got)
+	}
+	s.tickMu.Lock()
+	_, scheduled := s.scheduledTicks[world.BlockPos{X: 8, Y: 200, Z: 8}]
+	s.tickMu.Unlock()
+	if !scheduled {
+		t.Error("new fire should schedule a block tick")
+	}
+
+	// Fire cannot float in mid-air
+	if s.igniteBlock(8, 210, 8) {
+		t.Error("fire should not be placed without support")
+	}
+}
+
+func TestFireBurnsOutWithoutFuel(t *testing.T) {
+	s := New(DefaultConfig())
+	s.world.SetBlock(8, 199, 8, 1<<4)    // Stone
+	s.world.SetBlock(8, 200, 8, 51<<4|5) // Old fire
+
+	s.tickFire(8, 200, 8, s.world.GetBlock(8, 200, 8))
+
+	if got := s.world.GetBlock(8, 200, 8); got != 0 {
+		t.Errorf("old fire on stone should burn out, got block %d", got>>4)
+	}
+}
+
+func TestFireBurnsWool(t *testing.T) {
+	s := New(DefaultConfig())
+	s.world.SetBlock(8, 199, 8, 1<<4)  // Stone
+	s.world.SetBlock(9, 200, 8, 35<<4) // Wool
+
+	for i := 0; i < 1000; i++ {
+		s.world.SetBlock(8, 200, 8, 51<<4)
+		s.tickFire(8, 200, 8, 51<<4)
+		if s.world.GetBlock(9, 200, 8)>>4 != 35 {
+			return
+		}
+	}
+	t.Error("wool next to fire should eventually burn")
+}
+
+func TestDoFireTickDisabled(t *testing.T) {
+	s := New(DefaultConfig())
+	s.gamerules["doFireTick"] = "false"
+	s.world.SetBlock(8, 200, 8, 51<<4) // Unsupported fire
+
+	s.tickFire(8, 200, 8, 51<<4)
+
+	if got := s.world.GetBlock(8, 200, 8) >> 4; got != 51 {
+		t.Errorf("fire should not update when doFireTick is false, got block %d", got)
+	}
+}
+
+func TestPlayerBurnsInFire(t *testing.T) {
+	s := New(DefaultConfig())
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	go func() {
+		for {
+			if _, err := protocol.ReadPacket(c2); err != nil {
+				return
+			}
+		}
+	}()
+
+	player := &Player{EntityID: 1, Username: "Burner", Health: 20.0, GameMode: GameModeSurvival, Conn: c1, X: 8.5, Y: 200, Z: 8.5}
+	s.players[player.EntityID] = player
+	s.world.SetBlock(8, 200, 8, 51<<4)
+
+	s.tickPlayerFire(player)
+
+	player.mu.Lock()
+	health, fireTicks := player.Health, player.FireTicks
+	player.mu.Unlock()
+	if health >= 20 {
+		t.Errorf("player standing in fire should take damage, health = %v", health)
+	}
+	if fireTicks != fireBurnTicks {
+		t.Errorf("FireTicks = %d, want %d", fireTicks, fireBurnTicks)
+	}
+
+	// Stepping into water puts the player out
+	s.world.SetBlock(8, 200, 8, 9<<4)
+	s.tickPlayerFire(player)
+	player.mu.Lock()
+	fireTicks = player.FireTicks
+	player.mu.Unlock()
+	if fireTicks != 0 {
+		t.Errorf("water should extinguish the player, FireTicks = %d", fireTicks)
+	}
+}
@@ -19,6 +19,7 @@ const (
 
 // Entity metadata flags (index 0, type byte).
 const (
+	EntityFlagOnFire    byte = 0x01
 	EntityFlagInvisible byte = 0x20
 )
 
@@ -132,16 +133,27 @@ func (s *Server) broadcastPlayerListGamemode(player *Player) {
 	}
 }
 
-// broadcastEntityFlags sends an Entity Metadata packet (0x1C) to all players
-// with updated entity flags (index 0) for the given player.
+// playerEntityFlags returns the entity flags (metadata index 0) for a player.
+// Must be called with player.mu held.
+func playerEntityFlags(player *Player) byte {
+	var flags byte
+	if player.FireTicks > 0 {
+		flags |= EntityFlagOnFire
+	}
+	if player.GameMode == GameModeSpectator {
+		flags |= EntityFlagInvisible
+	}
+	return flags
+}
+
+// broadcastEntityFlags sends an Entity Metadata packet (0x1C) to the player and
+// everyone tracking them with updated entity flags (index 0) for the given player.
 // In spectator mode, the invisible flag (0x20) is set so the player appears
 // as a transparent head to other spectators and is invisible to non-spectators.
+// Burning players have the on-fire flag (0x01) set.
 func (s *Server) broadcastEntityFlags(player *Player) {
 	player.mu.Lock()
-	var flags byte
-	if player.GameMode == GameModeSpectator {
-		flags = EntityFlagInvisible
-	}
+	flags := playerEntityFlags(player)
 	entityID := player.EntityID
 	player.mu.Unlock()
 
@@ -152,13 +164,5 @@ func (s *Server) broadcastEntityFlags(player *Player) {
 		protocol.WriteByte(w, 0x7F) // Metadata terminator
 	})
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, p := range s.players {
-		p.mu.Lock()
-		if p.Conn != nil {
-			protocol.WritePacket(p.Conn, pkt)
-		}
-		p.mu.Unlock()
-	}
+	s.sendToPlayerAndViewers(player, pkt)
 }
@@ -294,6 +294,8 @@ func TestSpectatorEntityFlagsInvisible(t *testing.T) {
 		GameMode: GameModeSpectator,
 	}
 	s.players[spectator.EntityID] = spectator
+	player.trackedEntities = make(map[int32]bool)
+	s.trackEntity(player, spectator.EntityID)
 
 	// Look for entity metadata packet with invisible flag
 	receivedInvisible := false
@@ -352,6 +354,8 @@ func TestNonSpectatorEntityFlagsVisible(t *testing.T) {
 		GameMode: GameModeCreative,
 	}
 	s.players[creative.EntityID] = creative
+	player.trackedEntities = make(map[int32]bool)
+	s.trackEntity(player, creative.EntityID)
 
 	receivedMetadata := false
 	flagsValue := byte(0xFF)
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+// interestIndex maps chunks and entities to the players who can see them, so
+// broadcasts only visit interested players instead of every connected one.
+// It mirrors Player.loadedChunks and Player.trackedEntities, which stay the
+// source of truth: broadcasts still check them under the player's lock.
+//
+// The index lock is taken last, after s.mu and player.mu, and no other lock
+// is taken while holding it.
+type interestIndex struct {
+	mu       sync.RWMutex
+	chunks   map[ChunkPos]map[*Player]struct{}
+	entities map[int32]map[*Player]struct{}
+}
+
+// addViewer records p in the viewer set for key.
+func addViewer[K comparable](sets map[K]map[*Player]struct{}, key K, p *Player) {
+	set, ok := sets[key]
+	if !ok {
+		set = make(map[*Player]struct{})
+		sets[key] = set
+	}
+	set[p] = struct{}{}
+}
+
+// removeViewer drops p from the viewer set for key, deleting empty sets.
+func removeViewer[K comparable](sets map[K]map[*Player]struct{}, key K, p *Player) {
+	set, ok := sets[key]
+	if !ok {
+		return
+	}
+	delete(set, p)
+	if len(set) == 0 {
+		delete(sets, key)
+	}
+}
+
+// viewersOf returns a copy of the viewer set for key.
+func viewersOf[K comparable](sets map[K]map[*Player]struct{}, key K) []*Player {
+	set := sets[key]
+	viewers := make([]*Player, 0, len(set))
+	for p := range set {
+		viewers = append(viewers, p)
+	}
+	return viewers
+}
+
+// addChunk records that p has chunk cp loaded.
+func (ix *interestIndex) addChunk(p *Player, cp ChunkPos) {
+	ix.mu.Lock()
+	if ix.chunks == nil {
+		ix.chunks = make(map[ChunkPos]map[*Player]struct{})
+	}
+	addViewer(ix.chunks, cp, p)
+	ix.mu.Unlock()
+}
+
+// removeChunk records that p unloaded chunk cp.
+func (ix *interestIndex) removeChunk(p *Player, cp ChunkPos) {
+	ix.mu.Lock()
+	removeViewer(ix.chunks, cp, p)
+	ix.mu.Unlock()
+}
+
+// chunkViewers returns the players who have chunk cp loaded.
+func (ix *interestIndex) chunkViewers(cp ChunkPos) []*Player {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return viewersOf(ix.chunks, cp)
+}
+
+// addEntity records that p is tracking entity eid.
+func (ix *interestIndex) addEntity(p *Player, eid int32) {
+	ix.mu.Lock()
+	if ix.entities == nil {
+		ix.entities = make(map[int32]map[*Player]struct{})
+	}
+	addViewer(ix.entities, eid, p)
+	ix.mu.Unlock()
+}
+
+// removeEntity records that p stopped tracking entity eid.
+func (ix *interestIndex) removeEntity(p *Player, eid int32) {
+	ix.mu.Lock()
+	removeViewer(ix.entities, eid, p)
+	ix.mu.Unlock()
+}
+
+// entityViewers returns the players tracking entity eid.
+func (ix *interestIndex) entityViewers(eid int32) []*Player {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return viewersOf(ix.entities, eid)
+}
+
+// trackEntity marks entity eid as spawned for the player. The caller holds player.mu.
+func (s *Server) trackEntity(player *Player, eid int32) {
+	player.trackedEntities[eid] = true
+	s.interest.addEntity(player, eid)
+}
+
+// untrackEntity marks entity eid as despawned for the player. The caller holds player.mu.
+func (s *Server) untrackEntity(player *Player, eid int32) {
+	delete(player.trackedEntities, eid)
+	s.interest.removeEntity(player, eid)
+}
+
+// releaseTrackedEntities removes a disconnecting player from the viewers of
+// every entity they were tracking.
+func (s *Server) releaseTrackedEntities(player *Player) {
+	player.mu.Lock()
+	defer player.mu.Unlock()
+	for eid := range player.trackedEntities {
+		s.untrackEntity(player, eid)
+	}
+}
+
+// sendToChunkViewers sends pkt to every player who has chunk cp loaded,
+// except the given player (nil to send to everyone).
+func (s *Server) sendToChunkViewers(cp ChunkPos, pkt *protocol.Packet, except *Player) {
+	for _, p := range s.interest.chunkViewers(cp) {
+		if p == except {
+			continue
+		}
+		p.mu.Lock()
+		if p.Conn != nil && p.loadedChunks[cp] {
+			protocol.WritePacket(p.Conn, pkt)
+		}
+		p.mu.Unlock()
+	}
+}
+
+// sendToEntityViewers sends pkts to every player tracking entity eid.
+func (s *Server) sendToEntityViewers(eid int32, pkts ...*protocol.Packet) {
+	for _, p := range s.interest.entityViewers(eid) {
+		p.mu.Lock()
+		if p.Conn != nil && p.trackedEntities[eid] {
+			for _, pkt := range pkts {
+				protocol.WritePacket(p.Conn, pkt)
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+// sendToPlayerAndViewers sends pkt to the player and every player tracking them.
+func (s *Server) sendToPlayerAndViewers(player *Player, pkt *protocol.Packet) {
+	player.mu.Lock()
+	entityID := player.EntityID
+	if player.Conn != nil {
+		protocol.WritePacket(player.Conn, pkt)
+	}
+	player.mu.Unlock()
+	s.sendToEntityViewers(entityID, pkt)
+}
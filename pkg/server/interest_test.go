@@ -0,0 +1,89 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInterestIndex(t *testing.T) {
+	var ix interestIndex
+	a := &Player{Username: "A"}
+	b := &Player{Username: "B"}
+
+	ix.addChunk(a, ChunkPos{0, 0})
+	ix.addChunk(b, ChunkPos{0, 0})
+	ix.addChunk(b, ChunkPos{1, 0})
+	if n := len(ix.chunkViewers(ChunkPos{0, 0})); n != 2 {
+		t.Errorf("chunk (0, 0) has %d viewers, want 2", n)
+	}
+	ix.removeChunk(a, ChunkPos{0, 0})
+	if v := ix.chunkViewers(ChunkPos{0, 0}); len(v) != 1 || v[0] != b {
+		t.Errorf("chunk (0, 0) viewers = %v, want only B", v)
+	}
+	ix.removeChunk(b, ChunkPos{0, 0})
+	if _, ok := ix.chunks[ChunkPos{0, 0}]; ok {
+		t.Error("empty viewer set for chunk (0, 0) was not deleted")
+	}
+	if n := len(ix.chunkViewers(ChunkPos{9, 9})); n != 0 {
+		t.Errorf("unknown chunk has %d viewers, want 0", n)
+	}
+
+	ix.addEntity(a, 7)
+	ix.removeEntity(b, 7) // Not a viewer, no-op
+	if v := ix.entityViewers(7); len(v) != 1 || v[0] != a {
+		t.Errorf("entity 7 viewers = %v, want only A", v)
+	}
+}
+
+func TestBroadcastsReachOnlyViewers(t *testing.T) {
+	s := newCommandTestServer(t)
+	near, packets := addPacketTestPlayer(t, s, 1, "Near")
+	far := addCommandTestPlayer(t, s, 2, "Far")
+	far.loadedChunks[ChunkPos{5, 5}] = true
+	s.interest.addChunk(far, ChunkPos{5, 5})
+
+	near.mu.Lock()
+	s.trackEntity(near, 100)
+	near.mu.Unlock()
+
+	s.broadcastEffect(1003, 3, 70, 4, 0)
+	if pkt := nextPacket(t, packets); pkt.ID != 0x28 {
+		t.Fatalf("packet 0x%02X, want Effect", pkt.ID)
+	}
+	s.broadcastEntityStatus(100, 2)
+	if pkt := nextPacket(t, packets); pkt.ID != 0x1A {
+		t.Fatalf("packet 0x%02X, want Entity Status", pkt.ID)
+	}
+	s.broadcastEntityStatus(101, 2) // Untracked entity
+	s.broadcastDestroyEntity(100)
+	if pkt := nextPacket(t, packets); pkt.ID != 0x13 {
+		t.Fatalf("packet 0x%02X, want Destroy Entities", pkt.ID)
+	}
+	if v := s.interest.entityViewers(100); len(v) != 0 {
+		t.Errorf("destroyed entity still has %d viewers", len(v))
+	}
+	select {
+	case pkt := <-packets:
+		t.Errorf("unexpected packet 0x%02X", pkt.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestReleaseTrackedEntities(t *testing.T) {
+	s := newCommandTestServer(t)
+	p := addCommandTestPlayer(t, s, 1, "Leaver")
+	p.mu.Lock()
+	s.trackEntity(p, 10)
+	s.trackEntity(p, 11)
+	p.mu.Unlock()
+
+	s.releaseTrackedEntities(p)
+	for _, eid := range []int32{10, 11} {
+		if v := s.interest.entityViewers(eid); len(v) != 0 {
+			t.Errorf("entity %d still has %d viewers after release", eid, len(v))
+		}
+	}
+	if len(p.trackedEntities) != 0 {
+		t.Errorf("player still tracks %d entities", len(p.trackedEntities))
+	}
+}
@@ -110,6 +110,24 @@ func (s *Server) handleCloseWindow(player *Player, r *bytes.Reader) {
 			}
 		}
 		player.Inventory[0] = Slot{ItemID: -1}
+	} else if windowID == player.OpenWindowID && player.OpenContainer != nil {
+		// Chest contents stay in the chest
+		player.OpenContainer = nil
+		player.OpenWindowID = 0
+	} else if windowID == player.OpenWindowID && player.OpenMerchant != nil {
+		// Return unspent items from the trade inputs to inventory
+		for i := 0; i < 2; i++ {
+			if player.MerchantSlots[i].ItemID != -1 {
+				_, ok := addItemToInventory(player, player.MerchantSlots[i].ItemID, player.MerchantSlots[i].Damage, player.MerchantSlots[i].Count)
+				if !ok {
+					dropItems = append(dropItems, player.MerchantSlots[i])
+				}
+			}
+			player.MerchantSlots[i] = Slot{ItemID: -1}
+		}
+		player.MerchantSlots[2] = Slot{ItemID: -1}
+		player.OpenMerchant = nil
+		player.OpenWindowID = 0
 	} else if windowID == player.OpenWindowID {
 		// Return items from crafting table grid to inventory
 		for i := 0; i < 9; i++ {
@@ -537,33 +555,47 @@ func (s *Server) handleWindowClick(player *Player, windowID byte, slotNum int16,
 	}
 
 	px, py, pz := player.X, player.Y, player.Z
-	totalSlots := int16(46) // 1 output + 9 grid + 27 main + 9 hotbar
-
-	// Slot accessors: translate window slot to storage
-	getSlot := func(n int16) Slot {
-		switch {
-		case n == 0:
-			return player.CraftTableOutput
-		case n >= 1 && n <= 9:
-			return player.CraftTableGrid[n-1]
-		case n >= 10 && n <= 45:
-			return player.Inventory[n-1]
-		}
-		return Slot{ItemID: -1}
+	container := player.OpenContainer
+	merchant := player.OpenMerchant != nil
+	crafting := container == nil && !merchant
+	// Crafting table: 1 output + 9 grid; chest: 27 slots; trade window:
+	// 2 inputs + 1 output. Then 27 main + 9 hotbar.
+	containerSlots, totalSlots := windowLayout(player)
+	hotbarStart := containerSlots + 27
+	outputSlot := int16(-1)
+	if crafting {
+		outputSlot = 0
+	} else if merchant {
+		outputSlot = 2
 	}
-	setSlot := func(n int16, sl Slot) {
-		switch {
-		case n == 0:
-			player.CraftTableOutput = sl
-		case n >= 1 && n <= 9:
-			player.CraftTableGrid[n-1] = sl
-		case n >= 10 && n <= 45:
-			player.Inventory[n-1] = sl
+	// inWindow reports whether n is a window slot items can be put into
+	inWindow := func(n int16) bool { return n >= 0 && n < totalSlots && n != outputSlot }
+	traded := false
+
+	// A chest is shared with everyone looking into it, so hold its lock for
+	// the whole click: two players must not both take the same stack.
+	if container != nil {
+		container.mu.Lock()
+	}
+	// unlocked runs f with the player and container unlocked
+	unlocked := func(f func()) {
+		if container != nil {
+			container.mu.Unlock()
+		}
+		player.mu.Unlock()
+		f()
+		player.mu.Lock()
+		if container != nil {
+			container.mu.Lock()
 		}
 	}
 
+	// Slot accessors: translate window slot to storage
+	getSlot := func(n int16) Slot { return windowSlot(player, n) }
+	setSlot := func(n int16, sl Slot) { setWindowSlot(player, n, sl) }
+
 	// Handle crafting output (slot 0) specially
-	if slotNum == 0 {
+	if crafting && slotNum == 0 {
 		if mode == 0 && player.CraftTableOutput.ItemID != -1 {
 			result := player.CraftTableOutput
 			if player.Cursor.ItemID == -1 {
@@ -585,7 +617,12 @@ func (s *Server) handleWindowClick(player *Player, windowID byte, slotNum int16,
 				updateCraftOutput3x3(player)
 			}
 		}
-	} else if slotNum >= 1 && slotNum < totalSlots {
+	} else if merchant && slotNum == outputSlot {
+		// Trade output: taking it makes the trade
+		if mode == 0 || mode == 1 {
+			traded = takeMerchantOutput(player, mode)
+		}
+	} else if inWindow(slotNum) {
 		if mode == 0 { // Normal click
 			sl := getSlot(slotNum)
 			if button == 0 { // Left click
@@ -639,14 +676,16 @@ func (s *Server) handleWindowClick(player *Player, windowID byte, slotNum int16,
 			sl := getSlot(slotNum)
 			if sl.ItemID != -1 {
 				var destStart, destEnd int16
-				if slotNum >= 1 && slotNum <= 9 {
-					destStart, destEnd = 10, 45
-				} else if slotNum >= 10 && slotNum <= 36 {
-					destStart, destEnd = 37, 45
-				} else if slotNum >= 37 && slotNum <= 45 {
-					destStart, destEnd = 10, 36
+				if slotNum < containerSlots {
+					destStart, destEnd = containerSlots, totalSlots-1
+				} else if merchant {
+					destStart, destEnd = 0, 1
+				} else if !crafting {
+					destStart, destEnd = 0, containerSlots-1
+				} else if slotNum < hotbarStart {
+					destStart, destEnd = hotbarStart, totalSlots-1
 				} else {
-					destStart, destEnd = 10, 45
+					destStart, destEnd = containerSlots, hotbarStart-1
 				}
 				remaining := sl.Count
 				for i := destStart; i <= destEnd && remaining > 0; i++ {
@@ -678,8 +717,8 @@ func (s *Server) handleWindowClick(player *Player, windowID byte, slotNum int16,
 				}
 			}
 		} else if mode == 2 { // Number key hotkey
-			hotbarWinSlot := int16(37) + int16(button)
-			if hotbarWinSlot >= 37 && hotbarWinSlot <= 45 {
+			hotbarWinSlot := hotbarStart + int16(button)
+			if hotbarWinSlot >= hotbarStart && hotbarWinSlot < totalSlots {
 				temp := getSlot(slotNum)
 				setSlot(slotNum, getSlot(hotbarWinSlot))
 				setSlot(hotbarWinSlot, temp)
@@ -689,9 +728,9 @@ func (s *Server) handleWindowClick(player *Player, windowID byte, slotNum int16,
 
 	// Mode 6: double-click collect
 	if mode == 6 && player.Cursor.ItemID != -1 {
-		for i := int16(1); i < totalSlots && player.Cursor.Count < 64; i++ {
+		for i := int16(0); i < totalSlots && player.Cursor.Count < 64; i++ {
 			sl := getSlot(i)
-			if sl.ItemID == player.Cursor.ItemID && sl.Damage == player.Cursor.Damage {
+			if inWindow(i) && sl.ItemID == player.Cursor.ItemID && sl.Damage == player.Cursor.Damage {
 				space := 64 - player.Cursor.Count
 				if sl.Count <= space {
 					player.Cursor.Count += sl.Count
@@ -715,11 +754,11 @@ func (s *Server) handleWindowClick(player *Player, windowID byte, slotNum int16,
 			player.DragSlots = nil
 			player.DragButton = 1
 		case 1:
-			if slotNum >= 1 && slotNum < totalSlots {
+			if inWindow(slotNum) {
 				player.DragSlots = append(player.DragSlots, slotNum)
 			}
 		case 5:
-			if slotNum >= 1 && slotNum < totalSlots {
+			if inWindow(slotNum) {
 				player.DragSlots = append(player.DragSlots, slotNum)
 			}
 		case 2: // Left drag end
@@ -806,11 +845,9 @@ func (s *Server) handleWindowClick(player *Player, windowID byte, slotNum int16,
 				vx := -f1 * f4 * 0.3
 				vy := -f3*0.3 + 0.1
 				vz := f2 * f4 * 0.3
-				player.mu.Unlock()
-				s.SpawnItem(px, py+1.5, pz, vx, vy, vz, vitemID, vdamage, dropCount)
-				player.mu.Lock()
+				unlocked(func() { s.SpawnItem(px, py+1.5, pz, vx, vy, vz, vitemID, vdamage, dropCount) })
 			}
-		} else if slotNum >= 1 && slotNum < totalSlots {
+		} else if inWindow(slotNum) {
 			sl := getSlot(slotNum)
 			if sl.ItemID != -1 {
 				dropItemID := sl.ItemID
@@ -833,9 +870,7 @@ func (s *Server) handleWindowClick(player *Player, windowID byte, slotNum int16,
 				vx := -f1 * f4 * 0.3
 				vy := -f3*0.3 + 0.1
 				vz := f2 * f4 * 0.3
-				player.mu.Unlock()
-				s.SpawnItem(px, py+1.5, pz, vx, vy, vz, dropItemID, dropDamage, dropCount)
-				player.mu.Lock()
+				unlocked(func() { s.SpawnItem(px, py+1.5, pz, vx, vy, vz, dropItemID, dropDamage, dropCount) })
 			}
 		}
 	} else if slotNum == -999 && mode == 0 && player.Cursor.ItemID != -1 {
@@ -856,13 +891,18 @@ func (s *Server) handleWindowClick(player *Player, windowID byte, slotNum int16,
 		vx := -f1 * f4 * 0.3
 		vy := -f3*0.3 + 0.1
 		vz := f2 * f4 * 0.3
-		player.mu.Unlock()
-		s.SpawnItem(px, py+1.5, pz, vx, vy, vz, dropItemID, dropDamage, dropCount)
-		player.mu.Lock()
+		unlocked(func() { s.SpawnItem(px, py+1.5, pz, vx, vy, vz, dropItemID, dropDamage, dropCount) })
 	}
 
-	// Update crafting output
-	updateCraftOutput3x3(player)
+	// Update crafting or trade output
+	if crafting {
+		updateCraftOutput3x3(player)
+	} else if merchant {
+		updateMerchantOutput(player)
+	}
+	if container != nil {
+		container.mu.Unlock()
+	}
 
 	// Acknowledge action
 	confirmPkt := protocol.MarshalPacket(0x32, func(w *bytes.Buffer) {
@@ -872,27 +912,7 @@ func (s *Server) handleWindowClick(player *Player, windowID byte, slotNum int16,
 	})
 
 	// Send full window items sync
-	syncPkt := protocol.MarshalPacket(0x30, func(w *bytes.Buffer) {
-		protocol.WriteByte(w, windowID)
-		protocol.WriteInt16(w, totalSlots)
-		// Slot 0: crafting output
-		protocol.WriteSlotData(w, player.CraftTableOutput.ItemID, player.CraftTableOutput.Count, player.CraftTableOutput.Damage)
-		// Slots 1-9: crafting grid
-		for i := 0; i < 9; i++ {
-			sl := player.CraftTableGrid[i]
-			protocol.WriteSlotData(w, sl.ItemID, sl.Count, sl.Damage)
-		}
-		// Slots 10-36: main inventory (player slots 9-35)
-		for i := 9; i <= 35; i++ {
-			sl := player.Inventory[i]
-			protocol.WriteSlotData(w, sl.ItemID, sl.Count, sl.Damage)
-		}
-		// Slots 37-45: hotbar (player slots 36-44)
-		for i := 36; i <= 44; i++ {
-			sl := player.Inventory[i]
-			protocol.WriteSlotData(w, sl.ItemID, sl.Count, sl.Damage)
-		}
-	})
+	syncPkt := windowItemsPacket(player)
 	cursorPkt := protocol.MarshalPacket(0x2F, func(w *bytes.Buffer) {
 		protocol.WriteByte(w, 0xff)
 		protocol.WriteInt16(w, -1)
@@ -903,8 +923,15 @@ func (s *Server) handleWindowClick(player *Player, windowID byte, slotNum int16,
 		protocol.WritePacket(player.Conn, confirmPkt)
 		protocol.WritePacket(player.Conn, syncPkt)
 		protocol.WritePacket(player.Conn, cursorPkt)
+		if traded {
+			// Offer uses changed
+			protocol.WritePacket(player.Conn, merchantTradesPacket(player.OpenMerchant))
+		}
 	}
 
 	player.mu.Unlock()
+	if container != nil {
+		s.syncContainerViewers(container, player)
+	}
 	s.broadcastHeldItem(player)
 }
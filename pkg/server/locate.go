@@ -0,0 +1,82 @@
+package server
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// locateRadius is how far, in blocks, /locate searches for a structure.
+const locateRadius = 5000
+
+// registerStructureCommands registers /locate and /structureinfo.
+func (s *Server) registerStructureCommands() {
+	s.RegisterCommand(&Command{
+		Name:        "locate",
+		Description: "Finds the nearest structure of a kind",
+		Permission:  PermissionGameMode,
+		Syntaxes: []CommandSyntax{{
+			Args: []Arg{Required("structure", ChoiceArg{Options: world.StructureNames})},
+			Run:  s.runLocateCommand,
+		}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "structureinfo",
+		Description: "Shows the layout of the village you are standing in",
+		Permission:  PermissionGameMode,
+		Syntaxes:    []CommandSyntax{{Run: s.runStructureInfoCommand}},
+	})
+}
+
+// structureGenerator returns the generator of the world for the structure
+// commands, replying with an error if the sender is not a player or the
+// world has no structures.
+func (s *Server) structureGenerator(ctx *CommandContext) (*world.Generator, int, int, bool) {
+	if ctx.Sender == nil {
+		ctx.Error("This command can only be run by a player")
+		return nil, 0, 0, false
+	}
+	gen, ok := s.world.Gen.(*world.Generator)
+	if !ok {
+		ctx.Error("This world has no structures")
+		return nil, 0, 0, false
+	}
+	ctx.Sender.mu.Lock()
+	x, z := int(math.Floor(ctx.Sender.X)), int(math.Floor(ctx.Sender.Z))
+	ctx.Sender.mu.Unlock()
+	return gen, x, z, true
+}
+
+// runLocateCommand handles /locate <structure>.
+func (s *Server) runLocateCommand(ctx *CommandContext) {
+	gen, x, z, ok := s.structureGenerator(ctx)
+	if !ok {
+		return
+	}
+	name := ctx.String("structure")
+	sx, sz, found := gen.Locate(name, x, z, locateRadius)
+	if !found {
+		ctx.Error(fmt.Sprintf("No %s within %d blocks", name, locateRadius))
+		return
+	}
+	dist := math.Sqrt(float64((sx-x)*(sx-x) + (sz-z)*(sz-z)))
+	ctx.Info(fmt.Sprintf("The nearest %s is at %d, ~, %d (%.0f blocks away)", name, sx, sz, dist))
+}
+
+// runStructureInfoCommand handles /structureinfo, listing the buildings,
+// roads and farms of the village plan at the sender's position.
+func (s *Server) runStructureInfoCommand(ctx *CommandContext) {
+	gen, x, z, ok := s.structureGenerator(ctx)
+	if !ok {
+		return
+	}
+	plan, found := gen.VillagePlanAt(x, z)
+	if !found {
+		ctx.Error("You are not in a village")
+		return
+	}
+	for _, line := range plan.Describe() {
+		ctx.Info(line)
+	}
+}
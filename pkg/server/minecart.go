@@ -0,0 +1,134 @@
+package server
+
+import (
+	"bytes"
+	"math/rand"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+// MinecartEntity is a chest minecart, such as those left in abandoned
+// mineshafts. Minecarts stay where they were generated.
+type MinecartEntity struct {
+	EntityID  int32
+	X, Y, Z   float64
+	Container *Container
+}
+
+// spawnChunkMinecarts spawns the chest minecarts generated in chunk (cx, cz)
+// the first time the chunk is sent to a player.
+func (s *Server) spawnChunkMinecarts(cx, cz int32) {
+	features := s.world.Features(cx, cz)
+	for _, cart := range features.Minecarts {
+		s.mu.Lock()
+		if s.spawnedCarts[cart.Pos] {
+			s.mu.Unlock()
+			continue
+		}
+		s.spawnedCarts[cart.Pos] = true
+		eid := s.nextEID
+		s.nextEID++
+		m := &MinecartEntity{
+			EntityID:  eid,
+			X:         float64(cart.Pos.X) + 0.5,
+			Y:         float64(cart.Pos.Y),
+			Z:         float64(cart.Pos.Z) + 0.5,
+			Container: newLootContainer(cart),
+		}
+		s.minecarts[eid] = m
+		s.mu.Unlock()
+
+		s.broadcastSpawnMinecart(m)
+	}
+}
+
+func (s *Server) broadcastSpawnMinecart(m *MinecartEntity) {
+	s.mu.RLock()
+	players := make([]*Player, 0, len(s.players))
+	for _, p := range s.players {
+		players = append(players, p)
+	}
+	s.mu.RUnlock()
+
+	for _, p := range players {
+		if s.shouldTrack(p, m.X, m.Y, m.Z) {
+			s.sendMinecartToPlayer(p, m)
+			p.mu.Lock()
+			s.trackEntity(p, m.EntityID)
+			p.mu.Unlock()
+		}
+	}
+}
+
+func (s *Server) sendMinecartToPlayer(player *Player, m *MinecartEntity) {
+	// Spawn Object - 0x0E (Minecart, data 1 = chest minecart)
+	pkt := protocol.MarshalPacket(0x0E, func(w *bytes.Buffer) {
+		protocol.WriteVarInt(w, m.EntityID)
+		protocol.WriteByte(w, 10) // Type: Minecart
+		protocol.WriteInt32(w, int32(m.X*32))
+		protocol.WriteInt32(w, int32(m.Y*32))
+		protocol.WriteInt32(w, int32(m.Z*32))
+		protocol.WriteByte(w, 0) // Pitch
+		protocol.WriteByte(w, 0) // Yaw
+		protocol.WriteInt32(w, 1)
+		protocol.WriteInt16(w, 0) // Velocity X
+		protocol.WriteInt16(w, 0) // Velocity Y
+		protocol.WriteInt16(w, 0) // Velocity Z
+	})
+
+	player.mu.Lock()
+	if player.Conn != nil {
+		protocol.WritePacket(player.Conn, pkt)
+	}
+	player.mu.Unlock()
+}
+
+// handleInteractEntity handles a player right-clicking an entity.
+// Chest minecarts open their inventory and villagers their trade window.
+func (s *Server) handleInteractEntity(player *Player, targetID int32) {
+	s.mu.RLock()
+	cart, isCart := s.minecarts[targetID]
+	mob, isMob := s.mobEntities[targetID]
+	s.mu.RUnlock()
+	if !isCart && !(isMob && mob.Villager != nil) {
+		return
+	}
+	player.mu.Lock()
+	spectator := player.GameMode == GameModeSpectator
+	player.mu.Unlock()
+	if spectator {
+		return
+	}
+	if isCart {
+		s.openContainer(player, cart.Container, "Minecart with Chest")
+	} else {
+		s.openMerchant(player, mob.Villager)
+	}
+}
+
+// breakMinecart removes a chest minecart hit by a player, spilling its
+// contents. Outside creative mode the minecart and chest drop too.
+func (s *Server) breakMinecart(player *Player, cart *MinecartEntity) {
+	s.mu.Lock()
+	if _, ok := s.minecarts[cart.EntityID]; !ok {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.minecarts, cart.EntityID)
+	s.mu.Unlock()
+
+	s.broadcastDestroyEntity(cart.EntityID)
+	s.closeContainerViewers(cart.Container)
+	s.spillContainer(cart.Container, cart.X, cart.Y+0.5, cart.Z)
+
+	player.mu.Lock()
+	creative := player.GameMode == GameModeCreative
+	player.mu.Unlock()
+	if !creative {
+		for _, itemID := range []int16{328, 54} { // Minecart, chest
+			vx := rand.Float64()*0.2 - 0.1
+			vz := rand.Float64()*0.2 - 0.1
+			s.SpawnItem(cart.X, cart.Y+0.5, cart.Z, vx, 0.2, vz, itemID, 0, 1)
+		}
+	}
+}
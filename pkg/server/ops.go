@@ -0,0 +1,149 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Permission levels (vanilla op levels).
+const (
+	PermissionAll       = 0 // Every player
+	PermissionModerator = 1 // Bypass spawn protection
+	PermissionGameMode  = 2 // Cheat commands such as /gamemode, /tp and /gamerule
+	PermissionAdmin     = 3 // Player management such as /op and /kick
+	PermissionOwner     = 4 // Server management such as /stop
+)
+
+// DefaultOpLevel is the permission level given to players by /op.
+const DefaultOpLevel = PermissionOwner
+
+// OpEntry is an operator entry in ops.json (vanilla format).
+type OpEntry struct {
+	UUID                string `json:"uuid"`
+	Name                string `json:"name"`
+	Level               int    `json:"level"`
+	BypassesPlayerLimit bool   `json:"bypassesPlayerLimit"`
+}
+
+// dataPath returns the path of a server data file such as ops.json.
+func (s *Server) dataPath(name string) string {
+	return filepath.Join(s.config.DataDir, name)
+}
+
+// readJSONFile decodes a JSON file into v. A missing file is not an error.
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeJSONFile encodes v as indented JSON into the given file.
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// loadOps reads the operator list from ops.json.
+func (s *Server) loadOps() error {
+	var ops []OpEntry
+	if err := readJSONFile(s.dataPath("ops.json"), &ops); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.ops = ops
+	s.mu.Unlock()
+	log.Printf("Loaded %d operators", len(ops))
+	return nil
+}
+
+// saveOps writes the operator list to ops.json.
+func (s *Server) saveOps() error {
+	s.mu.RLock()
+	ops := append([]OpEntry{}, s.ops...)
+	s.mu.RUnlock()
+	return writeJSONFile(s.dataPath("ops.json"), ops)
+}
+
+// PermissionLevel returns the op level of a player, or 0 if they are not an operator.
+// A nil player is the server console, which has full permissions.
+func (s *Server) PermissionLevel(player *Player) int {
+	if player == nil {
+		return PermissionOwner
+	}
+	uuid := formatUUID(player.UUID)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, op := range s.ops {
+		if op.UUID == uuid || strings.EqualFold(op.Name, player.Username) {
+			return op.Level
+		}
+	}
+	return PermissionAll
+}
+
+// opLevel returns the op level of the named player, or 0 if they are not an operator.
+func (s *Server) opLevel(name string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, op := range s.ops {
+		if strings.EqualFold(op.Name, name) {
+			return op.Level
+		}
+	}
+	return PermissionAll
+}
+
+// SetOp makes the named player an operator with the given level, updating an existing entry.
+func (s *Server) SetOp(name string, level int) error {
+	uuid := formatUUID(offlineUUID(name))
+	s.mu.Lock()
+	found := false
+	for i, op := range s.ops {
+		if strings.EqualFold(op.Name, name) {
+			s.ops[i].Level = level
+			found = true
+		}
+	}
+	if !found {
+		s.ops = append(s.ops, OpEntry{UUID: uuid, Name: name, Level: level})
+	}
+	s.mu.Unlock()
+	return s.saveOps()
+}
+
+// RemoveOp removes the named player from the operator list. Returns false if they were not an operator.
+func (s *Server) RemoveOp(name string) (bool, error) {
+	s.mu.Lock()
+	removed := false
+	ops := s.ops[:0]
+	for _, op := range s.ops {
+		if strings.EqualFold(op.Name, name) {
+			removed = true
+			continue
+		}
+		ops = append(ops, op)
+	}
+	s.ops = ops
+	s.mu.Unlock()
+
+	if !removed {
+		return false, nil
+	}
+	return true, s.saveOps()
+}
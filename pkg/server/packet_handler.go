@@ -57,6 +57,7 @@ func (s *Server) handlePlayPacket(player *Player, pkt *protocol.Packet) {
 		player.mu.Unlock()
 		s.broadcastEntityTeleport(player)
 		s.sendChunkUpdates(player)
+		s.checkTraps(player)
 
 	case 0x05: // Player Look
 		yaw, _ := protocol.ReadFloat32(r)
@@ -89,6 +90,7 @@ func (s *Server) handlePlayPacket(player *Player, pkt *protocol.Packet) {
 		player.mu.Unlock()
 		s.broadcastEntityTeleport(player)
 		s.sendChunkUpdates(player)
+		s.checkTraps(player)
 
 	case 0x03: // Player (on ground)
 		onGround, _ := protocol.ReadBool(r)
@@ -113,7 +115,7 @@ func (s *Server) handlePlayPacket(player *Player, pkt *protocol.Packet) {
 		s.broadcastAnimation(player, 0)
 
 	case 0x15: // Client Settings
-	// Ignore for now
+		s.handleClientSettings(player, r)
 
 	case 0x13: // Player Abilities (serverbound)
 		// Client sends this when toggling flying or when F3+N is pressed to
@@ -147,7 +149,17 @@ func (s *Server) handlePlayPacket(player *Player, pkt *protocol.Packet) {
 		}
 
 	case 0x17: // Plugin Message
-	// Ignore for now
+		channel, err := protocol.ReadString(r)
+		if err != nil {
+			return
+		}
+		if channel == "MC|TrSel" {
+			index, err := protocol.ReadInt32(r)
+			if err != nil {
+				return
+			}
+			s.handleSelectTrade(player, index)
+		}
 
 	case 0x02: // Use Entity
 		targetID, _, err := protocol.ReadVarInt(r)
@@ -158,7 +170,9 @@ func (s *Server) handlePlayPacket(player *Player, pkt *protocol.Packet) {
 		if err != nil {
 			return
 		}
-		if useType == 1 { // Attack
+		if useType == 0 { // Interact
+			s.handleInteractEntity(player, targetID)
+		} else if useType == 1 { // Attack
 			s.handleAttack(player, targetID)
 		}
 
@@ -189,10 +203,14 @@ func (s *Server) handlePlayPacket(player *Player, pkt *protocol.Packet) {
 func (s *Server) handlePlayerDigging(player *Player, r *bytes.Reader) {
 	status, _ := protocol.ReadByte(r)
 	x, y, z, _ := protocol.ReadPosition(r)
-	_, _ = protocol.ReadByte(r) // face
+	face, _ := protocol.ReadByte(r)
 	if player.GameMode == GameModeSpectator {
 		return // spectators can't interact
 	}
+	if status == 0 && s.extinguishFire(x, y, z, face) {
+		// Punching a burning block puts out the fire instead of breaking it
+		return
+	}
 	if status == 0 && player.GameMode == GameModeCreative {
 		// Creative mode: instant break on start digging
 		s.handleBlockBreak(player, x, y, z)
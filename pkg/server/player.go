@@ -7,11 +7,13 @@ import (
 	"math"
 	"math/rand"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/VibeShit/VibeShitCraft/pkg/chat"
 	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
 )
 
 // Slot represents an inventory slot.
@@ -44,6 +46,10 @@ type Player struct {
 	CraftTableGrid   [9]Slot // 3x3 crafting grid for crafting table window
 	CraftTableOutput Slot    // Crafting output for crafting table window
 	OpenWindowID     byte    // Currently open window ID (0 = none/player inventory)
+	OpenContainer    *Container // Chest inventory shown in the open window (nil for crafting tables)
+	OpenMerchant     *Villager  // Villager whose trade window is open
+	MerchantSlots    [3]Slot    // Trade window: two inputs and the output
+	MerchantTrade    int        // Offer selected in the trade window
 	NoClip           bool    // True when in spectator mode (can pass through blocks)
 	DragSlots        []int16 // Slots being dragged over in mode 5
 	DragButton       int     // 0=left drag, 1=right drag
@@ -51,6 +57,19 @@ type Player struct {
 	ChunkQueue       chan ChunkPos // Queue for chunks that need to be generated and sent
 	FallStartY       float64      // Y position when the player started falling
 	IsFalling        bool         // Whether the player is currently falling
+	FireTicks        int          // Remaining environment ticks the player keeps burning
+	XPLevel          int32        // Experience level
+	XPProgress       float32      // Progress towards the next level (0..1)
+	XPTotal          int32        // Total experience collected
+	ViewDistance     int          // Render distance requested by the client, clamped to the server maximum
+	Locale           string       // Client language, e.g. "en_US"
+	SkinParts        byte         // Displayed skin parts bit mask from Client Settings
+	ChatMode         byte         // 0 = enabled, 1 = commands only, 2 = hidden
+	ChatColors       bool         // Whether the client displays chat colors
+	loadedViewDist   int32        // View distance the loadedChunks set was computed with
+	Selection        [2]*world.BlockPos // Corners chosen with /pos1 and /pos2
+	Clipboard        *Schematic         // Region copied with /copy or loaded with /schematic load
+	editHistory      [][]blockChange    // Blocks replaced by each /paste, newest last, for /undo
 	mu               sync.Mutex
 }
 
@@ -66,6 +85,11 @@ func (s *Server) handleLoginStart(conn net.Conn, pkt *protocol.Packet) (*Player,
 	// Generate offline-mode UUID (UUID v3 based on "OfflinePlayer:" + username)
 	uuid := offlineUUID(username)
 
+	if reason := s.loginDenyReason(username, uuid, addrIP(conn.RemoteAddr())); reason != "" {
+		sendLoginDisconnect(conn, reason)
+		return nil, fmt.Errorf("%s was refused: %s", username, strings.ReplaceAll(reason, "\n", " "))
+	}
+
 	// Kick any existing player with the same username to prevent duplicate logins
 	s.mu.RLock()
 	for _, existing := range s.players {
@@ -109,8 +133,12 @@ func (s *Server) handleLoginStart(conn net.Conn, pkt *protocol.Packet) (*Player,
 		Health:          20.0,
 		IsDead:          false,
 		NoClip:          s.config.DefaultGameMode == GameModeSpectator,
+		ViewDistance:    s.config.ViewDistance,
+		Locale:          defaultLocale,
+		SkinParts:       defaultSkinParts,
+		ChatColors:      true,
 		trackedEntities: make(map[int32]bool),
-		ChunkQueue:      make(chan ChunkPos, 1024), // Buffer large enough for a view distance of 10+
+		ChunkQueue:      make(chan ChunkPos, chunkQueueSize(s.config.ViewDistance)),
 	}
 
 	// Initialize all inventory slots as empty
@@ -146,7 +174,7 @@ func (s *Server) handlePlay(player *Player) {
 		protocol.WriteByte(w, 0)                         // Dimension: overworld
 		protocol.WriteByte(w, 0)                         // Difficulty: peaceful
 		protocol.WriteByte(w, byte(s.config.MaxPlayers)) // Max players
-		protocol.WriteString(w, "flat")                  // Level type: flat disables void fog
+		protocol.WriteString(w, s.world.Gen.LevelType()) // Level type
 		protocol.WriteBool(w, false)                     // Reduced debug info
 	})
 	protocol.WritePacket(conn, joinGame)
@@ -204,17 +232,25 @@ func (s *Server) handlePlay(player *Player) {
 	stopEnv := make(chan struct{})
 	go s.environmentLoop(player, stopEnv)
 
+	// Start experience orb pickup loop
+	stopXP := make(chan struct{})
+	go s.experiencePickupLoop(player, stopXP)
+
 	defer func() {
 		close(stopChunkLoop)
 		close(stopKeepAlive)
 		close(stopRegen)
 		close(stopPickup)
 		close(stopEnv)
+		close(stopXP)
 		// Remove from tab list before removing from players map
 		s.broadcastPlayerListRemove(player.UUID)
 		s.mu.Lock()
 		delete(s.players, player.EntityID)
 		s.mu.Unlock()
+		s.releasePlayerChunks(player)
+		s.releaseTrackedEntities(player)
+		s.releaseEditState(player)
 		s.broadcastChat(chat.Colored(player.Username+" left the game", "yellow"))
 		// Despawn for other players
 		s.broadcastDestroyEntity(player.EntityID)
@@ -282,9 +318,16 @@ func (s *Server) handlePlay(player *Player) {
 	// Spawn existing mob entities for this player
 	s.spawnMobEntitiesForPlayer(player)
 
+	// Spawn blocks that are currently falling
+	s.spawnFallingBlocksForPlayer(player)
+	s.spawnPrimedTNTForPlayer(player)
+
+	// Spawn experience orbs lying around
+	s.spawnExperienceOrbsForPlayer(player)
+
 	// Main packet read loop
 	for {
-		conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+		conn.SetReadDeadline(time.Now().Add(s.config.KeepAliveTimeout))
 		pkt, err := protocol.ReadPacket(conn)
 		if err != nil {
 			return
@@ -294,6 +337,12 @@ func (s *Server) handlePlay(player *Player) {
 	}
 }
 
+// chunkQueueSize returns a chunk queue buffer large enough to hold every chunk
+// within the given view distance, with a minimum of 1024.
+func chunkQueueSize(viewDistance int) int {
+	return max(1024, (2*viewDistance+1)*(2*viewDistance+1))
+}
+
 func (s *Server) chunkGenerationLoop(player *Player, stop chan struct{}) {
 	for {
 		select {
@@ -310,7 +359,7 @@ func (s *Server) chunkGenerationLoop(player *Player, stop chan struct{}) {
 }
 
 func (s *Server) keepAliveLoop(player *Player, stop chan struct{}) {
-	ticker := time.NewTicker(10 * time.Second)
+	ticker := time.NewTicker(s.config.KeepAliveInterval)
 	defer ticker.Stop()
 
 	for {
@@ -375,6 +424,9 @@ func (s *Server) environmentLoop(player *Player, stop chan struct{}) {
 				player.mu.Unlock()
 			}
 
+			// Fire and lava damage
+			s.tickPlayerFire(player)
+
 			// Acid water damage
 			if !s.GameRuleBool("acidWater") {
 				continue
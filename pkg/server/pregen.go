@@ -0,0 +1,317 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/chat"
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// MaxPregenRadius is the largest radius (in chunks) /pregen accepts.
+const MaxPregenRadius = 500
+
+// pregenReportInterval is how often pregeneration progress is reported.
+const pregenReportInterval = 5 * time.Second
+
+// PregenTask generates every chunk within a radius of spawn in the background.
+// Up to Config.PregenRetainLimit chunks, nearest to spawn first, are retained so
+// they stay loaded while no player is near them. The rest are evicted like any
+// other idle chunk once chunkEvictionGrace has passed.
+type PregenTask struct {
+	server *Server
+	radius int
+	chunks []world.ChunkPos // Chunks to generate, nearest to spawn first
+	report func(msg string)
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	next      int // Index of the next chunk to hand to a worker
+	done      int // Chunks finished (generated or already cached)
+	generated int // Chunks that actually had to be generated
+	paused    bool
+	cancelled bool
+	started   time.Time
+	pausedAt  time.Time
+	pausedFor time.Duration
+
+	finished chan struct{}
+}
+
+// Pregenerate starts generating all chunks within radius chunks of spawn.
+// Progress messages are passed to report as well as logged. Returns an error if
+// a pregeneration is already running.
+func (s *Server) Pregenerate(radius int, report func(msg string)) (*PregenTask, error) {
+	if radius < 0 || radius > MaxPregenRadius {
+		return nil, fmt.Errorf("radius must be between 0 and %d chunks", MaxPregenRadius)
+	}
+
+	s.mu.Lock()
+	if s.pregen != nil && !s.pregen.Finished() {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("a pregeneration is already running")
+	}
+	task := &PregenTask{
+		server:   s,
+		radius:   radius,
+		chunks:   pregenChunks(radius),
+		report:   report,
+		started:  time.Now(),
+		finished: make(chan struct{}),
+	}
+	task.cond = sync.NewCond(&task.mu)
+	s.pregen = task
+	s.mu.Unlock()
+
+	task.log(fmt.Sprintf("Pregenerating %d chunks within %d chunks of spawn", len(task.chunks), radius))
+	go task.run()
+	return task, nil
+}
+
+// pregenChunks returns the chunks within radius of the spawn chunk, sorted by distance.
+func pregenChunks(radius int) []world.ChunkPos {
+	r := int32(radius)
+	chunks := make([]world.ChunkPos, 0, (2*radius+1)*(2*radius+1))
+	for cx := -r; cx <= r; cx++ {
+		for cz := -r; cz <= r; cz++ {
+			chunks = append(chunks, world.ChunkPos{X: cx, Z: cz})
+		}
+	}
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return chunks[i].X*chunks[i].X+chunks[i].Z*chunks[i].Z < chunks[j].X*chunks[j].X+chunks[j].Z*chunks[j].Z
+	})
+	return chunks
+}
+
+// run generates the chunks with one worker per CPU. Generation itself is
+// throttled by the world's concurrency limiter, so players still get their chunks.
+func (t *PregenTask) run() {
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t.worker()
+		}()
+	}
+
+	stopReports := make(chan struct{})
+	go t.reportLoop(stopReports)
+
+	wg.Wait()
+	close(stopReports)
+	close(t.finished)
+
+	t.mu.Lock()
+	cancelled := t.cancelled
+	done, generated := t.done, t.generated
+	elapsed := time.Since(t.started) - t.pausedFor
+	t.mu.Unlock()
+
+	if cancelled {
+		t.log(fmt.Sprintf("Pregeneration cancelled after %d of %d chunks", done, len(t.chunks)))
+		return
+	}
+	t.log(fmt.Sprintf("Pregeneration finished: %d chunks (%d newly generated) in %s", done, generated, elapsed.Round(time.Second)))
+}
+
+// worker generates chunks until the task is finished or cancelled.
+func (t *PregenTask) worker() {
+	w := t.server.world
+	for {
+		t.mu.Lock()
+		for t.paused && !t.cancelled {
+			t.cond.Wait()
+		}
+		if t.cancelled || t.next >= len(t.chunks) {
+			t.mu.Unlock()
+			return
+		}
+		pos := t.chunks[t.next]
+		t.next++
+		t.mu.Unlock()
+
+		t.server.retainPregenChunk(pos)
+		generated := w.GenerateChunk(pos.X, pos.Z)
+
+		t.mu.Lock()
+		t.done++
+		if generated {
+			t.generated++
+		}
+		t.mu.Unlock()
+	}
+}
+
+// retainPregenChunk keeps a pregenerated chunk loaded while idle, unless
+// PregenRetainLimit chunks are already retained. Chunks stay retained across
+// pregenerations, so the limit bounds them all together.
+func (s *Server) retainPregenChunk(pos world.ChunkPos) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pregenRetained[pos] || len(s.pregenRetained) >= s.config.PregenRetainLimit {
+		return
+	}
+	s.pregenRetained[pos] = true
+	s.world.RetainChunk(pos.X, pos.Z)
+}
+
+// reportLoop reports progress periodically until stop is closed.
+func (t *PregenTask) reportLoop(stop chan struct{}) {
+	ticker := time.NewTicker(pregenReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			t.mu.Lock()
+			paused := t.paused
+			t.mu.Unlock()
+			if !paused {
+				t.log(t.Status())
+			}
+		}
+	}
+}
+
+// log logs a progress message and passes it to the task's report function.
+func (t *PregenTask) log(msg string) {
+	log.Print(msg)
+	if t.report != nil {
+		t.report(msg)
+	}
+}
+
+// Status returns a human-readable progress line with an ETA.
+func (t *PregenTask) Status() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	total := len(t.chunks)
+	elapsed := time.Since(t.started) - t.pausedFor
+	if t.paused {
+		elapsed -= time.Since(t.pausedAt)
+	}
+	percent := 100.0
+	if total > 0 {
+		percent = float64(t.done) * 100 / float64(total)
+	}
+
+	status := fmt.Sprintf("Pregenerating: %d/%d chunks (%.1f%%)", t.done, total, percent)
+	switch {
+	case t.Finished():
+		status = fmt.Sprintf("Pregeneration done: %d/%d chunks", t.done, total)
+	case t.paused:
+		status += ", paused"
+	case t.done > 0:
+		eta := time.Duration(float64(elapsed) / float64(t.done) * float64(total-t.done))
+		status += ", ETA " + eta.Round(time.Second).String()
+	}
+	return status
+}
+
+// Pause stops handing out new chunks until Resume is called.
+func (t *PregenTask) Pause() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.paused {
+		t.paused = true
+		t.pausedAt = time.Now()
+	}
+}
+
+// Resume continues a paused pregeneration.
+func (t *PregenTask) Resume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.paused {
+		t.paused = false
+		t.pausedFor += time.Since(t.pausedAt)
+		t.cond.Broadcast()
+	}
+}
+
+// Cancel stops the pregeneration. Chunks generated so far stay cached.
+func (t *PregenTask) Cancel() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cancelled = true
+	t.cond.Broadcast()
+}
+
+// Wait blocks until the pregeneration has finished or been cancelled.
+func (t *PregenTask) Wait() {
+	<-t.finished
+}
+
+// Finished returns true once all workers have stopped.
+func (t *PregenTask) Finished() bool {
+	select {
+	case <-t.finished:
+		return true
+	default:
+		return false
+	}
+}
+
+// registerPregenCommand registers /pregen.
+func (s *Server) registerPregenCommand() {
+	s.RegisterCommand(&Command{
+		Name:        "pregen",
+		Description: "Generates all chunks within a radius (in chunks) of spawn in the background",
+		Permission:  PermissionOwner,
+		Syntaxes: []CommandSyntax{
+			{
+				Args: []Arg{Required("radius", IntArg{Min: 1, Max: MaxPregenRadius})},
+				Run:  s.runPregenStartCommand,
+			},
+			{
+				Args: []Arg{Required("action", ChoiceArg{Options: []string{"status", "pause", "resume", "cancel"}})},
+				Run:  s.runPregenControlCommand,
+			},
+		},
+	})
+}
+
+// runPregenStartCommand handles /pregen <radius>.
+func (s *Server) runPregenStartCommand(ctx *CommandContext) {
+	sender := ctx.Sender
+	report := func(msg string) {
+		if sender != nil {
+			s.sendChatToPlayer(sender, chat.Colored(msg, "gray"))
+		}
+	}
+	if _, err := s.Pregenerate(ctx.Int("radius"), report); err != nil {
+		ctx.Error("Cannot start pregeneration: " + err.Error())
+	}
+}
+
+// runPregenControlCommand handles /pregen <status|pause|resume|cancel>.
+func (s *Server) runPregenControlCommand(ctx *CommandContext) {
+	s.mu.RLock()
+	task := s.pregen
+	s.mu.RUnlock()
+	if task == nil {
+		ctx.Error("No pregeneration has been started")
+		return
+	}
+
+	switch ctx.String("action") {
+	case "status":
+		ctx.Info(task.Status())
+	case "pause":
+		task.Pause()
+		ctx.Info("Pregeneration paused")
+	case "resume":
+		task.Resume()
+		ctx.Info("Pregeneration resumed")
+	case "cancel":
+		task.Cancel()
+		ctx.Info("Cancelling pregeneration")
+	}
+}
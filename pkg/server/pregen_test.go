@@ -0,0 +1,94 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPregenerate(t *testing.T) {
+	s := New(DefaultConfig())
+
+	task, err := s.Pregenerate(1, nil)
+	if err != nil {
+		t.Fatalf("Pregenerate: %v", err)
+	}
+	task.Wait()
+
+	for cx := int32(-1); cx <= 1; cx++ {
+		for cz := int32(-1); cz <= 1; cz++ {
+			if !s.world.IsChunkLoaded(cx, cz) {
+				t.Errorf("chunk (%d, %d) was not generated", cx, cz)
+			}
+		}
+	}
+
+	// Pregenerated chunks are kept past the grace period even though no player is nearby
+	s.world.EvictIdleChunks(0)
+	if n := s.world.LoadedChunkCount(); n != 9 {
+		t.Errorf("%d chunks loaded after eviction, want 9", n)
+	}
+	if status := task.Status(); !strings.Contains(status, "9/9") {
+		t.Errorf("Status() = %q", status)
+	}
+}
+
+func TestPregenerateRetainLimit(t *testing.T) {
+	config := DefaultConfig()
+	config.PregenRetainLimit = 5
+	s := New(config)
+
+	task, err := s.Pregenerate(1, nil)
+	if err != nil {
+		t.Fatalf("Pregenerate: %v", err)
+	}
+	task.Wait()
+
+	// Only the retained chunks outlive eviction, starting with the spawn chunk
+	s.world.EvictIdleChunks(0)
+	if n := s.world.LoadedChunkCount(); n != 5 {
+		t.Errorf("%d chunks loaded after eviction, want 5", n)
+	}
+	if !s.world.IsChunkLoaded(0, 0) {
+		t.Error("spawn chunk was evicted")
+	}
+
+	// A second run does not retain more chunks than the limit
+	task, err = s.Pregenerate(2, nil)
+	if err != nil {
+		t.Fatalf("Pregenerate: %v", err)
+	}
+	task.Wait()
+	s.world.EvictIdleChunks(0)
+	if n := s.world.LoadedChunkCount(); n != 5 {
+		t.Errorf("%d chunks loaded after a second pregeneration, want 5", n)
+	}
+}
+
+func TestPregeneratePauseAndCancel(t *testing.T) {
+	s := New(DefaultConfig())
+
+	task, err := s.Pregenerate(3, nil)
+	if err != nil {
+		t.Fatalf("Pregenerate: %v", err)
+	}
+	task.Pause()
+	if !strings.Contains(task.Status(), "paused") {
+		t.Errorf("Status() = %q, want paused", task.Status())
+	}
+	if _, err := s.Pregenerate(1, nil); err == nil {
+		t.Error("starting a second pregeneration should fail")
+	}
+
+	task.Cancel()
+	task.Wait()
+	if task.done == len(task.chunks) {
+		t.Error("cancelled pregeneration should not have finished every chunk")
+	}
+
+	// A new pregeneration can start once the previous one stopped
+	task, err = s.Pregenerate(0, nil)
+	if err != nil {
+		t.Fatalf("Pregenerate after cancel: %v", err)
+	}
+	task.Wait()
+}
@@ -0,0 +1,379 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// PropertiesFile is the name of the server configuration file.
+const PropertiesFile = "server.properties"
+
+// gameRulePropertyPrefix prefixes gamerule defaults in server.properties, e.g. "gamerule.keepInventory".
+const gameRulePropertyPrefix = "gamerule."
+
+// LoadConfig reads a server.properties file into a Config, starting from DefaultConfig.
+// If the file does not exist, it is created with the default values.
+func LoadConfig(path string) (Config, error) {
+	config := DefaultConfig()
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("%s not found, generating a default one", path)
+		return config, SaveConfig(path, config)
+	}
+	if err != nil {
+		return config, err
+	}
+	defer f.Close()
+
+	props, err := ReadProperties(f)
+	if err != nil {
+		return config, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := config.ApplyProperties(props); err != nil {
+		return config, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// SaveConfig writes a Config to a server.properties file.
+func SaveConfig(path string, config Config) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteProperties(f, config.Properties()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// SetProperty changes one key of a server.properties file, keeping the other
+// keys as they are. A missing file is created with just that key.
+func SetProperty(path, key, val string) error {
+	props := make(map[string]string)
+	f, err := os.Open(path)
+	if err == nil {
+		props, err = ReadProperties(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	props[key] = val
+
+	f, err = os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteProperties(f, props); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Properties returns the config as server.properties key/value pairs, using vanilla key names where they exist.
+func (c Config) Properties() map[string]string {
+	host, port, err := net.SplitHostPort(c.Address)
+	if err != nil {
+		host, port = c.Address, "25565"
+	}
+	worldType := c.WorldType
+	if worldType == "" {
+		worldType = world.WorldTypeDefault
+	}
+	seed := ""
+	if c.Seed != 0 {
+		seed = strconv.FormatInt(c.Seed, 10)
+	}
+
+	props := map[string]string{
+		"server-ip":             host,
+		"server-port":           port,
+		"max-players":           strconv.Itoa(c.MaxPlayers),
+		"motd":                  c.MOTD,
+		"level-seed":            seed,
+		"gamemode":              strconv.Itoa(int(c.DefaultGameMode)),
+		"white-list":            strconv.FormatBool(c.Whitelist),
+		"view-distance":         strconv.Itoa(c.ViewDistance),
+		"level-type":            strings.ToUpper(worldType),
+		"generator-settings":    c.GeneratorSettings,
+		"lava-level":            strconv.Itoa(c.LavaLevel),
+		"pregen-retain-limit":   strconv.Itoa(c.PregenRetainLimit),
+		"entity-tracking-range": strconv.FormatFloat(c.EntityTrackingRange, 'f', -1, 64),
+		"keep-alive-interval":   strconv.FormatFloat(c.KeepAliveInterval.Seconds(), 'f', -1, 64),
+		"keep-alive-timeout":    strconv.FormatFloat(c.KeepAliveTimeout.Seconds(), 'f', -1, 64),
+	}
+	for rule, val := range c.GameRules {
+		props[gameRulePropertyPrefix+rule] = val
+	}
+	return props
+}
+
+// ApplyProperties sets config fields from server.properties key/value pairs.
+// Unknown keys are ignored so that files written by vanilla servers can be used.
+func (c *Config) ApplyProperties(props map[string]string) error {
+	host, port, err := net.SplitHostPort(c.Address)
+	if err != nil {
+		host, port = "", "25565"
+	}
+
+	for key, val := range props {
+		switch key {
+		case "server-ip":
+			host = val
+		case "server-port":
+			if _, err := strconv.ParseUint(val, 10, 16); err != nil {
+				return fmt.Errorf("server-port: %q is not a valid port", val)
+			}
+			port = val
+		case "max-players":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 0 {
+				return fmt.Errorf("max-players: %q is not a valid player count", val)
+			}
+			c.MaxPlayers = n
+		case "motd":
+			c.MOTD = val
+		case "level-seed":
+			c.Seed = ParseSeed(val)
+		case "gamemode":
+			mode, ok := ParseGameMode(val)
+			if !ok {
+				return fmt.Errorf("gamemode: unknown game mode %q", val)
+			}
+			c.DefaultGameMode = mode
+		case "white-list":
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return fmt.Errorf("white-list: %q is not true or false", val)
+			}
+			c.Whitelist = b
+		case "level-type":
+			c.WorldType = strings.ToLower(val)
+		case "generator-settings":
+			c.GeneratorSettings = val
+		case "lava-level":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 || n >= world.ChunkHeight {
+				return fmt.Errorf("lava-level: %q must be a number between 1 and %d", val, world.ChunkHeight-1)
+			}
+			c.LavaLevel = n
+		case "pregen-retain-limit":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 0 {
+				return fmt.Errorf("pregen-retain-limit: %q is not a valid chunk count", val)
+			}
+			c.PregenRetainLimit = n
+		case "view-distance":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 2 || n > 32 {
+				return fmt.Errorf("view-distance: %q must be a number between 2 and 32", val)
+			}
+			c.ViewDistance = n
+		case "entity-tracking-range":
+			f, err := strconv.ParseFloat(val, 64)
+			if err != nil || f <= 0 {
+				return fmt.Errorf("entity-tracking-range: %q is not a positive number", val)
+			}
+			c.EntityTrackingRange = f
+		case "keep-alive-interval", "keep-alive-timeout":
+			f, err := strconv.ParseFloat(val, 64)
+			if err != nil || f <= 0 {
+				return fmt.Errorf("%s: %q is not a positive number of seconds", key, val)
+			}
+			d := time.Duration(f * float64(time.Second))
+			if key == "keep-alive-interval" {
+				c.KeepAliveInterval = d
+			} else {
+				c.KeepAliveTimeout = d
+			}
+		default:
+			if rule, ok := strings.CutPrefix(key, gameRulePropertyPrefix); ok && rule != "" {
+				if c.GameRules == nil {
+					c.GameRules = make(map[string]string)
+				}
+				c.GameRules[rule] = val
+			}
+		}
+	}
+
+	c.Address = net.JoinHostPort(host, port)
+
+	if _, err := world.NewChunkGenerator(c.WorldType, c.Seed, c.GeneratorSettings); err != nil {
+		return fmt.Errorf("level-type: %w", err)
+	}
+	return nil
+}
+
+// ParseSeed converts a level-seed value to a numeric seed like vanilla: numbers
+// are used as-is, other text is hashed with Java's String.hashCode, and an
+// empty value means a random seed (0).
+func ParseSeed(s string) int64 {
+	if s == "" {
+		return 0
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	var h int32
+	for _, c := range utf16Units(s) {
+		h = 31*h + int32(c)
+	}
+	return int64(h)
+}
+
+// utf16Units returns the UTF-16 code units of a string, as Java sees it.
+func utf16Units(s string) []uint16 {
+	var units []uint16
+	for _, r := range s {
+		if r >= 0x10000 {
+			r -= 0x10000
+			units = append(units, uint16(0xD800+(r>>10)), uint16(0xDC00+(r&0x3FF)))
+		} else {
+			units = append(units, uint16(r))
+		}
+	}
+	return units
+}
+
+// ReadProperties parses a Java .properties file. It supports comments,
+// "=" and ":" separators, line continuations and backslash escapes.
+func ReadProperties(r io.Reader) (map[string]string, error) {
+	props := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	var pending string
+	for scanner.Scan() {
+		line := pending + strings.TrimLeft(scanner.Text(), " \t\f")
+		pending = ""
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		// An odd number of trailing backslashes continues the line
+		if n := len(line) - len(strings.TrimRight(line, "\\")); n%2 == 1 {
+			pending = line[:len(line)-1]
+			continue
+		}
+
+		key, val := splitProperty(line)
+		props[unescapeProperty(key)] = unescapeProperty(val)
+	}
+	if pending != "" {
+		key, val := splitProperty(pending)
+		props[unescapeProperty(key)] = unescapeProperty(val)
+	}
+	return props, scanner.Err()
+}
+
+// splitProperty splits a property line at the first unescaped separator.
+func splitProperty(line string) (key, val string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':', ' ', '\t':
+			key = line[:i]
+			rest := strings.TrimLeft(line[i:], " \t")
+			if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				rest = strings.TrimLeft(rest[1:], " \t")
+			}
+			return key, rest
+		}
+	}
+	return line, ""
+}
+
+// unescapeProperty resolves backslash escapes in a property key or value.
+func unescapeProperty(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 < len(s) {
+				if n, err := strconv.ParseUint(s[i+1:i+5], 16, 16); err == nil {
+					b.WriteRune(rune(n))
+					i += 4
+					continue
+				}
+			}
+			b.WriteByte('u')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// escapeProperty escapes a property key or value so Java can read it back.
+// Non-ASCII characters are written as \uXXXX escapes.
+func escapeProperty(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\' || r == '=' || r == ':' || r == '#' || r == '!':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == ' ' && (isKey || i == 0):
+			b.WriteString("\\ ")
+		case r == '\n':
+			b.WriteString("\\n")
+		case r == '\t':
+			b.WriteString("\\t")
+		case r < 0x20 || r > 0x7E:
+			for _, u := range utf16Units(string(r)) {
+				fmt.Fprintf(&b, "\\u%04X", u)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// WriteProperties writes key/value pairs as a .properties file, sorted by key like vanilla.
+func WriteProperties(w io.Writer, props map[string]string) error {
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "#Minecraft server properties")
+	fmt.Fprintln(bw, "#"+time.Now().Format("Mon Jan 02 15:04:05 MST 2006"))
+	for _, key := range keys {
+		fmt.Fprintf(bw, "%s=%s\n", escapeProperty(key, true), escapeProperty(props[key], false))
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadProperties(t *testing.T) {
+	input := `#Minecraft server properties
+# comment
+server-port=25570
+motd=A \u00A7aGreen\: Server
+level-seed = hello
+view-distance:10
+gamerule.keepInventory=true
+long-value=first \
+    second
+`
+	props, err := ReadProperties(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadProperties: %v", err)
+	}
+	want := map[string]string{
+		"server-port":            "25570",
+		"motd":                   "A §aGreen: Server",
+		"level-seed":             "hello",
+		"view-distance":          "10",
+		"gamerule.keepInventory": "true",
+		"long-value":             "first second",
+	}
+	for key, val := range want {
+		if props[key] != val {
+			t.Errorf("%s = %q, want %q", key, props[key], val)
+		}
+	}
+}
+
+func TestApplyProperties(t *testing.T) {
+	config := DefaultConfig()
+	err := config.ApplyProperties(map[string]string{
+		"server-ip":              "127.0.0.1",
+		"server-port":            "25570",
+		"level-seed":             "hello",
+		"gamemode":               "1",
+		"view-distance":          "10",
+		"keep-alive-interval":    "5",
+		"gamerule.keepInventory": "true",
+		"spawn-protection":       "16", // Vanilla key we don't use
+	})
+	if err != nil {
+		t.Fatalf("ApplyProperties: %v", err)
+	}
+	if config.Address != "127.0.0.1:25570" {
+		t.Errorf("Address = %q", config.Address)
+	}
+	if config.Seed != 99162322 { // "hello".hashCode() in Java
+		t.Errorf("Seed = %d, want 99162322", config.Seed)
+	}
+	if config.DefaultGameMode != GameModeCreative || config.ViewDistance != 10 || config.KeepAliveInterval != 5*time.Second {
+		t.Errorf("config = %+v", config)
+	}
+	if config.GameRules["keepInventory"] != "true" || config.GameRules["doFireTick"] != "true" {
+		t.Errorf("GameRules = %v", config.GameRules)
+	}
+
+	if err := config.ApplyProperties(map[string]string{"view-distance": "100"}); err == nil {
+		t.Error("out of range view-distance should be rejected")
+	}
+	if err := config.ApplyProperties(map[string]string{"lava-level": "256"}); err == nil {
+		t.Error("out of range lava-level should be rejected")
+	}
+	if err := config.ApplyProperties(map[string]string{"pregen-retain-limit": "-1"}); err == nil {
+		t.Error("negative pregen-retain-limit should be rejected")
+	}
+
+	if err := config.ApplyProperties(map[string]string{"level-type": "FLAT", "generator-settings": "3;minecraft:stone;1"}); err != nil {
+		t.Errorf("flat level-type: %v", err)
+	} else if config.WorldType != "flat" {
+		t.Errorf("WorldType = %q, want flat", config.WorldType)
+	}
+	if err := config.ApplyProperties(map[string]string{"generator-settings": "3;minecraft:unobtainium"}); err == nil {
+		t.Error("invalid flat preset should be rejected")
+	}
+}
+
+func TestLoadConfigGeneratesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), PropertiesFile)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if config.MaxPlayers != 20 || config.ViewDistance != DefaultViewDistance {
+		t.Errorf("default config = %+v", config)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("default file not generated: %v", err)
+	}
+	for _, line := range []string{"server-port=25565", "view-distance=7", "gamerule.mobGriefing=true"} {
+		if !bytes.Contains(data, []byte(line+"\n")) {
+			t.Errorf("generated file is missing %q:\n%s", line, data)
+		}
+	}
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), PropertiesFile)
+	config := DefaultConfig()
+	config.MOTD = "Ümlaut = fun: #1"
+	config.Seed = -42
+	config.EntityTrackingRange = 64
+	config.KeepAliveTimeout = 45 * time.Second
+	config.LavaLevel = 30
+	if err := SaveConfig(path, config); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if loaded.MOTD != config.MOTD || loaded.Seed != -42 || loaded.EntityTrackingRange != 64 || loaded.KeepAliveTimeout != 45*time.Second || loaded.LavaLevel != 30 {
+		t.Errorf("round trip: got %+v", loaded)
+	}
+}
+
+func TestGameRuleDefaultsFromConfig(t *testing.T) {
+	config := DefaultConfig()
+	config.GameRules = map[string]string{"keepInventory": "true"}
+	s := New(config)
+	if !s.GameRuleBool("keepInventory") {
+		t.Error("keepInventory should come from the config")
+	}
+	if !s.GameRuleBool("doFireTick") {
+		t.Error("rules missing from the config should keep their defaults")
+	}
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+// Schematic is a box of blocks copied out of a world, in the layout of the
+// MCEdit/WorldEdit .schematic format.
+type Schematic struct {
+	Width, Height, Length int      // Size along X, Y and Z
+	Blocks                []uint16 // Block states, indexed by (y*Length+z)*Width+x
+	// Offset is where the box's minimum corner sits relative to the block
+	// the player stood on when copying it, and so where it is pasted.
+	OffsetX, OffsetY, OffsetZ int
+}
+
+// newSchematic creates an all-air schematic of the given size.
+func newSchematic(width, height, length int) *Schematic {
+	return &Schematic{Width: width, Height: height, Length: length, Blocks: make([]uint16, width*height*length)}
+}
+
+func (s *Schematic) index(x, y, z int) int {
+	return (y*s.Length+z)*s.Width + x
+}
+
+// At returns the block state at (x, y, z) inside the schematic.
+func (s *Schematic) At(x, y, z int) uint16 {
+	return s.Blocks[s.index(x, y, z)]
+}
+
+// Set sets the block state at (x, y, z) inside the schematic.
+func (s *Schematic) Set(x, y, z int, state uint16) {
+	s.Blocks[s.index(x, y, z)] = state
+}
+
+// Rotate returns a copy of the schematic turned clockwise (seen from above)
+// by the given number of quarter turns about the copy point. Directional
+// blocks such as stairs, doors, chests and logs are turned with it.
+func (s *Schematic) Rotate(turns int) *Schematic {
+	r := &Schematic{Width: s.Width, Height: s.Height, Length: s.Length, Blocks: append([]uint16(nil), s.Blocks...),
+		OffsetX: s.OffsetX, OffsetY: s.OffsetY, OffsetZ: s.OffsetZ}
+	for i := 0; i < turns&3; i++ {
+		r = r.rotateClockwise()
+	}
+	return r
+}
+
+// rotateClockwise returns the schematic turned a quarter turn clockwise.
+// Column (x, z) moves to (Length-1-z, x), and the offset turns the same way
+// about the copy point.
+func (s *Schematic) rotateClockwise() *Schematic {
+	r := newSchematic(s.Length, s.Height, s.Width)
+	r.OffsetX, r.OffsetY, r.OffsetZ = -s.OffsetZ-s.Length+1, s.OffsetY, s.OffsetX
+	for y := 0; y < s.Height; y++ {
+		for z := 0; z < s.Length; z++ {
+			for x := 0; x < s.Width; x++ {
+				r.Set(s.Length-1-z, y, x, rotateBlockState(s.At(x, y, z), 1))
+			}
+		}
+	}
+	return r
+}
+
+// Facing metadata of directional blocks, listed clockwise from north
+// (north, east, south, west), and the blocks using each encoding.
+var (
+	stairFacings = [4]uint16{3, 0, 2, 1}
+	torchFacings = [4]uint16{4, 1, 3, 2}
+	wallFacings  = [4]uint16{2, 5, 3, 4} // Ladders, wall signs, chests, furnaces, dispensers
+	doorFacings  = [4]uint16{3, 0, 1, 2}
+	southFacings = [4]uint16{2, 3, 0, 1} // Fence gates, beds and pumpkins
+	stairBlocks  = []uint16{53, 67, 108, 109, 114, 128, 134, 135, 136, 156, 163, 164, 180}
+	torchBlocks  = []uint16{50, 75, 76}
+	wallBlocks   = []uint16{23, 54, 61, 62, 65, 68, 130, 146, 154, 158}
+	doorBlocks   = []uint16{64, 71, 193, 194, 195, 196, 197}
+	southBlocks  = []uint16{26, 86, 91, 107, 183, 184, 185, 186, 187}
+	logBlocks    = []uint16{17, 162}
+)
+
+// rotateBlockState turns a block state clockwise by quarter turns. Blocks
+// without a horizontal facing are returned unchanged.
+func rotateBlockState(state uint16, turns int) uint16 {
+	turns &= 3
+	id, meta := state>>4, state&15
+	has := func(ids []uint16) bool {
+		for _, b := range ids {
+			if b == id {
+				return true
+			}
+		}
+		return false
+	}
+	// turn rotates the facing stored in the meta bits under mask
+	turn := func(facings [4]uint16, mask uint16) uint16 {
+		for i, f := range facings {
+			if meta&mask == f {
+				return id<<4 | meta&^mask | facings[(i+turns)&3]
+			}
+		}
+		return state
+	}
+
+	switch {
+	case has(stairBlocks):
+		return turn(stairFacings, 3)
+	case has(torchBlocks):
+		return turn(torchFacings, 7)
+	case has(wallBlocks):
+		return turn(wallFacings, 7)
+	case has(doorBlocks):
+		if meta&8 != 0 { // The upper half only stores the hinge side
+			return state
+		}
+		return turn(doorFacings, 3)
+	case has(southBlocks):
+		return turn(southFacings, 3)
+	case has(logBlocks):
+		if turns&1 == 1 && meta&12 != 0 && meta&12 != 12 {
+			meta ^= 12 // Swap the east-west and north-south axes
+		}
+		return id<<4 | meta
+	case id == 63: // Standing signs face one of 16 directions
+		return id<<4 | (meta+uint16(turns)*4)&15
+	}
+	return state
+}
+
+// WriteSchematic writes the schematic as a gzipped .schematic NBT file.
+func (s *Schematic) WriteSchematic(w io.Writer) error {
+	blocks := make([]byte, len(s.Blocks))
+	data := make([]byte, len(s.Blocks))
+	var add []byte
+	for i, state := range s.Blocks {
+		id := state >> 4
+		blocks[i] = byte(id)
+		data[i] = byte(state & 15)
+		if id > 255 {
+			if add == nil {
+				add = make([]byte, len(s.Blocks)>>1+1)
+			}
+			// AddBlocks packs the high 4 bits of two block IDs per byte, the first one in the low nibble
+			if i&1 == 0 {
+				add[i>>1] |= byte(id >> 8)
+			} else {
+				add[i>>1] |= byte(id>>8) << 4
+			}
+		}
+	}
+	root := protocol.NBTCompound{
+		"Width":        int16(s.Width),
+		"Height":       int16(s.Height),
+		"Length":       int16(s.Length),
+		"Materials":    "Alpha",
+		"Blocks":       blocks,
+		"Data":         data,
+		"Entities":     protocol.NBTList{},
+		"TileEntities": protocol.NBTList{},
+		"WEOffsetX":    int32(s.OffsetX),
+		"WEOffsetY":    int32(s.OffsetY),
+		"WEOffsetZ":    int32(s.OffsetZ),
+	}
+	if add != nil {
+		root["AddBlocks"] = add
+	}
+
+	zw := gzip.NewWriter(w)
+	if err := protocol.WriteNBT(zw, "Schematic", root); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// ReadSchematic reads a gzipped .schematic NBT file holding at most maxVolume
+// blocks. The size is checked before any space for the blocks is allocated.
+func ReadSchematic(r io.Reader, maxVolume int) (*Schematic, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	_, root, err := protocol.ReadNBT(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	if m, _ := root["Materials"].(string); m != "Alpha" {
+		return nil, fmt.Errorf("unsupported schematic materials %q", m)
+	}
+	width, _ := root["Width"].(int16)
+	height, _ := root["Height"].(int16)
+	length, _ := root["Length"].(int16)
+	if width <= 0 || height <= 0 || length <= 0 {
+		return nil, fmt.Errorf("invalid schematic size %dx%dx%d", width, height, length)
+	}
+	if v := int64(width) * int64(height) * int64(length); v > int64(maxVolume) {
+		return nil, fmt.Errorf("schematic has %d blocks, at most %d are allowed", v, maxVolume)
+	}
+	volume := int(width) * int(height) * int(length)
+	blocks, _ := root["Blocks"].([]byte)
+	data, _ := root["Data"].([]byte)
+	if len(blocks) != volume || len(data) != volume {
+		return nil, fmt.Errorf("schematic has %d blocks and %d data values, want %d", len(blocks), len(data), volume)
+	}
+	s := newSchematic(int(width), int(height), int(length))
+	add, _ := root["AddBlocks"].([]byte)
+	for i := range s.Blocks {
+		id := uint16(blocks[i])
+		if i>>1 < len(add) {
+			if i&1 == 0 {
+				id |= uint16(add[i>>1]&15) << 8
+			} else {
+				id |= uint16(add[i>>1]>>4) << 8
+			}
+		}
+		s.Blocks[i] = id<<4 | uint16(data[i]&15)
+	}
+	offX, _ := root["WEOffsetX"].(int32)
+	offY, _ := root["WEOffsetY"].(int32)
+	offZ, _ := root["WEOffsetZ"].(int32)
+	s.OffsetX, s.OffsetY, s.OffsetZ = int(offX), int(offY), int(offZ)
+	return s, nil
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"testing"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+func TestSchematicRoundTrip(t *testing.T) {
+	schem := newSchematic(3, 2, 2)
+	for i := range schem.Blocks {
+		schem.Blocks[i] = uint16(i+1)<<4 | uint16(i%16)
+	}
+	schem.Set(2, 1, 1, 300<<4|5) // Needs AddBlocks
+	schem.OffsetX, schem.OffsetY, schem.OffsetZ = -4, 1, 7
+
+	var buf bytes.Buffer
+	if err := schem.WriteSchematic(&buf); err != nil {
+		t.Fatalf("WriteSchematic: %v", err)
+	}
+	got, err := ReadSchematic(&buf, 100)
+	if err != nil {
+		t.Fatalf("ReadSchematic: %v", err)
+	}
+	if !reflect.DeepEqual(got, schem) {
+		t.Errorf("read back %+v, want %+v", got, schem)
+	}
+
+	if _, err := ReadSchematic(bytes.NewReader([]byte("not a schematic")), 100); err == nil {
+		t.Error("expected error for a file that is not gzipped NBT")
+	}
+}
+
+func TestReadSchematicTooBig(t *testing.T) {
+	write := func(root protocol.NBTCompound) *bytes.Buffer {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if err := protocol.WriteNBT(zw, "Schematic", root); err != nil {
+			t.Fatal(err)
+		}
+		zw.Close()
+		return &buf
+	}
+
+	// A tiny file claiming the largest possible size must not be allocated
+	huge := protocol.NBTCompound{
+		"Width":     int16(32767),
+		"Height":    int16(32767),
+		"Length":    int16(32767),
+		"Materials": "Alpha",
+		"Blocks":    []byte{1},
+		"Data":      []byte{0},
+	}
+	if _, err := ReadSchematic(write(huge), maxEditVolume); err == nil {
+		t.Error("expected error for a 32767x32767x32767 schematic")
+	}
+
+	// Within the limit, the size must still match the block arrays
+	lying := protocol.NBTCompound{
+		"Width":     int16(16),
+		"Height":    int16(16),
+		"Length":    int16(16),
+		"Materials": "Alpha",
+		"Blocks":    []byte{1},
+		"Data":      []byte{0},
+	}
+	if _, err := ReadSchematic(write(lying), maxEditVolume); err == nil {
+		t.Error("expected error for a schematic with fewer blocks than its size")
+	}
+}
+
+func TestSchematicRotate(t *testing.T) {
+	schem := newSchematic(3, 1, 2)
+	schem.OffsetX, schem.OffsetZ = 1, -5
+	schem.Set(0, 0, 0, 53<<4|0) // Oak stairs facing east
+	schem.Set(2, 0, 1, 17<<4|4) // East-west oak log
+	schem.Set(1, 0, 0, 54<<4|2) // Chest facing north
+
+	// worldAt returns the block a schematic pastes at (x, z) relative to the copy point
+	worldAt := func(s *Schematic, x, z int) uint16 {
+		lx, lz := x-s.OffsetX, z-s.OffsetZ
+		if lx < 0 || lz < 0 || lx >= s.Width || lz >= s.Length {
+			return 0xFFFF
+		}
+		return s.At(lx, 0, lz)
+	}
+
+	r := schem.Rotate(1)
+	if r.Width != 2 || r.Length != 3 {
+		t.Fatalf("rotated size %dx%d, want 2x3", r.Width, r.Length)
+	}
+	// Clockwise about the copy point, (x, z) moves to (-z, x)
+	if got := worldAt(r, 5, 1); got != 53<<4|2 {
+		t.Errorf("stairs = %d:%d, want facing south (53:2)", got>>4, got&15)
+	}
+	if got := worldAt(r, 4, 3); got != 17<<4|8 {
+		t.Errorf("log = %d:%d, want north-south (17:8)", got>>4, got&15)
+	}
+	if got := worldAt(r, 5, 2); got != 54<<4|5 {
+		t.Errorf("chest = %d:%d, want facing east (54:5)", got>>4, got&15)
+	}
+
+	if full := schem.Rotate(4); !reflect.DeepEqual(full, schem) {
+		t.Error("four quarter turns should give back the original")
+	}
+	if back := schem.Rotate(1).Rotate(3); !reflect.DeepEqual(back, schem) {
+		t.Error("rotating 90 then 270 degrees should give back the original")
+	}
+}
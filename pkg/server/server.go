@@ -23,22 +23,82 @@ type Config struct {
 	MOTD            string
 	Seed            int64
 	DefaultGameMode byte
+	DataDir         string // Directory holding ops.json and other data files ("" = working directory)
+	Whitelist       bool   // Only allow whitelisted players (and operators) to join
+
+	ViewDistance        int               // Chunks sent around a player in each direction
+	EntityTrackingRange float64           // Distance (in blocks) within which entities are spawned for players
+	KeepAliveInterval   time.Duration     // Time between Keep Alive packets
+	KeepAliveTimeout    time.Duration     // Time without any packet from a client before it is disconnected
+	GameRules           map[string]string // Initial gamerule values, overriding the built-in defaults
+	WorldType           string            // Terrain generator: default, flat, void or amplified
+	GeneratorSettings   string            // Superflat layer preset (flat worlds only)
+	LavaLevel           int               // Caves fill with lava at and below this Y (default and amplified worlds)
+	PregenRetainLimit   int               // Pregenerated chunks kept loaded while no player is near them (0 = none)
 }
 
+// DefaultViewDistance is the default number of chunks sent around a player in each direction.
+const DefaultViewDistance = 7
+
+// DefaultEntityTrackingRange is the default distance (in blocks) within which entities are spawned for players.
+const DefaultEntityTrackingRange = 48.0
+
+// DefaultPregenRetainLimit is the default number of pregenerated chunks kept
+// loaded while idle, enough for a radius of 16 chunks around spawn (about 140 MB).
+const DefaultPregenRetainLimit = 33 * 33
+
+// Default keep-alive timing.
+const (
+	DefaultKeepAliveInterval = 10 * time.Second
+	DefaultKeepAliveTimeout  = 30 * time.Second
+)
+
 // DefaultConfig returns a default server configuration.
 func DefaultConfig() Config {
 	return Config{
-		Address:    ":25565",
-		MaxPlayers: 20,
-		MOTD:       "A VibeShitCraft Server",
+		Address:             ":25565",
+		MaxPlayers:          20,
+		MOTD:                "A VibeShitCraft Server",
+		ViewDistance:        DefaultViewDistance,
+		EntityTrackingRange: DefaultEntityTrackingRange,
+		KeepAliveInterval:   DefaultKeepAliveInterval,
+		KeepAliveTimeout:    DefaultKeepAliveTimeout,
+		GameRules:           DefaultGameRules(),
+		LavaLevel:           world.DefaultLavaLevel,
+		PregenRetainLimit:   DefaultPregenRetainLimit,
 	}
 }
 
-// ViewDistance is the number of chunks sent around a player in each direction.
-const ViewDistance = 7
+// DefaultGameRules returns the built-in gamerule values.
+func DefaultGameRules() map[string]string {
+	return map[string]string{
+		"acidWater":       "false",
+		"acidWaterDamage": "1.0",
+		"doFireTick":      "true",
+		"mobGriefing":     "true",
+		"keepInventory":   "false",
+	}
+}
 
-// EntityTrackingRange is the distance (in blocks) within which entities are spawned for players.
-const EntityTrackingRange = 48.0
+// withDefaults returns the config with unset runtime settings replaced by their defaults.
+func (c Config) withDefaults() Config {
+	if c.ViewDistance <= 0 {
+		c.ViewDistance = DefaultViewDistance
+	}
+	if c.EntityTrackingRange <= 0 {
+		c.EntityTrackingRange = DefaultEntityTrackingRange
+	}
+	if c.KeepAliveInterval <= 0 {
+		c.KeepAliveInterval = DefaultKeepAliveInterval
+	}
+	if c.KeepAliveTimeout <= 0 {
+		c.KeepAliveTimeout = DefaultKeepAliveTimeout
+	}
+	if c.LavaLevel <= 0 {
+		c.LavaLevel = world.DefaultLavaLevel
+	}
+	return c
+}
 
 // ChunkPos identifies a chunk by its X and Z coordinates.
 type ChunkPos struct {
@@ -47,43 +107,117 @@ type ChunkPos struct {
 
 // Server is the core Minecraft server managing players, entities, and the world.
 type Server struct {
-	config      Config
-	listener    net.Listener
-	mu          sync.RWMutex
-	players     map[int32]*Player
-	entities    map[int32]*ItemEntity
-	mobEntities map[int32]*MobEntity
-	nextEID     int32
-	stopCh      chan struct{}
-	stopOnce    sync.Once
-	world       *world.World
-	gamerules   map[string]string
+	config        Config
+	seed          int64 // World seed, chosen at random when the config has none
+	listener      net.Listener
+	mu            sync.RWMutex
+	players       map[int32]*Player
+	entities      map[int32]*ItemEntity
+	mobEntities   map[int32]*MobEntity
+	fallingBlocks map[int32]*FallingBlockEntity
+	primedTNT     map[int32]*PrimedTNTEntity
+	xpOrbs        map[int32]*ExperienceOrb
+	minecarts     map[int32]*MinecartEntity
+	containers    map[world.BlockPos]*Container // Chest inventories, created when first opened
+	spawnedCarts  map[world.BlockPos]bool       // Generated chest minecarts already spawned
+	lootedChests  map[world.BlockPos]bool       // Generated chests already filled from their loot table
+	spawners      map[world.BlockPos]int        // Ticks until each active mob spawner next spawns
+	spawnedMobs   map[world.BlockPos]bool       // Generated structure mobs already spawned
+	trapCooldowns map[world.BlockPos]time.Time  // When each generated trap trigger can go off again
+	trapArrows    map[world.BlockPos]int        // Arrows left in generated trap dispensers
+	deaths        map[string][]DeathRecord      // Recent deaths keyed by lowercase username
+	nextEID       int32
+	stopCh        chan struct{}
+	stopOnce      sync.Once
+	world         *world.World
+	gamerules     map[string]string
+	commands      map[string]*Command // Registered commands keyed by lowercase name and alias
+	ops           []OpEntry
+	whitelist     []WhitelistEntry
+	bannedPlayers []BanEntry
+	bannedIPs     []IPBanEntry
+
+	whitelistEnabled bool
+	pregen           *PregenTask             // Most recent chunk pregeneration, if any
+	pregenRetained   map[world.ChunkPos]bool // Pregenerated chunks kept loaded while idle, at most PregenRetainLimit
+
+	tickMu         sync.Mutex
+	currentTick    int64                    // Game ticks elapsed since the server started
+	scheduledTicks map[world.BlockPos]int64 // Pending block updates keyed by position, value is the due tick
+
+	blockChangeMu sync.Mutex
+	blockChanges  map[ChunkPos]map[world.BlockPos]uint16 // Block changes to send at the end of the tick
+	interest      interestIndex                          // Players viewing each chunk and entity
 }
 
 // New creates a new server with the given configuration.
 func New(config Config) *Server {
+	config = config.withDefaults()
+	gamerules := DefaultGameRules()
+	for rule, val := range config.GameRules {
+		gamerules[rule] = val
+	}
+
 	seed := config.Seed
 	if seed == 0 {
 		seed = time.Now().UnixNano()
 	}
 	log.Printf("World seed: %d", seed)
-	return &Server{
-		config:      config,
-		players:     make(map[int32]*Player),
-		entities:    make(map[int32]*ItemEntity),
-		mobEntities: make(map[int32]*MobEntity),
-		nextEID:     1,
-		stopCh:      make(chan struct{}),
-		world:       world.NewWorld(seed),
-		gamerules: map[string]string{
-			"acidWater":       "false",
-			"acidWaterDamage": "1.0",
-		},
+	gen, err := world.NewChunkGenerator(config.WorldType, seed, config.GeneratorSettings)
+	if err != nil {
+		log.Printf("Invalid world type, using default terrain: %v", err)
+		gen = world.NewGenerator(seed)
+	}
+	if terrain, ok := gen.(*world.Generator); ok {
+		terrain.LavaLevel = config.LavaLevel
 	}
+	s := &Server{
+		config:         config,
+		seed:           seed,
+		players:        make(map[int32]*Player),
+		entities:       make(map[int32]*ItemEntity),
+		mobEntities:    make(map[int32]*MobEntity),
+		fallingBlocks:  make(map[int32]*FallingBlockEntity),
+		primedTNT:      make(map[int32]*PrimedTNTEntity),
+		xpOrbs:         make(map[int32]*ExperienceOrb),
+		minecarts:      make(map[int32]*MinecartEntity),
+		containers:     make(map[world.BlockPos]*Container),
+		spawnedCarts:   make(map[world.BlockPos]bool),
+		lootedChests:   make(map[world.BlockPos]bool),
+		spawners:       make(map[world.BlockPos]int),
+		spawnedMobs:    make(map[world.BlockPos]bool),
+		trapCooldowns:  make(map[world.BlockPos]time.Time),
+		trapArrows:     make(map[world.BlockPos]int),
+		deaths:         make(map[string][]DeathRecord),
+		nextEID:        1,
+		stopCh:         make(chan struct{}),
+		world:          world.NewWorldWithGenerator(gen),
+		gamerules:      gamerules,
+		scheduledTicks: make(map[world.BlockPos]int64),
+		blockChanges:   make(map[ChunkPos]map[world.BlockPos]uint16),
+		commands:       make(map[string]*Command),
+
+		whitelistEnabled: config.Whitelist,
+		pregenRetained:   make(map[world.ChunkPos]bool),
+	}
+	s.registerDefaultCommands()
+	s.registerAccessCommands()
+	s.registerPregenCommand()
+	s.registerStructureCommands()
+	s.registerWorldInfoCommands()
+	s.registerEditCommands()
+	return s
 }
 
 // Start begins listening for connections.
 func (s *Server) Start() error {
+	if err := s.loadOps(); err != nil {
+		log.Printf("Failed to load ops.json: %v", err)
+	}
+	if err := s.loadAccessLists(); err != nil {
+		log.Printf("Failed to load whitelist and ban lists: %v", err)
+	}
+
 	var err error
 	s.listener, err = net.Listen("tcp", s.config.Address)
 	if err != nil {
@@ -94,6 +228,9 @@ func (s *Server) Start() error {
 	go s.acceptLoop()
 	go s.entityPhysicsLoop()
 	go s.randomTickLoop()
+	go s.blockTickLoop()
+	go s.chunkEvictionLoop()
+	go s.spawnerLoop()
 	return nil
 }
 
@@ -114,9 +251,10 @@ func (s *Server) randomTickLoop() {
 				p.mu.Lock()
 				px := int32(p.X) >> 4
 				pz := int32(p.Z) >> 4
+				viewDistance := s.viewDistanceFor(p)
 				p.mu.Unlock()
-				for dx := -int32(ViewDistance); dx <= int32(ViewDistance); dx++ {
-					for dz := -int32(ViewDistance); dz <= int32(ViewDistance); dz++ {
+				for dx := -viewDistance; dx <= viewDistance; dx++ {
+					for dz := -viewDistance; dz <= viewDistance; dz++ {
 						loadedChunks[ChunkPos{X: px + dx, Z: pz + dz}] = struct{}{}
 					}
 				}
@@ -247,6 +385,12 @@ func (s *Server) randomTickLoop() {
 									}
 								}
 							}
+						case 10, 11: // Lava
+							if s.GameRuleBool("doFireTick") {
+								s.tickLavaIgnition(rx, ry, rz)
+							}
+						case 51: // Fire
+							s.tickFire(rx, ry, rz, blockState)
 						case 6: // Sapling
 							if rand.Float32() < 0.10 {
 								woodType := metadata & 0x07
@@ -324,7 +468,7 @@ func (s *Server) handleConnection(conn net.Conn) {
 	state := protocol.StateHandshaking
 
 	for {
-		conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+		conn.SetReadDeadline(time.Now().Add(s.config.KeepAliveTimeout))
 		pkt, err := protocol.ReadPacket(conn)
 		if err != nil {
 			return
@@ -438,6 +582,13 @@ func (s *Server) playerCount() int {
 	return len(s.players)
 }
 
+// GameRule returns the raw value of a gamerule, or "" if it does not exist.
+func (s *Server) GameRule(name string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.gamerules[name]
+}
+
 func (s *Server) GameRuleBool(name string) bool {
 	s.mu.RLock()
 	val, ok := s.gamerules[name]
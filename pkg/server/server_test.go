@@ -2,6 +2,8 @@ package server
 
 import (
 	"testing"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
 )
 
 func TestOfflineUUID(t *testing.T) {
@@ -532,3 +534,35 @@ func TestEntityGravityDoesNotAffectPlayers(t *testing.T) {
 		t.Errorf("player Y should not change, got %f", p.Y)
 	}
 }
+
+func TestLavaLevelConfig(t *testing.T) {
+	// countCaveLava counts lava above the default lava level in the caves of a 6x6 chunk area
+	countCaveLava := func(lavaLevel int) (lava, maxY int) {
+		srv := New(Config{Address: "127.0.0.1:0", MaxPlayers: 10, MOTD: "Test", Seed: 4242, LavaLevel: lavaLevel})
+		for cx := 0; cx < 6; cx++ {
+			for cz := 0; cz < 6; cz++ {
+				sections, _ := srv.world.Gen.GenerateInternal(cx, cz)
+				for sec := range sections {
+					for i, block := range sections[sec] {
+						if y := sec*16 + i/256; block>>4 == 11 && y > world.DefaultLavaLevel {
+							lava++
+							maxY = max(maxY, y)
+						}
+					}
+				}
+			}
+		}
+		return lava, maxY
+	}
+
+	if lava, _ := countCaveLava(0); lava != 0 {
+		t.Errorf("default lava level: %d lava blocks above y=%d", lava, world.DefaultLavaLevel)
+	}
+	lava, maxY := countCaveLava(40)
+	if lava == 0 {
+		t.Errorf("lava level 40: no lava above y=%d", world.DefaultLavaLevel)
+	}
+	if maxY > 40 {
+		t.Errorf("lava level 40: lava at y=%d", maxY)
+	}
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// spawnerMobTypes maps the entity names of generated spawners and structure
+// mobs to Spawn Mob type IDs.
+var spawnerMobTypes = map[string]byte{
+	"Skeleton":   51,
+	"Spider":     52,
+	"Zombie":     54,
+	"CaveSpider": 59,
+	"Witch":      66,
+}
+
+// Mob spawner behaviour, matching vanilla: a spawner is active while a player
+// is within spawnerActivationRange blocks, and every 10-40 seconds spawns up
+// to spawnerSpawnCount mobs within spawnerSpawnRange blocks horizontally,
+// unless spawnerMaxNearby of its mobs are already close by.
+const (
+	spawnerActivationRange = 16.0
+	spawnerSpawnRange      = 4
+	spawnerSpawnCount      = 4
+	spawnerMaxNearby       = 6
+	spawnerFirstDelay      = 20 // Ticks before a newly activated spawner first spawns
+	spawnerMinDelay        = 200
+	spawnerMaxDelay        = 800
+	spawnerInterval        = time.Second
+)
+
+// sendChunkSpawners sends the block entity data of the mob spawners in chunk
+// (cx, cz), so the client shows the spinning mob inside them.
+func (s *Server) sendChunkSpawners(player *Player, cx, cz int32) {
+	for _, sp := range s.world.Features(cx, cz).Spawners {
+		if s.world.GetBlock(sp.Pos.X, sp.Pos.Y, sp.Pos.Z)>>4 != 52 {
+			continue
+		}
+		pkt := protocol.MarshalPacket(0x35, func(w *bytes.Buffer) {
+			protocol.WritePosition(w, sp.Pos.X, sp.Pos.Y, sp.Pos.Z)
+			protocol.WriteByte(w, 1) // Action: set mob spawner data
+			protocol.WriteNBT(w, "", protocol.NBTCompound{
+				"id":       "MobSpawner",
+				"x":        sp.Pos.X,
+				"y":        sp.Pos.Y,
+				"z":        sp.Pos.Z,
+				"EntityId": sp.Mob,
+				"Delay":    int16(spawnerFirstDelay),
+			})
+		})
+		player.mu.Lock()
+		if player.Conn != nil {
+			protocol.WritePacket(player.Conn, pkt)
+		}
+		player.mu.Unlock()
+	}
+}
+
+func (s *Server) spawnerLoop() {
+	ticker := time.NewTicker(spawnerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.tickSpawners(int(spawnerInterval / (50 * time.Millisecond)))
+		}
+	}
+}
+
+// tickSpawners advances the active mob spawners by the given number of ticks
+// and spawns mobs from those whose delay ran out. Only spawners in loaded
+// chunks near a player are considered.
+func (s *Server) tickSpawners(ticks int) {
+	type viewer struct{ x, y, z float64 }
+	s.mu.RLock()
+	var viewers []viewer
+	for _, p := range s.players {
+		p.mu.Lock()
+		if !p.IsDead && p.GameMode != GameModeSpectator {
+			viewers = append(viewers, viewer{p.X, p.Y, p.Z})
+		}
+		p.mu.Unlock()
+	}
+	s.mu.RUnlock()
+
+	active := make(map[world.BlockPos]world.MobSpawner)
+	for _, v := range viewers {
+		pcx, pcz := int32(math.Floor(v.x))>>4, int32(math.Floor(v.z))>>4
+		for cx := pcx - 1; cx <= pcx+1; cx++ {
+			for cz := pcz - 1; cz <= pcz+1; cz++ {
+				if !s.world.IsChunkLoaded(cx, cz) {
+					continue
+				}
+				for _, sp := range s.world.Features(cx, cz).Spawners {
+					dx := float64(sp.Pos.X) + 0.5 - v.x
+					dy := float64(sp.Pos.Y) + 0.5 - v.y
+					dz := float64(sp.Pos.Z) + 0.5 - v.z
+					if dx*dx+dy*dy+dz*dz <= spawnerActivationRange*spawnerActivationRange &&
+						s.world.GetBlock(sp.Pos.X, sp.Pos.Y, sp.Pos.Z)>>4 == 52 {
+						active[sp.Pos] = sp
+					}
+				}
+			}
+		}
+	}
+
+	var ready []world.MobSpawner
+	s.mu.Lock()
+	for pos := range s.spawners {
+		if _, ok := active[pos]; !ok {
+			delete(s.spawners, pos) // Nobody nearby; restart the delay when someone returns
+		}
+	}
+	for pos, sp := range active {
+		delay, ok := s.spawners[pos]
+		if !ok {
+			delay = spawnerFirstDelay
+		}
+		delay -= ticks
+		if delay <= 0 {
+			ready = append(ready, sp)
+			delay = spawnerMinDelay + rand.Intn(spawnerMaxDelay-spawnerMinDelay)
+		}
+		s.spawners[pos] = delay
+	}
+	s.mu.Unlock()
+
+	for _, sp := range ready {
+		s.spawnFromSpawner(sp)
+	}
+}
+
+// spawnFromSpawner spawns a group of mobs around a spawner, on open ground.
+func (s *Server) spawnFromSpawner(sp world.MobSpawner) {
+	mobType, ok := spawnerMobTypes[sp.Mob]
+	if !ok {
+		return
+	}
+	cx, cy, cz := float64(sp.Pos.X)+0.5, float64(sp.Pos.Y), float64(sp.Pos.Z)+0.5
+
+	s.mu.RLock()
+	nearby := 0
+	for _, mob := range s.mobEntities {
+		dx, dy, dz := mob.X-cx, mob.Y-cy, mob.Z-cz
+		if mob.MobType == mobType && dx*dx+dz*dz <= 81 && dy*dy <= 16 {
+			nearby++
+		}
+	}
+	s.mu.RUnlock()
+
+	for i := 0; i < spawnerSpawnCount && nearby < spawnerMaxNearby; i++ {
+		x := sp.Pos.X + int32(rand.Intn(spawnerSpawnRange*2+1)-spawnerSpawnRange)
+		y := sp.Pos.Y + int32(rand.Intn(3)-1)
+		z := sp.Pos.Z + int32(rand.Intn(spawnerSpawnRange*2+1)-spawnerSpawnRange)
+		if !isSolidBlock(s.world.GetBlock(x, y-1, z)>>4) ||
+			isSolidBlock(s.world.GetBlock(x, y, z)>>4) || isSolidBlock(s.world.GetBlock(x, y+1, z)>>4) {
+			continue
+		}
+		s.SpawnMob(float64(x)+0.5, float64(y), float64(z)+0.5, mobType)
+		nearby++
+	}
+}
+
+// spawnStructureMobs spawns the mobs living in generated structures in chunk
+// (cx, cz), such as witch hut witches, the first time the chunk is sent.
+func (s *Server) spawnStructureMobs(cx, cz int32) {
+	for _, m := range s.world.Features(cx, cz).Mobs {
+		mobType, ok := spawnerMobTypes[m.Mob]
+		if !ok {
+			continue
+		}
+		s.mu.Lock()
+		spawned := s.spawnedMobs[m.Pos]
+		s.spawnedMobs[m.Pos] = true
+		s.mu.Unlock()
+		if !spawned {
+			s.SpawnMob(float64(m.Pos.X)+0.5, float64(m.Pos.Y), float64(m.Pos.Z)+0.5, mobType)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"math/rand"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+)
+
+// PrimedTNTEntity represents lit TNT counting down to an explosion (Spawn Object type 50).
+type PrimedTNTEntity struct {
+	EntityID   int32
+	X, Y, Z    float64
+	VX, VY, VZ float64
+	Fuse       int // Ticks remaining until the TNT explodes
+}
+
+// tntFuseTicks is the fuse length of TNT ignited by a player or fire (4 seconds).
+const tntFuseTicks = 80
+
+// PrimeTNT removes the TNT block at (x, y, z) and replaces it with a primed TNT
+// entity that explodes after fuse ticks.
+func (s *Server) PrimeTNT(x, y, z int32, fuse int) {
+	s.world.SetBlock(x, y, z, 0)
+	s.broadcastBlockChange(x, y, z, 0)
+
+	// Lit TNT hops up slightly in a random horizontal direction
+	angle := rand.Float64() * 2 * math.Pi
+
+	s.mu.Lock()
+	eid := s.nextEID
+	s.nextEID++
+
+	tnt := &PrimedTNTEntity{
+		EntityID: eid,
+		X:        float64(x) + 0.5,
+		Y:        float64(y),
+		Z:        float64(z) + 0.5,
+		VX:       -math.Sin(angle) * 0.02,
+		VY:       0.2,
+		VZ:       -math.Cos(angle) * 0.02,
+		Fuse:     fuse,
+	}
+	s.primedTNT[eid] = tnt
+	s.mu.Unlock()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.players {
+		if s.shouldTrack(p, tnt.X, tnt.Y, tnt.Z) {
+			s.sendPrimedTNTToPlayer(p, tnt)
+			p.mu.Lock()
+			s.trackEntity(p, tnt.EntityID)
+			p.mu.Unlock()
+		}
+	}
+	log.Printf("TNT primed at (%d, %d, %d) with a fuse of %d ticks", x, y, z, fuse)
+}
+
+func (s *Server) sendPrimedTNTToPlayer(player *Player, tnt *PrimedTNTEntity) {
+	// Spawn Object - 0x0E (Primed TNT)
+	spawnObj := protocol.MarshalPacket(0x0E, func(w *bytes.Buffer) {
+		protocol.WriteVarInt(w, tnt.EntityID)
+		protocol.WriteByte(w, 50) // Type: Primed TNT
+		protocol.WriteInt32(w, int32(tnt.X*32))
+		protocol.WriteInt32(w, int32(tnt.Y*32))
+		protocol.WriteInt32(w, int32(tnt.Z*32))
+		protocol.WriteByte(w, 0) // Pitch
+		protocol.WriteByte(w, 0) // Yaw
+		protocol.WriteInt32(w, 0)
+	})
+
+	player.mu.Lock()
+	if player.Conn != nil {
+		protocol.WritePacket(player.Conn, spawnObj)
+	}
+	player.mu.Unlock()
+}
+
+func (s *Server) spawnPrimedTNTForPlayer(player *Player) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, tnt := range s.primedTNT {
+		if s.shouldTrack(player, tnt.X, tnt.Y, tnt.Z) {
+			s.sendPrimedTNTToPlayer(player, tnt)
+			player.mu.Lock()
+			s.trackEntity(player, tnt.EntityID)
+			player.mu.Unlock()
+		}
+	}
+}
+
+// tickPrimedTNT advances all primed TNT by one tick.
+// Must be called with s.mu held. Returns the TNT that moved and the TNT whose fuse ran out.
+func (s *Server) tickPrimedTNT() (moved []*PrimedTNTEntity, exploded []*PrimedTNTEntity) {
+	const gravity = 0.04
+	const drag = 0.98
+	const size = 0.98
+
+	for eid, tnt := range s.primedTNT {
+		tnt.VY -= gravity
+
+		if !s.checkEntityCollision(tnt.X+tnt.VX, tnt.Y, tnt.Z, size, size) {
+			tnt.X += tnt.VX
+		} else {
+			tnt.VX = 0
+		}
+
+		onGround := false
+		if !s.checkEntityCollision(tnt.X, tnt.Y+tnt.VY, tnt.Z, size, size) {
+			tnt.Y += tnt.VY
+		} else {
+			if tnt.VY < 0 {
+				onGround = true
+			}
+			tnt.VY = 0
+		}
+
+		if !s.checkEntityCollision(tnt.X, tnt.Y, tnt.Z+tnt.VZ, size, size) {
+			tnt.Z += tnt.VZ
+		} else {
+			tnt.VZ = 0
+		}
+
+		tnt.VX *= drag
+		tnt.VY *= drag
+		tnt.VZ *= drag
+		if onGround {
+			tnt.VX *= 0.7
+			tnt.VZ *= 0.7
+		}
+
+		tnt.Fuse--
+		if tnt.Fuse <= 0 || tnt.Y < -64 {
+			delete(s.primedTNT, eid)
+			exploded = append(exploded, tnt)
+			continue
+		}
+		moved = append(moved, tnt)
+	}
+	return moved, exploded
+}
+
+// detonateTNT removes a primed TNT entity whose fuse ran out and explodes it.
+// Must be called without s.mu held.
+func (s *Server) detonateTNT(tnt *PrimedTNTEntity) {
+	s.broadcastDestroyEntity(tnt.EntityID)
+	if tnt.Y < 0 {
+		return
+	}
+	s.Explode(tnt.X, tnt.Y+0.49, tnt.Z, TNTExplosionPower, true, "blew up")
+}
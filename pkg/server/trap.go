@@ -0,0 +1,127 @@
+package server
+
+import (
+	"math"
+	"time"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// Generated traps go off when a player steps onto one of their pressure
+// plates or tripwires. A trigger can only go off once per trapCooldown, and
+// each trap dispenser holds trapArrowsPerDispenser arrows. There are no
+// arrow entities, so a dispenser's arrow hits the player who set it off.
+const (
+	trapCooldown           = time.Second
+	trapArrowsPerDispenser = 6
+	trapArrowDamage        = 3
+)
+
+// isTrapTrigger returns true for the blocks generated traps are set off by:
+// pressure plates, tripwire hooks and tripwire.
+func isTrapTrigger(blockID uint16) bool {
+	return blockID == 70 || blockID == 72 || blockID == 131 || blockID == 132
+}
+
+// checkTraps sets off the generated trap whose trigger the player is standing in.
+func (s *Server) checkTraps(player *Player) {
+	player.mu.Lock()
+	x := int32(math.Floor(player.X))
+	y := int32(math.Floor(player.Y))
+	z := int32(math.Floor(player.Z))
+	ignored := player.IsDead || player.GameMode == GameModeSpectator
+	player.mu.Unlock()
+	if ignored || !isTrapTrigger(s.world.GetBlock(x, y, z)>>4) {
+		return
+	}
+
+	pos := world.BlockPos{X: x, Y: y, Z: z}
+	trap, ok := s.trapAt(pos)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	s.mu.Lock()
+	if now.Before(s.trapCooldowns[pos]) {
+		s.mu.Unlock()
+		return
+	}
+	s.trapCooldowns[pos] = now.Add(trapCooldown)
+	s.mu.Unlock()
+
+	switch trap.Kind {
+	case world.TrapTNT:
+		for _, t := range trap.Targets {
+			if s.world.GetBlock(t.X, t.Y, t.Z)>>4 == 46 {
+				s.PrimeTNT(t.X, t.Y, t.Z, tntFuseTicks)
+			}
+		}
+	case world.TrapArrows:
+		for _, t := range trap.Targets {
+			s.fireTrapDispenser(player, t)
+		}
+	}
+}
+
+// trapAt returns the generated trap with a trigger at pos. A trap is listed by
+// the chunk of its first trigger, so the loaded chunks around pos are searched.
+func (s *Server) trapAt(pos world.BlockPos) (world.Trap, bool) {
+	pcx, pcz := pos.X>>4, pos.Z>>4
+	for cx := pcx - 1; cx <= pcx+1; cx++ {
+		for cz := pcz - 1; cz <= pcz+1; cz++ {
+			if !s.world.IsChunkLoaded(cx, cz) {
+				continue
+			}
+			for _, trap := range s.world.Features(cx, cz).Traps {
+				for _, trigger := range trap.Triggers {
+					if trigger == pos {
+						return trap, true
+					}
+				}
+			}
+		}
+	}
+	return world.Trap{}, false
+}
+
+// fireTrapDispenser shoots an arrow from the trap dispenser at pos at the
+// player, or clicks if it is out of arrows or has been broken.
+func (s *Server) fireTrapDispenser(player *Player, pos world.BlockPos) {
+	state := s.world.GetBlock(pos.X, pos.Y, pos.Z)
+	if state>>4 != 23 {
+		return
+	}
+	s.mu.Lock()
+	arrows, ok := s.trapArrows[pos]
+	if !ok {
+		arrows = trapArrowsPerDispenser
+	}
+	if arrows > 0 {
+		s.trapArrows[pos] = arrows - 1
+	}
+	s.mu.Unlock()
+
+	if arrows == 0 {
+		s.broadcastEffect(1001, pos.X, pos.Y, pos.Z, 0) // Dispenser click
+		return
+	}
+	s.broadcastEffect(1002, pos.X, pos.Y, pos.Z, 0) // Dispenser shoot
+	s.broadcastEffect(2000, pos.X, pos.Y, pos.Z, dispenserSmokeDirection(state&7))
+	s.applyDamage(player, trapArrowDamage, "was shot by an arrow")
+}
+
+// dispenserSmokeDirection returns the Effect 2000 data for smoke coming out
+// of a dispenser with the given facing metadata.
+func dispenserSmokeDirection(facing uint16) int32 {
+	switch facing {
+	case 2: // North
+		return 1
+	case 3: // South
+		return 7
+	case 4: // West
+		return 3
+	case 5: // East
+		return 5
+	}
+	return 4
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// findTrap returns the first trap of the nearest structure of the given kind
+// in the world of a server created with seed 12345.
+func findTrap(t *testing.T, s *Server, kind world.TempleKind) world.Trap {
+	t.Helper()
+	p, ok := world.NewGenerator(12345).FindTemple(kind, 0, 0, 12)
+	if !ok {
+		t.Fatalf("no %v within 12 cells", kind)
+	}
+	for cx := int32(p.X >> 4); cx <= int32((p.X+p.Width-1)>>4); cx++ {
+		for cz := int32(p.Z >> 4); cz <= int32((p.Z+p.Depth-1)>>4); cz++ {
+			if traps := s.world.Features(cx, cz).Traps; len(traps) > 0 {
+				return traps[0]
+			}
+		}
+	}
+	t.Fatalf("%v has no trap", kind)
+	return world.Trap{}
+}
+
+func newTrapTestServer(t *testing.T) *Server {
+	t.Helper()
+	config := DefaultConfig()
+	config.DataDir = t.TempDir()
+	config.Seed = 12345
+	return New(config)
+}
+
+// standOn moves the player into the trigger block at pos.
+func standOn(p *Player, pos world.BlockPos) {
+	p.mu.Lock()
+	p.X, p.Y, p.Z = float64(pos.X)+0.5, float64(pos.Y), float64(pos.Z)+0.5
+	p.mu.Unlock()
+}
+
+func TestTNTTrap(t *testing.T) {
+	s := newTrapTestServer(t)
+	p := addCommandTestPlayer(t, s, 1, "Raider")
+	trap := findTrap(t, s, world.DesertPyramid)
+	if trap.Kind != world.TrapTNT {
+		t.Fatalf("pyramid trap kind = %v, want TrapTNT", trap.Kind)
+	}
+
+	standOn(p, trap.Triggers[0])
+	s.checkTraps(p)
+
+	s.mu.RLock()
+	primed := len(s.primedTNT)
+	s.mu.RUnlock()
+	if primed != len(trap.Targets) {
+		t.Errorf("%d TNT primed, want %d", primed, len(trap.Targets))
+	}
+	for _, pos := range trap.Targets {
+		if b := s.world.GetBlock(pos.X, pos.Y, pos.Z) >> 4; b == 46 {
+			t.Errorf("TNT still at %v", pos)
+		}
+	}
+}
+
+func TestArrowTrap(t *testing.T) {
+	s := newTrapTestServer(t)
+	p := addCommandTestPlayer(t, s, 1, "Raider")
+	p.Health = 20
+	trap := findTrap(t, s, world.WitchHut)
+	if trap.Kind != world.TrapArrows {
+		t.Fatalf("witch hut trap kind = %v, want TrapArrows", trap.Kind)
+	}
+	trigger := trap.Triggers[0]
+	standOn(p, trigger)
+
+	s.checkTraps(p)
+	if p.Health != 20-trapArrowDamage {
+		t.Fatalf("health after trap = %v, want %v", p.Health, 20-trapArrowDamage)
+	}
+
+	// Still on the plate: the trap is cooling down
+	s.checkTraps(p)
+	if p.Health != 20-trapArrowDamage {
+		t.Errorf("trap fired again during its cooldown")
+	}
+
+	// The dispenser runs out of arrows
+	for i := 0; i < trapArrowsPerDispenser+2; i++ {
+		s.mu.Lock()
+		delete(s.trapCooldowns, trigger)
+		s.mu.Unlock()
+		p.Health = 20
+		s.checkTraps(p)
+	}
+	if p.Health != 20 {
+		t.Errorf("empty dispenser still hurt the player (health %v)", p.Health)
+	}
+
+	// Spectators don't set traps off
+	s.mu.Lock()
+	delete(s.trapCooldowns, trigger)
+	delete(s.trapArrows, trap.Targets[0])
+	s.mu.Unlock()
+	p.GameMode = GameModeSpectator
+	s.checkTraps(p)
+	if p.Health != 20 {
+		t.Error("spectator set off the trap")
+	}
+}
+
+func TestWitchHutSpawnsWitch(t *testing.T) {
+	s := newTrapTestServer(t)
+	trap := findTrap(t, s, world.WitchHut)
+	cx, cz := trap.Triggers[0].X>>4, trap.Triggers[0].Z>>4
+	for i := 0; i < 2; i++ {
+		for x := cx - 1; x <= cx+1; x++ {
+			for z := cz - 1; z <= cz+1; z++ {
+				s.spawnStructureMobs(x, z)
+			}
+		}
+	}
+	witches := 0
+	s.mu.RLock()
+	for _, mob := range s.mobEntities {
+		if mob.MobType == 66 {
+			witches++
+		}
+	}
+	s.mu.RUnlock()
+	if witches != 1 {
+		t.Errorf("%d witches spawned, want 1", witches)
+	}
+}
@@ -0,0 +1,498 @@
+package server
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/protocol"
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// MobTypeVillager is the Spawn Mob type ID of villagers.
+const MobTypeVillager byte = 120
+
+// merchantWindowID is the window ID used for villager trade windows.
+const merchantWindowID = 3
+
+// itemEmerald is the currency villagers trade in.
+const itemEmerald int16 = 388
+
+// Villager behaviour. Villagers stroll to a random spot up to
+// villagerWanderRange blocks away inside their village, then idle for a
+// while. As in vanilla, a new offer starts with villagerTradeUses uses and
+// trading restocks the villager villagerRestockTicks later.
+const (
+	villagerWalkSpeed    = 0.1
+	villagerWanderRange  = 8
+	villagerMinIdle      = 60
+	villagerMaxIdle      = 200
+	villagerGiveUpTicks  = 100 // Ticks before a villager stops walking to a spot it cannot reach
+	villagerTradeUses    = 7
+	villagerRestockTicks = 40
+)
+
+// Trade is one offer of a villager: Buy1 (and Buy2, if its ItemID is not -1)
+// for Sell. An offer is disabled once it has been used MaxUses times.
+type Trade struct {
+	Buy1, Buy2, Sell Slot
+	Uses, MaxUses    int
+}
+
+// Disabled returns true if the offer has been used up.
+func (t *Trade) Disabled() bool {
+	return t.Uses >= t.MaxUses
+}
+
+// Villager is the trading and wandering state of a villager mob.
+type Villager struct {
+	Profession int    // One of the world.Profession constants
+	Career     string // Career name, shown as the trade window title
+
+	mu      sync.Mutex
+	Trades  []Trade
+	tiers   [][]tradeOffer // Offers of the villager's career, by tier
+	tier    int            // Tiers unlocked so far
+	restock int            // Ticks until the villager restocks, 0 if not due
+	rand    *rand.Rand
+
+	MinX, MinZ, MaxX, MaxZ float64 // Village bounds it wanders within
+
+	walking   bool
+	targetX   float64
+	targetZ   float64
+	walkTicks int
+	idleTicks int
+}
+
+// tradeOffer builds one trade of a tier from the villager's random source.
+type tradeOffer func(r *rand.Rand) Trade
+
+// between returns a random count in [min, max].
+func between(r *rand.Rand, min, max int) byte {
+	return byte(min + r.Intn(max-min+1))
+}
+
+// wants buys min-max of an item for one emerald.
+func wants(itemID int16, min, max int) tradeOffer {
+	return func(r *rand.Rand) Trade {
+		return Trade{
+			Buy1: Slot{ItemID: itemID, Count: between(r, min, max)},
+			Buy2: Slot{ItemID: -1},
+			Sell: Slot{ItemID: itemEmerald, Count: 1},
+		}
+	}
+}
+
+// sells sells one of an item for min-max emeralds.
+func sells(itemID, damage int16, min, max int) tradeOffer {
+	return func(r *rand.Rand) Trade {
+		return Trade{
+			Buy1: Slot{ItemID: itemEmerald, Count: between(r, min, max)},
+			Buy2: Slot{ItemID: -1},
+			Sell: Slot{ItemID: itemID, Damage: damage, Count: 1},
+		}
+	}
+}
+
+// sellsStack sells min-max of an item for one emerald.
+func sellsStack(itemID, damage int16, min, max int) tradeOffer {
+	return func(r *rand.Rand) Trade {
+		return Trade{
+			Buy1: Slot{ItemID: itemEmerald, Count: 1},
+			Buy2: Slot{ItemID: -1},
+			Sell: Slot{ItemID: itemID, Damage: damage, Count: between(r, min, max)},
+		}
+	}
+}
+
+// processes turns count of an item and an emerald into min-max of another.
+func processes(buyID int16, count byte, sellID int16, min, max int) tradeOffer {
+	return func(r *rand.Rand) Trade {
+		return Trade{
+			Buy1: Slot{ItemID: buyID, Count: count},
+			Buy2: Slot{ItemID: itemEmerald, Count: 1},
+			Sell: Slot{ItemID: sellID, Count: between(r, min, max)},
+		}
+	}
+}
+
+// villagerCareer is a villager career and the offers it unlocks, tier by tier.
+type villagerCareer struct {
+	name  string
+	tiers [][]tradeOffer
+}
+
+// woolColours offers wool of every colour, as shepherds do.
+func woolColours() []tradeOffer {
+	offers := make([]tradeOffer, 16)
+	for i := range offers {
+		offers[i] = sells(35, int16(i), 1, 2)
+	}
+	return offers
+}
+
+// villagerCareers lists the careers of each profession with the vanilla 1.8
+// trades. Enchanted items are sold without enchantments.
+var villagerCareers = map[int][]villagerCareer{
+	world.ProfessionFarmer: {
+		{"Farmer", [][]tradeOffer{
+			{wants(296, 18, 22), wants(392, 15, 19), wants(391, 15, 19), sellsStack(297, 0, 2, 4)},
+			{wants(86, 8, 13), sellsStack(400, 0, 2, 3)},
+			{wants(103, 7, 12), sellsStack(260, 0, 5, 7)},
+			{sellsStack(357, 0, 6, 10), sells(354, 0, 1, 1)},
+		}},
+		{"Fisherman", [][]tradeOffer{
+			{wants(287, 15, 20), wants(263, 16, 24), processes(349, 6, 350, 6, 6)},
+			{sells(346, 0, 7, 8)},
+		}},
+		{"Shepherd", [][]tradeOffer{
+			{wants(35, 16, 22), sells(359, 0, 3, 4)},
+			woolColours(),
+		}},
+		{"Fletcher", [][]tradeOffer{
+			{wants(287, 15, 20), sellsStack(262, 0, 8, 12)},
+			{sells(261, 0, 2, 3), processes(13, 10, 318, 6, 10)},
+		}},
+	},
+	world.ProfessionLibrarian: {
+		{"Librarian", [][]tradeOffer{
+			{wants(339, 24, 36), sells(403, 0, 5, 64)},
+			{wants(340, 8, 10), sells(345, 0, 10, 12), sells(47, 0, 3, 4)},
+			{wants(387, 2, 2), sells(347, 0, 10, 12), sellsStack(20, 0, 3, 5)},
+			{sells(403, 0, 5, 64)},
+			{sells(421, 0, 20, 22)},
+		}},
+	},
+	world.ProfessionPriest: {
+		{"Cleric", [][]tradeOffer{
+			{wants(367, 36, 40), wants(266, 8, 10)},
+			{sellsStack(331, 0, 1, 4), sellsStack(351, 4, 1, 2)},
+			{sells(381, 0, 7, 11), sellsStack(348, 0, 1, 3)},
+			{sells(384, 0, 3, 11)},
+		}},
+	},
+	world.ProfessionBlacksmith: {
+		{"Armorer", [][]tradeOffer{
+			{wants(263, 16, 24), sells(306, 0, 4, 6)},
+			{wants(265, 7, 9), sells(307, 0, 10, 14)},
+			{wants(264, 3, 4), sells(311, 0, 16, 19)},
+			{sells(305, 0, 5, 7), sells(304, 0, 9, 11), sells(302, 0, 5, 7), sells(303, 0, 11, 15)},
+		}},
+		{"Weapon Smith", [][]tradeOffer{
+			{wants(263, 16, 24), sells(258, 0, 6, 8)},
+			{wants(265, 7, 9), sells(267, 0, 9, 10)},
+			{wants(264, 3, 4), sells(276, 0, 12, 15), sells(279, 0, 9, 12)},
+		}},
+		{"Tool Smith", [][]tradeOffer{
+			{wants(263, 16, 24), sells(256, 0, 5, 7)},
+			{wants(265, 7, 9), sells(257, 0, 9, 11)},
+			{wants(264, 3, 4), sells(278, 0, 12, 15)},
+		}},
+	},
+	world.ProfessionButcher: {
+		{"Butcher", [][]tradeOffer{
+			{wants(319, 14, 18), wants(365, 14, 18)},
+			{wants(263, 16, 24), sellsStack(320, 0, 5, 7), sellsStack(366, 0, 6, 8)},
+		}},
+		{"Leatherworker", [][]tradeOffer{
+			{wants(334, 9, 12), sells(300, 0, 2, 4)},
+			{sells(299, 0, 7, 12)},
+			{sells(329, 0, 8, 10)},
+		}},
+	},
+}
+
+// newVillager creates the villager spawned for a generated one. Its career
+// and first trades are picked from the generated villager's seed.
+func newVillager(wv world.Villager) *Villager {
+	careers := villagerCareers[wv.Profession]
+	if len(careers) == 0 {
+		careers = villagerCareers[world.ProfessionFarmer]
+	}
+	r := rand.New(rand.NewSource(wv.Seed))
+	career := careers[r.Intn(len(careers))]
+	v := &Villager{
+		Profession: wv.Profession,
+		Career:     career.name,
+		tiers:      career.tiers,
+		rand:       r,
+		MinX:       float64(wv.MinX),
+		MinZ:       float64(wv.MinZ),
+		MaxX:       float64(wv.MaxX) + 1,
+		MaxZ:       float64(wv.MaxZ) + 1,
+	}
+	v.addTier()
+	return v
+}
+
+// addTier unlocks the villager's next tier of trades, if it has one.
+// Must be called with v.mu held, or before the villager is shared.
+func (v *Villager) addTier() {
+	if v.tier >= len(v.tiers) {
+		return
+	}
+	for _, offer := range v.tiers[v.tier] {
+		t := offer(v.rand)
+		t.MaxUses = villagerTradeUses
+		v.Trades = append(v.Trades, t)
+	}
+	v.tier++
+}
+
+// useTrade records that trade i was made. The first use of an offer, and
+// one in five others, makes the villager restock shortly afterwards.
+// Must be called with v.mu held.
+func (v *Villager) useTrade(i int) {
+	v.Trades[i].Uses++
+	if v.Trades[i].Uses == 1 || v.rand.Intn(5) == 0 {
+		v.restock = villagerRestockTicks
+	}
+}
+
+// tickRestock counts down to a pending restock, which re-enables used up
+// offers and unlocks the next tier. Must be called with v.mu held.
+func (v *Villager) tickRestock() {
+	if v.restock == 0 {
+		return
+	}
+	v.restock--
+	if v.restock > 0 {
+		return
+	}
+	for i := range v.Trades {
+		if v.Trades[i].Disabled() {
+			v.Trades[i].MaxUses += 2 + v.rand.Intn(6) + v.rand.Intn(6)
+		}
+	}
+	v.addTier()
+}
+
+// matchTrade returns the offer that the items in the two input slots pay
+// for, preferring the selected one, or -1. Must be called with v.mu held.
+func (v *Villager) matchTrade(in1, in2 Slot, selected int) int {
+	pays := func(t *Trade) bool {
+		if t.Disabled() {
+			return false
+		}
+		if t.Buy2.ItemID == -1 {
+			return (covers(in1, t.Buy1) && in2.ItemID == -1) || (covers(in2, t.Buy1) && in1.ItemID == -1)
+		}
+		return covers(in1, t.Buy1) && covers(in2, t.Buy2)
+	}
+	if selected >= 0 && selected < len(v.Trades) && pays(&v.Trades[selected]) {
+		return selected
+	}
+	for i := range v.Trades {
+		if pays(&v.Trades[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// covers returns true if the stack in have pays for want.
+func covers(have, want Slot) bool {
+	return have.ItemID == want.ItemID && have.Damage == want.Damage && have.Count >= want.Count
+}
+
+// villagerAI is the AIFunc for villagers. They wander between random spots
+// inside their village, idling in between.
+// Called from tickEntityPhysics with s.mu held.
+func villagerAI(mob *MobEntity, s *Server) {
+	v := mob.Villager
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.tickRestock()
+
+	if !v.walking {
+		if v.idleTicks > 0 {
+			v.idleTicks--
+			return
+		}
+		v.targetX = clampFloat(mob.X+float64(v.rand.Intn(2*villagerWanderRange+1)-villagerWanderRange), v.MinX+0.5, v.MaxX-0.5)
+		v.targetZ = clampFloat(mob.Z+float64(v.rand.Intn(2*villagerWanderRange+1)-villagerWanderRange), v.MinZ+0.5, v.MaxZ-0.5)
+		v.walking = true
+		v.walkTicks = 0
+	}
+
+	dx, dz := v.targetX-mob.X, v.targetZ-mob.Z
+	dist := math.Sqrt(dx*dx + dz*dz)
+	v.walkTicks++
+	if dist < 0.5 || v.walkTicks > villagerGiveUpTicks {
+		v.walking = false
+		v.idleTicks = villagerMinIdle + v.rand.Intn(villagerMaxIdle-villagerMinIdle+1)
+		return
+	}
+	mob.Yaw = float32(math.Atan2(dz, dx)*180/math.Pi) - 90
+	if mob.OnGround {
+		mob.VX = dx / dist * villagerWalkSpeed
+		mob.VZ = dz / dist * villagerWalkSpeed
+	}
+}
+
+// clampFloat limits f to [min, max].
+func clampFloat(f, min, max float64) float64 {
+	return math.Max(min, math.Min(max, f))
+}
+
+// spawnChunkVillagers spawns the villagers of the village buildings in chunk
+// (cx, cz) the first time the chunk is sent.
+func (s *Server) spawnChunkVillagers(cx, cz int32) {
+	for _, wv := range s.world.Features(cx, cz).Villagers {
+		s.mu.Lock()
+		spawned := s.spawnedMobs[wv.Pos]
+		s.spawnedMobs[wv.Pos] = true
+		s.mu.Unlock()
+		if !spawned {
+			s.spawnVillager(wv)
+		}
+	}
+}
+
+// spawnVillager spawns a villager mob for a generated villager.
+func (s *Server) spawnVillager(wv world.Villager) *MobEntity {
+	mob := &MobEntity{
+		MobType:  MobTypeVillager,
+		X:        float64(wv.Pos.X) + 0.5,
+		Y:        float64(wv.Pos.Y),
+		Z:        float64(wv.Pos.Z) + 0.5,
+		Villager: newVillager(wv),
+		AIFunc:   villagerAI,
+	}
+	s.spawnMobEntity(mob)
+	return mob
+}
+
+// openMerchant opens the trade window of a villager.
+func (s *Server) openMerchant(player *Player, v *Villager) {
+	player.mu.Lock()
+	defer player.mu.Unlock()
+	player.OpenWindowID = merchantWindowID
+	player.OpenContainer = nil
+	player.OpenMerchant = v
+	player.MerchantTrade = 0
+	for i := range player.MerchantSlots {
+		player.MerchantSlots[i] = Slot{ItemID: -1}
+	}
+	if player.Conn == nil {
+		return
+	}
+	openPkt := protocol.MarshalPacket(0x2D, func(w *bytes.Buffer) {
+		protocol.WriteByte(w, merchantWindowID)
+		protocol.WriteString(w, "minecraft:villager")
+		protocol.WriteString(w, `{"text":"`+v.Career+`"}`)
+		protocol.WriteByte(w, 3)
+	})
+	protocol.WritePacket(player.Conn, openPkt)
+	protocol.WritePacket(player.Conn, windowItemsPacket(player))
+	protocol.WritePacket(player.Conn, merchantTradesPacket(v))
+}
+
+// merchantTradesPacket builds the MC|TrList plugin message listing a
+// villager's offers for its open trade window.
+func merchantTradesPacket(v *Villager) *protocol.Packet {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return protocol.MarshalPacket(0x3F, func(w *bytes.Buffer) {
+		protocol.WriteString(w, "MC|TrList")
+		protocol.WriteInt32(w, merchantWindowID)
+		protocol.WriteByte(w, byte(len(v.Trades)))
+		for _, t := range v.Trades {
+			protocol.WriteSlotData(w, t.Buy1.ItemID, t.Buy1.Count, t.Buy1.Damage)
+			protocol.WriteSlotData(w, t.Sell.ItemID, t.Sell.Count, t.Sell.Damage)
+			protocol.WriteBool(w, t.Buy2.ItemID != -1)
+			if t.Buy2.ItemID != -1 {
+				protocol.WriteSlotData(w, t.Buy2.ItemID, t.Buy2.Count, t.Buy2.Damage)
+			}
+			protocol.WriteBool(w, t.Disabled())
+			protocol.WriteInt32(w, int32(t.Uses))
+			protocol.WriteInt32(w, int32(t.MaxUses))
+		}
+	})
+}
+
+// handleSelectTrade processes the MC|TrSel plugin message, sent when the
+// player picks an offer in the trade window.
+func (s *Server) handleSelectTrade(player *Player, index int32) {
+	player.mu.Lock()
+	defer player.mu.Unlock()
+	if player.OpenMerchant == nil {
+		return
+	}
+	player.MerchantTrade = int(index)
+	updateMerchantOutput(player)
+	if player.Conn != nil {
+		protocol.WritePacket(player.Conn, windowItemsPacket(player))
+	}
+}
+
+// updateMerchantOutput shows what the items in the trade window's input
+// slots buy in its output slot. Must be called with player.mu held.
+func updateMerchantOutput(player *Player) {
+	v := player.OpenMerchant
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	player.MerchantSlots[2] = Slot{ItemID: -1}
+	if i := v.matchTrade(player.MerchantSlots[0], player.MerchantSlots[1], player.MerchantTrade); i >= 0 {
+		player.MerchantSlots[2] = v.Trades[i].Sell
+	}
+}
+
+// takeMerchantOutput makes the trade shown in the output slot of the trade
+// window, paying with the input slots. The bought items go to the cursor, or
+// with a shift-click (mode 1) straight to the inventory, trading as often as
+// the inputs allow. Returns whether any trade was made.
+// Must be called with player.mu held.
+func takeMerchantOutput(player *Player, mode byte) bool {
+	traded := false
+	for {
+		v := player.OpenMerchant
+		v.mu.Lock()
+		i := v.matchTrade(player.MerchantSlots[0], player.MerchantSlots[1], player.MerchantTrade)
+		if i < 0 {
+			v.mu.Unlock()
+			break
+		}
+		t := v.Trades[i]
+		switch {
+		case mode == 1:
+			if _, ok := addItemToInventory(player, t.Sell.ItemID, t.Sell.Damage, t.Sell.Count); !ok {
+				v.mu.Unlock()
+				return traded
+			}
+		case player.Cursor.ItemID == -1:
+			player.Cursor = t.Sell
+		case player.Cursor.ItemID == t.Sell.ItemID && player.Cursor.Damage == t.Sell.Damage && int(player.Cursor.Count)+int(t.Sell.Count) <= 64:
+			player.Cursor.Count += t.Sell.Count
+		default:
+			v.mu.Unlock()
+			return traded
+		}
+
+		if covers(player.MerchantSlots[0], t.Buy1) {
+			payMerchant(&player.MerchantSlots[0], t.Buy1.Count)
+			if t.Buy2.ItemID != -1 {
+				payMerchant(&player.MerchantSlots[1], t.Buy2.Count)
+			}
+		} else {
+			payMerchant(&player.MerchantSlots[1], t.Buy1.Count)
+		}
+		v.useTrade(i)
+		v.mu.Unlock()
+		traded = true
+		if mode != 1 {
+			break
+		}
+	}
+	return traded
+}
+
+// payMerchant takes count items from an input slot of the trade window.
+func payMerchant(sl *Slot, count byte) {
+	sl.Count -= count
+	if sl.Count == 0 {
+		*sl = Slot{ItemID: -1}
+	}
+}
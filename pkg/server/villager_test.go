@@ -0,0 +1,132 @@
+package server
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+func TestNewVillagerTrades(t *testing.T) {
+	for _, profession := range []int{world.ProfessionFarmer, world.ProfessionLibrarian, world.ProfessionPriest, world.ProfessionBlacksmith, world.ProfessionButcher} {
+		for seed := int64(0); seed < 20; seed++ {
+			v := newVillager(world.Villager{Profession: profession, Seed: seed})
+			if len(v.Trades) == 0 {
+				t.Fatalf("profession %d seed %d: no trades", profession, seed)
+			}
+			for _, tr := range v.Trades {
+				if tr.Buy1.ItemID != itemEmerald && tr.Buy2.ItemID != itemEmerald && tr.Sell.ItemID != itemEmerald {
+					t.Errorf("%s trade %+v does not involve emeralds", v.Career, tr)
+				}
+				if tr.Buy1.Count == 0 || tr.Sell.Count == 0 || tr.MaxUses != villagerTradeUses {
+					t.Errorf("%s trade %+v malformed", v.Career, tr)
+				}
+			}
+			again := newVillager(world.Villager{Profession: profession, Seed: seed})
+			if again.Career != v.Career || again.Trades[0] != v.Trades[0] {
+				t.Errorf("profession %d seed %d: villager not deterministic", profession, seed)
+			}
+		}
+	}
+}
+
+func TestVillagerSpawnsWithProfession(t *testing.T) {
+	s := newCommandTestServer(t)
+	mob := s.spawnVillager(world.Villager{Pos: world.BlockPos{X: 4, Y: 70, Z: 4}, Profession: world.ProfessionLibrarian})
+	if mob.MobType != MobTypeVillager || mob.Villager == nil || mob.Villager.Career != "Librarian" {
+		t.Fatalf("spawned %+v, want a librarian villager", mob)
+	}
+	s.mu.RLock()
+	_, ok := s.mobEntities[mob.EntityID]
+	s.mu.RUnlock()
+	if !ok {
+		t.Error("villager not added to the world")
+	}
+}
+
+func TestMerchantTrade(t *testing.T) {
+	s := newCommandTestServer(t)
+	p := addContainerTestPlayer(t, s, 1, "Looter")
+	mob := s.spawnVillager(world.Villager{Pos: world.BlockPos{X: 4, Y: 70, Z: 4}})
+	v := mob.Villager
+	v.Trades = []Trade{{
+		Buy1:    Slot{ItemID: 296, Count: 20}, // Wheat
+		Buy2:    Slot{ItemID: -1},
+		Sell:    Slot{ItemID: itemEmerald, Count: 1},
+		MaxUses: 1,
+	}}
+
+	s.handleInteractEntity(p, mob.EntityID)
+	if p.OpenWindowID != merchantWindowID || p.OpenMerchant != v {
+		t.Fatal("trade window not opened")
+	}
+
+	// Put 30 wheat into the first input
+	p.Cursor = Slot{ItemID: 296, Count: 30}
+	s.handleWindowClick(p, merchantWindowID, 0, 0, 1, 0)
+	if p.MerchantSlots[2].ItemID != itemEmerald {
+		t.Fatalf("output = %+v, want an emerald", p.MerchantSlots[2])
+	}
+
+	// Taking the output makes the trade
+	s.handleWindowClick(p, merchantWindowID, 2, 0, 2, 0)
+	if p.Cursor.ItemID != itemEmerald || p.Cursor.Count != 1 {
+		t.Errorf("cursor = %+v, want an emerald", p.Cursor)
+	}
+	if p.MerchantSlots[0].Count != 10 {
+		t.Errorf("%d wheat left, want 10", p.MerchantSlots[0].Count)
+	}
+	if v.Trades[0].Uses != 1 || !v.Trades[0].Disabled() {
+		t.Errorf("trade used %d/%d times", v.Trades[0].Uses, v.Trades[0].MaxUses)
+	}
+	if p.MerchantSlots[2].ItemID != -1 {
+		t.Errorf("used up trade still offers %+v", p.MerchantSlots[2])
+	}
+
+	// The output slot cannot be filled by the player
+	s.handleWindowClick(p, merchantWindowID, 2, 0, 3, 0)
+	if p.MerchantSlots[2].ItemID != -1 {
+		t.Errorf("output slot = %+v after placing into it", p.MerchantSlots[2])
+	}
+
+	// Restocking re-enables the trade and unlocks the next tier
+	for i := 0; i < villagerRestockTicks; i++ {
+		v.tickRestock()
+	}
+	if v.Trades[0].Disabled() {
+		t.Error("trade still disabled after restocking")
+	}
+	if len(v.Trades) == 1 {
+		t.Error("no new trades unlocked")
+	}
+
+	// Closing the window gives back the unspent wheat
+	s.handleCloseWindow(p, bytes.NewReader([]byte{merchantWindowID}))
+	if p.OpenMerchant != nil || p.OpenWindowID != 0 {
+		t.Error("trade window still open")
+	}
+	wheat := 0
+	for _, sl := range p.Inventory {
+		if sl.ItemID == 296 {
+			wheat += int(sl.Count)
+		}
+	}
+	if wheat != 10 {
+		t.Errorf("%d wheat returned, want 10", wheat)
+	}
+}
+
+func TestVillagerWandersInsideVillage(t *testing.T) {
+	s := newCommandTestServer(t)
+	v := newVillager(world.Villager{Seed: 7, MinX: 0, MinZ: 0, MaxX: 15, MaxZ: 9})
+	mob := &MobEntity{X: 12.5, Z: 2.5, OnGround: true, Villager: v}
+	for tick := 0; tick < 5000; tick++ {
+		villagerAI(mob, s)
+		mob.X += mob.VX
+		mob.Z += mob.VZ
+		mob.VX, mob.VZ = 0, 0
+		if mob.X < 0 || mob.X > 16 || mob.Z < 0 || mob.Z > 10 {
+			t.Fatalf("tick %d: villager at (%.1f, %.1f) left the village", tick, mob.X, mob.Z)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/VibeShit/VibeShitCraft/pkg/world"
+)
+
+// registerWorldInfoCommands registers /seed and /biome.
+func (s *Server) registerWorldInfoCommands() {
+	s.RegisterCommand(&Command{
+		Name:        "seed",
+		Description: "Shows the world seed",
+		Permission:  PermissionGameMode,
+		Syntaxes:    []CommandSyntax{{Run: s.runSeedCommand}},
+	})
+	s.RegisterCommand(&Command{
+		Name:        "biome",
+		Description: "Shows the biome you are standing in",
+		Permission:  PermissionGameMode,
+		Syntaxes:    []CommandSyntax{{Run: s.runBiomeCommand}},
+	})
+}
+
+// runSeedCommand handles /seed.
+func (s *Server) runSeedCommand(ctx *CommandContext) {
+	ctx.Info(fmt.Sprintf("Seed: %d", s.seed))
+}
+
+// runBiomeCommand handles /biome, naming the biome of the sender's column.
+func (s *Server) runBiomeCommand(ctx *CommandContext) {
+	if ctx.Sender == nil {
+		ctx.Error("This command can only be run by a player")
+		return
+	}
+	ctx.Sender.mu.Lock()
+	x, z := int(math.Floor(ctx.Sender.X)), int(math.Floor(ctx.Sender.Z))
+	ctx.Sender.mu.Unlock()
+
+	var biome *world.Biome
+	switch gen := s.world.Gen.(type) {
+	case *world.Generator:
+		biome = gen.Biome(x, z)
+	case *world.FlatGenerator:
+		if biome = world.BiomeByID(gen.Biome); biome == nil {
+			ctx.Info(fmt.Sprintf("Biome ID %d at %d, %d", gen.Biome, x, z))
+			return
+		}
+	default:
+		ctx.Error("This world has no biomes")
+		return
+	}
+	ctx.Info(fmt.Sprintf("%s (ID %d) at %d, %d", biome.Name, biome.ID, x, z))
+}
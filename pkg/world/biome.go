@@ -5,11 +5,17 @@ type Biome struct {
 	ID              byte // Minecraft biome ID
 	Name            string
 	SurfaceBlock    uint16  // block state (blockID << 4 | meta)
-	FillerBlock     uint16  // block below surface
+	FillerBlock     uint16  // blocks just below the surface, above stone
+	StrataBlock     uint16  // layer between filler and stone, e.g. sandstone under sand (0 = none)
 	BaseHeight      int     // base terrain height in blocks
 	HeightVariation float64 // amplitude of height noise
 	TreeDensity     float64 // 0.0 = none, higher = more trees
+	Tree            TreeType
+	AltTree         TreeType // grown instead of Tree with AltTreeChance
+	AltTreeChance   float64
 	BoulderDensity  float64 // 0.0 = none, chance per column
+	LilyPadDensity  float64 // chance per water column of a lily pad
+	ClayBands       bool    // filler and strata are bands of stained clay
 	HasSnow         bool
 	// Village styling
 	VillageLog    uint16
@@ -22,12 +28,28 @@ type Biome struct {
 	VillageDeco2  uint16
 }
 
+// TreeType selects the tree builder used for a biome's trees.
+type TreeType int
+
+const (
+	TreeOak TreeType = iota
+	TreeSpruce
+	TreeBirch
+	TreeJungle
+	TreeDarkOak
+	TreeCactus // cactus or dead bush
+	TreeAcacia
+	TreeSwampOak     // oak with vines
+	TreeHugeMushroom // brown or red huge mushroom
+)
+
 // Predefined biomes
 var (
 	BiomeOcean = &Biome{
 		ID: 0, Name: "Ocean",
 		SurfaceBlock: 12 << 4, // sand
 		FillerBlock:  12 << 4, // sand
+		StrataBlock:  24 << 4, // sandstone
 		BaseHeight:   38, HeightVariation: 8,
 		TreeDensity: 0,
 	}
@@ -50,9 +72,11 @@ var (
 	BiomeDesert = &Biome{
 		ID: 2, Name: "Desert",
 		SurfaceBlock: 12 << 4, // sand
-		FillerBlock:  24 << 4, // sandstone
+		FillerBlock:  12 << 4, // sand
+		StrataBlock:  24 << 4, // sandstone
 		BaseHeight:   64, HeightVariation: 10,
 		TreeDensity:    0.02,
+		Tree:           TreeCactus,
 		BoulderDensity: 0.02,      // desert rocks
 		VillageLog:     24<<4 | 2, // smooth sandstone (farm borders/accents)
 		VillagePlanks:  24 << 4,   // sandstone
@@ -69,6 +93,7 @@ var (
 		FillerBlock:  1 << 4, // stone
 		BaseHeight:   72, HeightVariation: 50,
 		TreeDensity:    0.03,
+		Tree:           TreeSpruce,
 		BoulderDensity: 0.08,
 		VillageLog:     17<<4 | 1,  // spruce log
 		VillagePlanks:  5<<4 | 1,   // spruce planks
@@ -85,6 +110,8 @@ var (
 		FillerBlock:  3 << 4, // dirt
 		BaseHeight:   68, HeightVariation: 14,
 		TreeDensity:    0.065,
+		AltTree:        TreeBirch,
+		AltTreeChance:  0.3,
 		BoulderDensity: 0.04,
 		VillageLog:     17 << 4,  // oak log
 		VillagePlanks:  5 << 4,   // oak planks
@@ -101,6 +128,7 @@ var (
 		FillerBlock:  3 << 4, // dirt
 		BaseHeight:   70, HeightVariation: 20,
 		TreeDensity:    0.12,
+		Tree:           TreeJungle,
 		BoulderDensity: 0.02,
 		VillageLog:     17<<4 | 3,  // jungle log
 		VillagePlanks:  5<<4 | 3,   // jungle planks
@@ -117,6 +145,7 @@ var (
 		FillerBlock:  3 << 4, // dirt
 		BaseHeight:   68, HeightVariation: 10,
 		TreeDensity:    0.25,
+		Tree:           TreeDarkOak,
 		BoulderDensity: 0.02,
 		VillageLog:     162<<4 | 1, // dark oak log
 		VillagePlanks:  5<<4 | 5,   // dark oak planks
@@ -133,6 +162,7 @@ var (
 		FillerBlock:  3 << 4,  // dirt
 		BaseHeight:   66, HeightVariation: 8,
 		TreeDensity:    0.01,
+		Tree:           TreeSpruce,
 		BoulderDensity: 0.02,
 		HasSnow:        true,
 		VillageLog:     17<<4 | 1,  // spruce log
@@ -141,6 +171,148 @@ var (
 		VillageSlab:    126<<4 | 1, // spruce slab
 		VillageStairs:  134 << 4,   // spruce stairs
 	}
+	BiomeTaiga = &Biome{
+		ID: 5, Name: "Taiga",
+		SurfaceBlock: 2 << 4, // grass
+		FillerBlock:  3 << 4, // dirt
+		BaseHeight:   68, HeightVariation: 14,
+		TreeDensity:    0.08,
+		Tree:           TreeSpruce,
+		BoulderDensity: 0.04,
+		VillageLog:     17<<4 | 1,  // spruce log
+		VillagePlanks:  5<<4 | 1,   // spruce planks
+		VillagePath:    13 << 4,    // gravel
+		VillageSlab:    126<<4 | 1, // spruce slab
+		VillageStairs:  134 << 4,   // spruce stairs
+		VillageFence:   188 << 4,   // spruce fence
+		VillageDeco1:   39 << 4,    // brown mushroom
+		VillageDeco2:   31<<4 | 2,  // fern
+	}
+	BiomeSavanna = &Biome{
+		ID: 35, Name: "Savanna",
+		SurfaceBlock: 2 << 4, // grass
+		FillerBlock:  3 << 4, // dirt
+		BaseHeight:   68, HeightVariation: 8,
+		TreeDensity:    0.012,
+		Tree:           TreeAcacia,
+		BoulderDensity: 0.01,
+		VillageLog:     162 << 4,   // acacia log
+		VillagePlanks:  5<<4 | 4,   // acacia planks
+		VillagePath:    13 << 4,    // gravel
+		VillageSlab:    126<<4 | 4, // acacia slab
+		VillageStairs:  163 << 4,   // acacia stairs
+		VillageFence:   192 << 4,   // acacia fence
+		VillageDeco1:   31<<4 | 1,  // tall grass
+		VillageDeco2:   37 << 4,    // dandelion
+	}
+	// Swampland's biome ID also makes clients draw darker water and grass.
+	BiomeSwampland = &Biome{
+		ID: 6, Name: "Swampland",
+		SurfaceBlock: 2 << 4, // grass
+		FillerBlock:  3 << 4, // dirt
+		BaseHeight:   62, HeightVariation: 3,
+		TreeDensity:    0.03,
+		Tree:           TreeSwampOak,
+		LilyPadDensity: 0.06,
+		VillageLog:     17 << 4,   // oak log
+		VillagePlanks:  5 << 4,    // oak planks
+		VillagePath:    13 << 4,   // gravel
+		VillageSlab:    126 << 4,  // oak slab
+		VillageStairs:  53 << 4,   // oak stairs
+		VillageFence:   85 << 4,   // oak fence
+		VillageDeco1:   38<<4 | 1, // blue orchid
+		VillageDeco2:   39 << 4,   // brown mushroom
+	}
+	BiomeMesa = &Biome{
+		ID: 37, Name: "Mesa",
+		SurfaceBlock: 12<<4 | 1, // red sand
+		FillerBlock:  172 << 4,  // hardened clay
+		StrataBlock:  172 << 4,  // hardened clay
+		BaseHeight:   72, HeightVariation: 22,
+		TreeDensity:    0.006,
+		Tree:           TreeCactus,
+		BoulderDensity: 0.01,
+		ClayBands:      true,
+		VillageLog:     179<<4 | 2, // smooth red sandstone
+		VillagePlanks:  179 << 4,   // red sandstone
+		VillagePath:    179<<4 | 1, // chiseled red sandstone
+		VillageSlab:    182 << 4,   // red sandstone slab
+		VillageStairs:  180 << 4,   // red sandstone stairs
+		VillageFence:   139 << 4,   // cobblestone wall
+		VillageDeco1:   31 << 4,    // dead bush
+		VillageDeco2:   31 << 4,    // dead bush
+	}
+	BiomeMushroomIsland = &Biome{
+		ID: 14, Name: "Mushroom Island",
+		SurfaceBlock: 110 << 4, // mycelium
+		FillerBlock:  3 << 4,   // dirt
+		BaseHeight:   66, HeightVariation: 8,
+		TreeDensity:   0.01,
+		Tree:          TreeHugeMushroom,
+		VillageLog:    99<<4 | 10, // mushroom stem
+		VillagePlanks: 5 << 4,     // oak planks
+		VillagePath:   13 << 4,    // gravel
+		VillageSlab:   126 << 4,   // oak slab
+		VillageStairs: 53 << 4,    // oak stairs
+		VillageFence:  85 << 4,    // oak fence
+		VillageDeco1:  39 << 4,    // brown mushroom
+		VillageDeco2:  40 << 4,    // red mushroom
+	}
+	BiomeBirchForest = &Biome{
+		ID: 27, Name: "Birch Forest",
+		SurfaceBlock: 2 << 4, // grass
+		FillerBlock:  3 << 4, // dirt
+		BaseHeight:   68, HeightVariation: 12,
+		TreeDensity:    0.07,
+		Tree:           TreeBirch,
+		BoulderDensity: 0.02,
+		VillageLog:     17<<4 | 2,  // birch log
+		VillagePlanks:  5<<4 | 2,   // birch planks
+		VillagePath:    13 << 4,    // gravel
+		VillageSlab:    126<<4 | 2, // birch slab
+		VillageStairs:  135 << 4,   // birch stairs
+		VillageFence:   189 << 4,   // birch fence
+		VillageDeco1:   37 << 4,    // dandelion
+		VillageDeco2:   38<<4 | 8,  // oxeye daisy
+	}
+
+	// Shore biomes are chosen by terrain height rather than climate; see Generator.Biome.
+	BiomeRiver = &Biome{
+		ID: 7, Name: "River",
+		SurfaceBlock: 12 << 4, // sand
+		FillerBlock:  3 << 4,  // dirt
+		BaseHeight:   56, HeightVariation: 2,
+	}
+	BiomeFrozenRiver = &Biome{
+		ID: 11, Name: "Frozen River",
+		SurfaceBlock: 12 << 4, // sand
+		FillerBlock:  3 << 4,  // dirt
+		BaseHeight:   56, HeightVariation: 2,
+		HasSnow: true,
+	}
+	BiomeFrozenOcean = &Biome{
+		ID: 10, Name: "Frozen Ocean",
+		SurfaceBlock: 12 << 4, // sand
+		FillerBlock:  12 << 4, // sand
+		StrataBlock:  24 << 4, // sandstone
+		BaseHeight:   38, HeightVariation: 8,
+		HasSnow: true,
+	}
+	BiomeBeach = &Biome{
+		ID: 16, Name: "Beach",
+		SurfaceBlock: 12 << 4, // sand
+		FillerBlock:  12 << 4, // sand
+		StrataBlock:  24 << 4, // sandstone
+		BaseHeight:   63, HeightVariation: 2,
+	}
+	BiomeColdBeach = &Biome{
+		ID: 26, Name: "Cold Beach",
+		SurfaceBlock: 12 << 4, // sand
+		FillerBlock:  12 << 4, // sand
+		StrataBlock:  24 << 4, // sandstone
+		BaseHeight:   63, HeightVariation: 2,
+		HasSnow: true,
+	}
 )
 
 // allBiomes is an ordered list used for selection lookups.
@@ -153,6 +325,28 @@ var allBiomes = []*Biome{
 	BiomeJungle,
 	BiomeDarkForest,
 	BiomeSnowyTundra,
+	BiomeTaiga,
+	BiomeSavanna,
+	BiomeSwampland,
+	BiomeMesa,
+	BiomeMushroomIsland,
+	BiomeBirchForest,
+	BiomeRiver,
+	BiomeFrozenRiver,
+	BiomeFrozenOcean,
+	BiomeBeach,
+	BiomeColdBeach,
+}
+
+// BiomeByID returns the biome with the given Minecraft biome ID, or nil if
+// the generator has no such biome.
+func BiomeByID(id byte) *Biome {
+	for _, b := range allBiomes {
+		if b.ID == id {
+			return b
+		}
+	}
+	return nil
 }
 
 // BiomeAt selects a biome for a world block position using temperature and
@@ -185,34 +379,56 @@ func BiomeAt(tempNoise, rainNoise *Perlin, worldX, worldZ int) *Biome {
 
 	// Whittaker Diagram Classification:
 	// Tundra: Cold, any rain
-	// Taiga/Extreme Hills/Forest: Temperate
-	// Desert/Plains/Jungle: Warm
+	// Taiga/Extreme Hills: Cool
+	// Forests/Plains/Mushroom Island: Temperate
+	// Jungle/Swamp/Savanna/Desert/Mesa: Warm
 
 	switch {
 	case temp < 0.35: // Cold Region
 		return BiomeSnowyTundra
 
-	case temp < 0.65: // Temperate Region
-		if rain > 0.7 {
+	case temp < 0.42: // Cool Region
+		if rain > 0.45 {
+			return BiomeTaiga
+		}
+		return BiomeExtremeHills
+
+	case temp < 0.62: // Temperate Region
+		if rain > 0.74 {
+			return BiomeMushroomIsland // very rare, very wet
+		}
+		if rain > 0.62 {
 			return BiomeDarkForest // high moisture
 		}
-		if rain > 0.4 {
+		if rain > 0.54 {
 			return BiomeForest // med moisture
 		}
-		if rain > 0.25 {
+		if rain > 0.47 {
+			return BiomeBirchForest
+		}
+		if rain > 0.33 {
 			return BiomePlains // low moisture
 		}
 		return BiomeExtremeHills // very low moisture
 
-	default: // Warm/Hot Region (temp >= 0.65)
-		if rain > 0.75 {
+	default: // Warm/Hot Region (temp >= 0.62)
+		if rain > 0.66 {
 			return BiomeJungle // high moisture, hot
 		}
+		if rain > 0.56 {
+			return BiomeSwampland
+		}
 		// Transition between Jungle and Desert
-		if rain > 0.4 {
+		if rain > 0.46 {
 			return BiomePlains // somewhat dry, hot
 		}
+		if rain > 0.38 {
+			return BiomeSavanna
+		}
 		// Dry, hot
-		return BiomeDesert
+		if rain > 0.3 {
+			return BiomeDesert
+		}
+		return BiomeMesa
 	}
 }
@@ -49,3 +49,62 @@ func TestBiomeFieldsValid(t *testing.T) {
 		}
 	}
 }
+
+func TestEveryBiomeGenerated(t *testing.T) {
+	g := NewGenerator(42)
+	found := make(map[*Biome]bool)
+	for x := -3000; x < 3000; x += 37 {
+		for z := -3000; z < 3000; z += 37 {
+			b := g.Biome(x, z)
+			found[b] = true
+
+			h := g.SurfaceHeight(x, z)
+			switch b {
+			case BiomeRiver, BiomeFrozenRiver, BiomeOcean, BiomeFrozenOcean:
+				if h >= WaterLevel {
+					t.Fatalf("%s at (%d, %d) has dry surface height %d", b.Name, x, z, h)
+				}
+			case BiomeBeach, BiomeColdBeach:
+				if h < WaterLevel-4 || h > WaterLevel+1 {
+					t.Fatalf("%s at (%d, %d) has surface height %d", b.Name, x, z, h)
+				}
+			}
+		}
+	}
+
+	for _, b := range allBiomes {
+		// Frozen oceans need deep water in the cold, which this terrain rarely has
+		if !found[b] && b != BiomeFrozenOcean {
+			t.Errorf("biome %s not found in a 6000x6000 area", b.Name)
+		}
+	}
+}
+
+func TestMesaClayBands(t *testing.T) {
+	g := NewGenerator(5)
+	if g.clayBands != clayBands(5) {
+		t.Error("clay bands are not deterministic")
+	}
+	stained := 0
+	for _, block := range g.clayBands {
+		switch block >> 4 {
+		case 159:
+			stained++
+		case 172:
+		default:
+			t.Fatalf("unexpected clay band block %d", block)
+		}
+	}
+	if stained == 0 {
+		t.Error("no stained clay bands")
+	}
+
+	for y := 60; y < 70; y++ {
+		if got := g.undergroundBlock(BiomeMesa, y, 70, 5); got != g.clayBands[y%64] {
+			t.Errorf("mesa at y=%d = %d, want clay band %d", y, got, g.clayBands[y%64])
+		}
+	}
+	if got := g.undergroundBlock(BiomeMesa, 40, 70, 5); got != 1<<4 {
+		t.Errorf("deep mesa = %d, want stone", got)
+	}
+}
@@ -0,0 +1,298 @@
+package world
+
+import "math"
+
+// carverRange is how many chunks away a cave or ravine can start and still
+// reach the chunk being generated.
+const carverRange = 8
+
+// Salts separating the cave and ravine random streams of a chunk.
+const (
+	caveSalt   = 0x63617665
+	ravineSalt = 0x72617669
+)
+
+// carverRand is a small splitmix64 random source. Carvers replay the tunnels
+// of every chunk within carverRange, so seeding must be cheap.
+type carverRand struct {
+	state uint64
+}
+
+func newCarverRand(seed int64) *carverRand {
+	return &carverRand{state: uint64(seed)}
+}
+
+func (r *carverRand) next() uint64 {
+	r.state += 0x9E3779B97F4A7C15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// Int63 returns a non-negative random int64, used to seed sub-tunnels.
+func (r *carverRand) Int63() int64 { return int64(r.next() >> 1) }
+
+// Intn returns a random int in [0, n). n <= 0 returns 0.
+func (r *carverRand) Intn(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return int(r.next() % uint64(n))
+}
+
+// Float64 returns a random float in [0, 1).
+func (r *carverRand) Float64() float64 { return float64(r.next()>>11) / (1 << 53) }
+
+// carveTarget is the chunk being carved.
+type carveTarget struct {
+	chunkX, chunkZ int
+	sections       *[SectionsPerChunk][ChunkSectionSize]uint16
+	heightCache    *[24][24]int // surface heights for local coords [-4, 19]
+	lavaLevel      int
+}
+
+// carve cuts vanilla-style worm caves and ravines through a chunk. Tunnels
+// starting in every chunk within carverRange are replayed from their own seed
+// and clipped to this chunk, so they continue seamlessly across chunk borders.
+func (g *Generator) carve(chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16, heightCache *[24][24]int) {
+	t := &carveTarget{chunkX: chunkX, chunkZ: chunkZ, sections: sections, heightCache: heightCache, lavaLevel: g.LavaLevel}
+	for ox := chunkX - carverRange; ox <= chunkX+carverRange; ox++ {
+		for oz := chunkZ - carverRange; oz <= chunkZ+carverRange; oz++ {
+			t.caveSystems(newCarverRand(g.chunkSeed(ox, oz, caveSalt)), ox, oz)
+			t.ravines(newCarverRand(g.chunkSeed(ox, oz, ravineSalt)), ox, oz)
+		}
+	}
+}
+
+// chunkSeed mixes the world seed with a chunk position and salt.
+func (g *Generator) chunkSeed(chunkX, chunkZ int, salt int64) int64 {
+	return positionSeed(g.Seed, int64(chunkX), int64(chunkZ), salt)
+}
+
+// caveSystems replays the cave systems starting in chunk (originX, originZ).
+// Most chunks have none; the rest get a few tunnels, sometimes branching from a room.
+func (t *carveTarget) caveSystems(r *carverRand, originX, originZ int) {
+	count := r.Intn(r.Intn(r.Intn(15)+1) + 1)
+	if r.Intn(7) != 0 {
+		count = 0
+	}
+	for i := 0; i < count; i++ {
+		x := float64(originX*16 + r.Intn(16))
+		y := float64(r.Intn(r.Intn(120) + 8))
+		z := float64(originZ*16 + r.Intn(16))
+
+		tunnels := 1
+		if r.Intn(4) == 0 {
+			t.tunnel(r.Int63(), x, y, z, 1+r.Float64()*6, 0, 0, -1, -1, 0.5)
+			tunnels += r.Intn(4)
+		}
+		for j := 0; j < tunnels; j++ {
+			yaw := r.Float64() * math.Pi * 2
+			pitch := (r.Float64() - 0.5) * 2 / 8
+			width := r.Float64()*2 + r.Float64()
+			if r.Intn(10) == 0 {
+				width *= r.Float64()*r.Float64()*3 + 1
+			}
+			t.tunnel(r.Int63(), x, y, z, width, yaw, pitch, 0, 0, 1)
+		}
+	}
+}
+
+// tunnel carves a winding worm tunnel from (x, y, z). A step of -1 carves a
+// single round room instead. Wide tunnels split into two branches partway.
+func (t *carveTarget) tunnel(seed int64, x, y, z, width, yaw, pitch float64, step, maxSteps int, heightScale float64) {
+	r := newCarverRand(seed)
+	centerX, centerZ := float64(t.chunkX*16+8), float64(t.chunkZ*16+8)
+	var yawChange, pitchChange float64
+
+	if maxSteps <= 0 {
+		n := carverRange*16 - 16
+		maxSteps = n - r.Intn(n/4)
+	}
+	room := false
+	if step == -1 {
+		step = maxSteps / 2
+		room = true
+	}
+	branchAt := r.Intn(maxSteps/2) + maxSteps/4
+	steep := r.Intn(6) == 0
+
+	for ; step < maxSteps; step++ {
+		radius := 1.5 + math.Sin(float64(step)*math.Pi/float64(maxSteps))*width
+		vRadius := radius * heightScale
+
+		x += math.Cos(yaw) * math.Cos(pitch)
+		y += math.Sin(pitch)
+		z += math.Sin(yaw) * math.Cos(pitch)
+		if steep {
+			pitch *= 0.92
+		} else {
+			pitch *= 0.7
+		}
+		pitch += pitchChange * 0.1
+		yaw += yawChange * 0.1
+		pitchChange *= 0.9
+		yawChange *= 0.75
+		pitchChange += (r.Float64() - r.Float64()) * r.Float64() * 2
+		yawChange += (r.Float64() - r.Float64()) * r.Float64() * 4
+
+		if !room && step == branchAt && width > 1 {
+			t.tunnel(r.Int63(), x, y, z, r.Float64()*0.5+0.5, yaw-math.Pi/2, pitch/3, step, maxSteps, 1)
+			t.tunnel(r.Int63(), x, y, z, r.Float64()*0.5+0.5, yaw+math.Pi/2, pitch/3, step, maxSteps, 1)
+			return
+		}
+		if !room && r.Intn(4) == 0 {
+			continue
+		}
+
+		// Give up once the tunnel can no longer reach this chunk
+		dx, dz := x-centerX, z-centerZ
+		remaining := float64(maxSteps - step)
+		reach := width + 2 + 16
+		if dx*dx+dz*dz-remaining*remaining > reach*reach {
+			return
+		}
+
+		if x >= centerX-16-radius*2 && z >= centerZ-16-radius*2 && x <= centerX+16+radius*2 && z <= centerZ+16+radius*2 {
+			t.carveBlob(x, y, z, radius, vRadius, func(dx, dy, dz float64, _ int) bool {
+				return dy > -0.7 && dx*dx+dy*dy+dz*dz < 1
+			})
+			if room {
+				return
+			}
+		}
+	}
+}
+
+// ravines replays the ravine, if any, starting in chunk (originX, originZ).
+func (t *carveTarget) ravines(r *carverRand, originX, originZ int) {
+	if r.Intn(50) != 0 {
+		return
+	}
+	x := float64(originX*16 + r.Intn(16))
+	y := float64(r.Intn(r.Intn(40)+8) + 20)
+	z := float64(originZ*16 + r.Intn(16))
+	yaw := r.Float64() * math.Pi * 2
+	pitch := (r.Float64() - 0.5) * 2 / 8
+	width := (r.Float64()*2 + r.Float64()) * 2
+	t.ravine(r.Int63(), x, y, z, width, yaw, pitch, 3)
+}
+
+// ravine carves a tall, narrow ravine. The walls are made uneven by widening
+// the ravine by a random factor at each height.
+func (t *carveTarget) ravine(seed int64, x, y, z, width, yaw, pitch, heightScale float64) {
+	r := newCarverRand(seed)
+	centerX, centerZ := float64(t.chunkX*16+8), float64(t.chunkZ*16+8)
+	var yawChange, pitchChange float64
+
+	n := carverRange*16 - 16
+	maxSteps := n - r.Intn(n/4)
+
+	var wallScale [256]float64
+	scale := 1.0
+	for i := range wallScale {
+		if i == 0 || r.Intn(3) == 0 {
+			scale = 1 + r.Float64()*r.Float64()
+		}
+		wallScale[i] = scale * scale
+	}
+
+	for step := 0; step < maxSteps; step++ {
+		radius := 1.5 + math.Sin(float64(step)*math.Pi/float64(maxSteps))*width
+		vRadius := radius * heightScale
+		radius *= r.Float64()*0.25 + 0.75
+		vRadius *= r.Float64()*0.25 + 0.75
+
+		x += math.Cos(yaw) * math.Cos(pitch)
+		y += math.Sin(pitch)
+		z += math.Sin(yaw) * math.Cos(pitch)
+		pitch *= 0.7
+		pitch += pitchChange * 0.05
+		yaw += yawChange * 0.05
+		pitchChange *= 0.8
+		yawChange *= 0.5
+		pitchChange += (r.Float64() - r.Float64()) * r.Float64() * 2
+		yawChange += (r.Float64() - r.Float64()) * r.Float64() * 4
+
+		if r.Intn(4) == 0 {
+			continue
+		}
+
+		dx, dz := x-centerX, z-centerZ
+		remaining := float64(maxSteps - step)
+		reach := width + 2 + 16
+		if dx*dx+dz*dz-remaining*remaining > reach*reach {
+			return
+		}
+
+		if x >= centerX-16-radius*2 && z >= centerZ-16-radius*2 && x <= centerX+16+radius*2 && z <= centerZ+16+radius*2 {
+			t.carveBlob(x, y, z, radius, vRadius, func(dx, dy, dz float64, by int) bool {
+				return (dx*dx+dz*dz)*wallScale[by]+dy*dy/6 < 1
+			})
+		}
+	}
+}
+
+// carveBlob carves the blocks of this chunk within the ellipsoid centred on
+// (x, y, z) for which inside returns true. inside gets the block's offset from
+// the centre scaled by the radii, and its Y. Blobs that would break into the
+// sea, a river or a lake are skipped so carvers never drain them.
+func (t *carveTarget) carveBlob(x, y, z, radius, vRadius float64, inside func(dx, dy, dz float64, by int) bool) {
+	minX, minZ := t.chunkX*16, t.chunkZ*16
+	x0 := max(int(math.Floor(x-radius))-1, minX)
+	x1 := min(int(math.Floor(x+radius))+1, minX+15)
+	z0 := max(int(math.Floor(z-radius))-1, minZ)
+	z1 := min(int(math.Floor(z+radius))+1, minZ+15)
+	y0 := max(int(math.Floor(y-vRadius))-1, 1)
+	y1 := min(int(math.Floor(y+vRadius))+1, 248)
+	if x0 > x1 || z0 > z1 || y0 > y1 {
+		return
+	}
+
+	// Any column under water whose floor the blob reaches means breaking into water
+	for bx := x0; bx <= x1; bx++ {
+		for bz := z0; bz <= z1; bz++ {
+			surfH := t.heightCache[bx-minX+4][bz-minZ+4]
+			if surfH < WaterLevel && y1 >= surfH-1 {
+				return
+			}
+		}
+	}
+
+	for bx := x0; bx <= x1; bx++ {
+		dx := (float64(bx) + 0.5 - x) / radius
+		for bz := z0; bz <= z1; bz++ {
+			dz := (float64(bz) + 0.5 - z) / radius
+			if dx*dx+dz*dz >= 1 {
+				continue
+			}
+			lx, lz := bx-minX, bz-minZ
+			for by := y1; by >= y0; by-- {
+				dy := (float64(by) + 0.5 - y) / vRadius
+				if !inside(dx, dy, dz, by) {
+					continue
+				}
+				idx := ((by%16)*16+lz)*16 + lx
+				if !isCarvable(t.sections[by/16][idx]) {
+					continue
+				}
+				if by <= t.lavaLevel {
+					t.sections[by/16][idx] = 11 << 4 // Lava
+				} else {
+					t.sections[by/16][idx] = 0
+				}
+			}
+		}
+	}
+}
+
+// isCarvable returns true for the natural terrain blocks carvers cut through.
+// Sand and gravel are left alone so they do not hang over cave openings.
+func isCarvable(state uint16) bool {
+	switch state >> 4 {
+	case 1, 2, 3, 24, 80, 110, 159, 172, 179: // stone, grass, dirt, sandstone, snow, mycelium, clays, red sandstone
+		return true
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package world
+
+import "testing"
+
+// carveTestChunk returns stone-filled sections up to surfH with a flat height cache.
+func carveTestChunk(surfH int) (*[SectionsPerChunk][ChunkSectionSize]uint16, *[24][24]int) {
+	var sections [SectionsPerChunk][ChunkSectionSize]uint16
+	var heights [24][24]int
+	for y := 1; y <= surfH; y++ {
+		for i := 0; i < 256; i++ {
+			sections[y/16][(y%16)*256+i] = 1 << 4
+		}
+	}
+	for x := range heights {
+		for z := range heights[x] {
+			heights[x][z] = surfH
+		}
+	}
+	return &sections, &heights
+}
+
+func TestCarveCaves(t *testing.T) {
+	g := NewGenerator(77)
+	g.LavaLevel = 20
+
+	air, lava := 0, 0
+	for cx := 0; cx < 6; cx++ {
+		for cz := 0; cz < 6; cz++ {
+			sections, heights := carveTestChunk(100)
+			g.carve(cx, cz, sections, heights)
+
+			again, _ := carveTestChunk(100)
+			g.carve(cx, cz, again, heights)
+			if *again != *sections {
+				t.Fatalf("carving chunk (%d, %d) is not deterministic", cx, cz)
+			}
+
+			for y := 1; y <= 100; y++ {
+				for i := 0; i < 256; i++ {
+					switch sections[y/16][(y%16)*256+i] >> 4 {
+					case 0:
+						air++
+						if y <= g.LavaLevel {
+							t.Fatalf("air at y=%d, below the lava level", y)
+						}
+					case 11:
+						lava++
+						if y > g.LavaLevel {
+							t.Fatalf("lava at y=%d, above the lava level", y)
+						}
+					}
+				}
+			}
+		}
+	}
+	if air == 0 || lava == 0 {
+		t.Errorf("carved %d air and %d lava blocks in 36 chunks, want both", air, lava)
+	}
+}
+
+func TestCarversAvoidWater(t *testing.T) {
+	g := NewGenerator(77)
+	const seaFloor = 45
+	for cx := 0; cx < 6; cx++ {
+		for cz := 0; cz < 6; cz++ {
+			sections, heights := carveTestChunk(seaFloor)
+			g.carve(cx, cz, sections, heights)
+			for y := seaFloor - 1; y <= seaFloor; y++ {
+				for i := 0; i < 256; i++ {
+					if sections[y/16][(y%16)*256+i] != 1<<4 {
+						t.Fatalf("carver broke through the sea floor at y=%d in chunk (%d, %d)", y, cx, cz)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRavineIsDeep(t *testing.T) {
+	g := NewGenerator(1)
+	sections, heights := carveTestChunk(100)
+	target := &carveTarget{sections: sections, heightCache: heights, lavaLevel: g.LavaLevel}
+	target.ravine(5, 0, 50, 8, 3, 0, 0, 3)
+
+	// Find the tallest carved column
+	tallest := 0
+	for i := 0; i < 256; i++ {
+		n := 0
+		for y := 1; y <= 100; y++ {
+			if sections[y/16][(y%16)*256+i] == 0 {
+				n++
+			}
+		}
+		tallest = max(tallest, n)
+	}
+	if tallest < 10 {
+		t.Errorf("tallest ravine column is %d blocks, want at least 10", tallest)
+	}
+}
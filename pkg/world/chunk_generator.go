@@ -0,0 +1,273 @@
+package world
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ChunkGenerator produces the terrain of a world, one chunk column at a time.
+type ChunkGenerator interface {
+	// GenerateInternal returns the block sections and biome IDs of the chunk column at (chunkX, chunkZ).
+	GenerateInternal(chunkX, chunkZ int) ([SectionsPerChunk][ChunkSectionSize]uint16, [256]byte)
+	// SurfaceHeight returns the Y of the highest solid block at world-space x, z.
+	SurfaceHeight(x, z int) int
+	// LevelType returns the level type sent to clients in Join Game and Respawn.
+	LevelType() string
+}
+
+// World types selectable in the server configuration.
+const (
+	WorldTypeDefault   = "default"
+	WorldTypeFlat      = "flat"
+	WorldTypeVoid      = "void"
+	WorldTypeAmplified = "amplified"
+)
+
+// NewChunkGenerator creates the generator for a world type. Settings is the
+// layer preset for flat worlds and is ignored by the other types.
+func NewChunkGenerator(worldType string, seed int64, settings string) (ChunkGenerator, error) {
+	switch strings.ToLower(worldType) {
+	case "", WorldTypeDefault, "largebiomes", "default_1_1", "customized":
+		// Vanilla types without a dedicated generator use the default terrain
+		return NewGenerator(seed), nil
+	case WorldTypeAmplified:
+		return NewAmplifiedGenerator(seed), nil
+	case WorldTypeFlat:
+		if settings == "" {
+			settings = DefaultFlatPreset
+		}
+		return ParseFlatPreset(settings)
+	case WorldTypeVoid:
+		return VoidGenerator{}, nil
+	}
+	return nil, fmt.Errorf("unknown world type %q (expected default, flat, void or amplified)", worldType)
+}
+
+// DefaultFlatPreset is the vanilla "Classic Flat" superflat preset.
+const DefaultFlatPreset = "3;minecraft:bedrock,2*minecraft:dirt,minecraft:grass;1;village"
+
+// defaultFlatGenerator generates DefaultFlatPreset worlds.
+var defaultFlatGenerator = mustParseFlatPreset(DefaultFlatPreset)
+
+// mustParseFlatPreset is ParseFlatPreset for presets known to be valid.
+func mustParseFlatPreset(preset string) *FlatGenerator {
+	g, err := ParseFlatPreset(preset)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// FlatLayer is a run of identical blocks in a superflat world.
+type FlatLayer struct {
+	Block  uint16 // Block state (blockID << 4 | metadata)
+	Height int
+}
+
+// FlatGenerator generates superflat worlds from a stack of layers.
+type FlatGenerator struct {
+	Layers []FlatLayer // Bottom layer first
+	Biome  byte
+}
+
+// flatBlockNames maps the block names accepted in flat presets to block IDs.
+var flatBlockNames = map[string]uint16{
+	"air":               0,
+	"stone":             1,
+	"grass":             2,
+	"dirt":              3,
+	"cobblestone":       4,
+	"planks":            5,
+	"bedrock":           7,
+	"water":             9,
+	"lava":              11,
+	"sand":              12,
+	"gravel":            13,
+	"gold_ore":          14,
+	"iron_ore":          15,
+	"coal_ore":          16,
+	"log":               17,
+	"leaves":            18,
+	"glass":             20,
+	"sandstone":         24,
+	"wool":              35,
+	"mossy_cobblestone": 48,
+	"obsidian":          49,
+	"snow_layer":        78,
+	"ice":               79,
+	"snow":              80,
+	"clay":              82,
+	"netherrack":        87,
+	"soul_sand":         88,
+	"glowstone":         89,
+	"stonebrick":        98,
+	"mycelium":          110,
+	"end_stone":         121,
+	"quartz_block":      155,
+	"hardened_clay":     172,
+	"packed_ice":        174,
+}
+
+// ParseFlatPreset parses a vanilla superflat preset string
+// ("version;layers;biome;structures"), e.g. "3;minecraft:bedrock,2*minecraft:dirt,minecraft:grass;1".
+// Layers are "[count*]block[:meta]" with block names or numeric IDs; version 2
+// presets using "countxID" are also accepted. Structure options are ignored.
+func ParseFlatPreset(preset string) (*FlatGenerator, error) {
+	parts := strings.Split(preset, ";")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid flat preset %q: expected version;layers[;biome]", preset)
+	}
+
+	g := &FlatGenerator{Biome: 1} // Plains
+	height := 0
+	for _, spec := range strings.Split(parts[1], ",") {
+		layer, err := parseFlatLayer(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, fmt.Errorf("invalid flat preset %q: %w", preset, err)
+		}
+		height += layer.Height
+		g.Layers = append(g.Layers, layer)
+	}
+	if height > ChunkHeight {
+		return nil, fmt.Errorf("invalid flat preset %q: layers are %d blocks tall, maximum is %d", preset, height, ChunkHeight)
+	}
+
+	if len(parts) > 2 && parts[2] != "" {
+		biome, err := strconv.ParseUint(parts[2], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid flat preset %q: bad biome %q", preset, parts[2])
+		}
+		g.Biome = byte(biome)
+	}
+	return g, nil
+}
+
+// parseFlatLayer parses a single "[count*]block[:meta]" layer.
+func parseFlatLayer(spec string) (FlatLayer, error) {
+	layer := FlatLayer{Height: 1}
+	if i := strings.IndexAny(spec, "*x"); i > 0 {
+		n, err := strconv.Atoi(spec[:i])
+		if err == nil {
+			if n < 1 {
+				return layer, fmt.Errorf("bad layer count in %q", spec)
+			}
+			layer.Height = n
+			spec = spec[i+1:]
+		}
+	}
+
+	name := strings.TrimPrefix(spec, "minecraft:")
+	var meta uint64
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		var err error
+		if meta, err = strconv.ParseUint(name[i+1:], 10, 4); err != nil {
+			return layer, fmt.Errorf("bad metadata in %q", spec)
+		}
+		name = name[:i]
+	}
+
+	id, ok := flatBlockNames[name]
+	if !ok {
+		n, err := strconv.ParseUint(name, 10, 12)
+		if err != nil {
+			return layer, fmt.Errorf("unknown block %q", spec)
+		}
+		id = uint16(n)
+	}
+	layer.Block = id<<4 | uint16(meta)
+	return layer, nil
+}
+
+// GenerateInternal implements ChunkGenerator.
+func (g *FlatGenerator) GenerateInternal(chunkX, chunkZ int) ([SectionsPerChunk][ChunkSectionSize]uint16, [256]byte) {
+	var sections [SectionsPerChunk][ChunkSectionSize]uint16
+	var biomes [256]byte
+	for i := range biomes {
+		biomes[i] = g.Biome
+	}
+
+	for y := 0; y < ChunkHeight; y++ {
+		if block := g.BlockAt(y); block != 0 {
+			sec, sy := y/16, y%16
+			for i := 0; i < 256; i++ {
+				sections[sec][sy*256+i] = block
+			}
+		}
+	}
+	return sections, biomes
+}
+
+// BlockAt returns the block state of the layer at height y, or air above the
+// top layer and outside the world.
+func (g *FlatGenerator) BlockAt(y int) uint16 {
+	if y < 0 {
+		return 0
+	}
+	for _, layer := range g.Layers {
+		if y < layer.Height {
+			return layer.Block
+		}
+		y -= layer.Height
+	}
+	return 0
+}
+
+// SurfaceHeight implements ChunkGenerator. It returns the top of the highest
+// non-air layer, or 0 if every layer is air.
+func (g *FlatGenerator) SurfaceHeight(x, z int) int {
+	y, top := 0, 0
+	for _, layer := range g.Layers {
+		y += layer.Height
+		if layer.Block != 0 {
+			top = y - 1
+		}
+	}
+	return top
+}
+
+// LevelType implements ChunkGenerator.
+func (g *FlatGenerator) LevelType() string { return "flat" }
+
+// Void world spawn platform: a square of stone centred on the spawn point.
+const (
+	voidPlatformY      = 64
+	voidPlatformRadius = 2
+)
+
+// VoidGenerator generates an empty world with only a small stone platform at spawn.
+type VoidGenerator struct{}
+
+// onVoidPlatform returns true if world-space x, z is part of the spawn platform.
+func onVoidPlatform(x, z int) bool {
+	return x >= 8-voidPlatformRadius && x <= 8+voidPlatformRadius && z >= 8-voidPlatformRadius && z <= 8+voidPlatformRadius
+}
+
+// GenerateInternal implements ChunkGenerator.
+func (VoidGenerator) GenerateInternal(chunkX, chunkZ int) ([SectionsPerChunk][ChunkSectionSize]uint16, [256]byte) {
+	var sections [SectionsPerChunk][ChunkSectionSize]uint16
+	var biomes [256]byte
+	for i := range biomes {
+		biomes[i] = 1 // Plains
+	}
+	for lx := 0; lx < 16; lx++ {
+		for lz := 0; lz < 16; lz++ {
+			if onVoidPlatform(chunkX*16+lx, chunkZ*16+lz) {
+				sections[voidPlatformY/16][((voidPlatformY%16)*16+lz)*16+lx] = 1 << 4 // Stone
+			}
+		}
+	}
+	return sections, biomes
+}
+
+// SurfaceHeight implements ChunkGenerator.
+func (VoidGenerator) SurfaceHeight(x, z int) int {
+	if onVoidPlatform(x, z) {
+		return voidPlatformY
+	}
+	return 0
+}
+
+// LevelType implements ChunkGenerator. Flat makes the client draw the horizon
+// at Y 0 instead of sea level, which suits an empty world.
+func (VoidGenerator) LevelType() string { return "flat" }
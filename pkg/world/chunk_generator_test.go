@@ -0,0 +1,105 @@
+package world
+
+import "testing"
+
+func TestParseFlatPreset(t *testing.T) {
+	g, err := ParseFlatPreset(DefaultFlatPreset)
+	if err != nil {
+		t.Fatalf("ParseFlatPreset: %v", err)
+	}
+	want := []FlatLayer{{7 << 4, 1}, {3 << 4, 2}, {2 << 4, 1}}
+	if len(g.Layers) != len(want) {
+		t.Fatalf("layers = %v, want %v", g.Layers, want)
+	}
+	for i := range want {
+		if g.Layers[i] != want[i] {
+			t.Errorf("layer %d = %v, want %v", i, g.Layers[i], want[i])
+		}
+	}
+	if g.Biome != 1 {
+		t.Errorf("biome = %d, want 1", g.Biome)
+	}
+	if h := g.SurfaceHeight(0, 0); h != 3 {
+		t.Errorf("SurfaceHeight = %d, want 3", h)
+	}
+
+	sections, biomes := g.GenerateInternal(5, -3)
+	if sections[0][(3*16+4)*16+4] != 2<<4 || sections[0][(4*16+4)*16+4] != 0 {
+		t.Error("generated chunk does not match the layers")
+	}
+	if biomes[100] != 1 {
+		t.Errorf("biome = %d, want 1", biomes[100])
+	}
+}
+
+func TestParseFlatPresetVariants(t *testing.T) {
+	// Version 2 numeric format, metadata and a custom biome
+	g, err := ParseFlatPreset("2;7,3x1,35:14;2")
+	if err != nil {
+		t.Fatalf("ParseFlatPreset: %v", err)
+	}
+	if len(g.Layers) != 3 || g.Layers[1] != (FlatLayer{1 << 4, 3}) || g.Layers[2] != (FlatLayer{35<<4 | 14, 1}) {
+		t.Errorf("layers = %v", g.Layers)
+	}
+	if g.Biome != 2 {
+		t.Errorf("biome = %d, want 2", g.Biome)
+	}
+
+	for _, bad := range []string{"", "3;minecraft:unobtainium", "3;300*minecraft:stone", "3;minecraft:stone;notabiome"} {
+		if _, err := ParseFlatPreset(bad); err == nil {
+			t.Errorf("ParseFlatPreset(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFlatWorldBlock(t *testing.T) {
+	want := map[int32]uint16{-1: 0, 0: 7 << 4, 1: 3 << 4, 2: 3 << 4, 3: 2 << 4, 4: 0, 255: 0, 256: 0}
+	for y, block := range want {
+		if got := FlatWorldBlock(y); got != block {
+			t.Errorf("FlatWorldBlock(%d) = %d:%d, want %d:%d", y, got>>4, got&15, block>>4, block&15)
+		}
+	}
+}
+
+func TestVoidGenerator(t *testing.T) {
+	w := NewWorldWithGenerator(VoidGenerator{})
+	if got := w.GetBlock(8, voidPlatformY, 8); got != 1<<4 {
+		t.Errorf("spawn platform block = %d, want stone", got)
+	}
+	if got := w.GetBlock(100, voidPlatformY, 100); got != 0 {
+		t.Errorf("block away from spawn = %d, want air", got)
+	}
+	if got := w.GetBlock(8, 0, 8); got != 0 {
+		t.Errorf("void world should have no bedrock, got %d", got)
+	}
+	if h := w.Gen.SurfaceHeight(8, 8); h != voidPlatformY {
+		t.Errorf("SurfaceHeight at spawn = %d, want %d", h, voidPlatformY)
+	}
+}
+
+func TestNewChunkGenerator(t *testing.T) {
+	tests := []struct {
+		worldType string
+		levelType string
+	}{
+		{"", "default"},
+		{"DEFAULT", "default"},
+		{"flat", "flat"},
+		{"void", "flat"},
+		{"AMPLIFIED", "amplified"},
+		{"largeBiomes", "default"},
+	}
+	for _, tt := range tests {
+		gen, err := NewChunkGenerator(tt.worldType, 1, "")
+		if err != nil {
+			t.Errorf("NewChunkGenerator(%q): %v", tt.worldType, err)
+			continue
+		}
+		if gen.LevelType() != tt.levelType {
+			t.Errorf("NewChunkGenerator(%q).LevelType() = %q, want %q", tt.worldType, gen.LevelType(), tt.levelType)
+		}
+	}
+	if _, err := NewChunkGenerator("nether", 1, ""); err == nil {
+		t.Error("unknown world type should fail")
+	}
+}
@@ -0,0 +1,127 @@
+package world
+
+// dungeonSalt separates the dungeon random stream from other per-chunk streams.
+const dungeonSalt = 0x64756E67
+
+// dungeonAttempts is how many dungeon positions are tried per chunk. Most
+// attempts fail, as the room must be enclosed with only a few openings.
+const dungeonAttempts = 8
+
+// dungeonMobs are the spawner mobs; zombies are twice as likely as the others.
+var dungeonMobs = []string{"Skeleton", "Zombie", "Zombie", "Spider"}
+
+// isSolidTerrain returns true for blocks a dungeon can be built against:
+// anything but air and liquids.
+func isSolidTerrain(state uint16) bool {
+	id := state >> 4
+	return id != 0 && (id < 8 || id > 11)
+}
+
+// generateDungeons places vanilla-style monster rooms: a cobblestone and mossy
+// cobblestone room with a mob spawner in the middle and up to two loot chests
+// against its walls. A room is only built where it would be fully enclosed by
+// terrain apart from one to five openings into caves. Rooms are kept inside
+// a single chunk, so the check only needs the chunk's own blocks.
+func (g *Generator) generateDungeons(chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16, features *ChunkFeatures) {
+	r := g.chunkRand(chunkX, chunkZ, dungeonSalt)
+	get := func(lx, y, lz int) uint16 {
+		return sections[y/16][((y%16)*16+lz)*16+lx]
+	}
+
+	for i := 0; i < dungeonAttempts; i++ {
+		rx := r.Intn(2) + 2 // Interior half widths
+		rz := r.Intn(2) + 2
+		cx := rx + 1 + r.Intn(16-2*(rx+1))
+		cz := rz + 1 + r.Intn(16-2*(rz+1))
+		y := r.Intn(128)
+		if y-1 <= bedrockLayers {
+			continue
+		}
+
+		// The floor and ceiling must be solid, and the walls open in 1-5 places
+		valid := true
+		openings := 0
+		for x := cx - rx - 1; x <= cx+rx+1 && valid; x++ {
+			for z := cz - rz - 1; z <= cz+rz+1 && valid; z++ {
+				if !isSolidTerrain(get(x, y-1, z)) || !isSolidTerrain(get(x, y+4, z)) {
+					valid = false
+					break
+				}
+				edge := x == cx-rx-1 || x == cx+rx+1 || z == cz-rz-1 || z == cz+rz+1
+				if edge && get(x, y, z) == 0 && get(x, y+1, z) == 0 {
+					openings++
+				}
+			}
+		}
+		if !valid || openings < 1 || openings > 5 {
+			continue
+		}
+
+		for x := cx - rx - 1; x <= cx+rx+1; x++ {
+			for dy := 3; dy >= -1; dy-- {
+				by := y + dy
+				for z := cz - rz - 1; z <= cz+rz+1; z++ {
+					edge := x == cx-rx-1 || x == cx+rx+1 || z == cz-rz-1 || z == cz+rz+1
+					switch {
+					case !edge && dy >= 0:
+						setBlock(x, by, z, 0, sections)
+					case dy >= 0 && !isSolidTerrain(get(x, by-1, z)):
+						setBlock(x, by, z, 0, sections)
+					case isSolidTerrain(get(x, by, z)):
+						if dy == -1 && r.Intn(4) != 0 {
+							setBlock(x, by, z, 48<<4, sections) // Mossy cobblestone
+						} else {
+							setBlock(x, by, z, 4<<4, sections) // Cobblestone
+						}
+					}
+				}
+			}
+		}
+
+		// Up to two chests, each against exactly one wall
+		for c := 0; c < 2; c++ {
+			for try := 0; try < 3; try++ {
+				x := cx + r.Intn(rx*2+1) - rx
+				z := cz + r.Intn(rz*2+1) - rz
+				if get(x, y, z) != 0 {
+					continue
+				}
+				walls := 0
+				var facing uint16
+				if isSolidTerrain(get(x, y, z-1)) {
+					walls++
+					facing = 3 // Wall to the north, face south
+				}
+				if isSolidTerrain(get(x, y, z+1)) {
+					walls++
+					facing = 2
+				}
+				if isSolidTerrain(get(x-1, y, z)) {
+					walls++
+					facing = 5
+				}
+				if isSolidTerrain(get(x+1, y, z)) {
+					walls++
+					facing = 4
+				}
+				if walls != 1 {
+					continue
+				}
+				setBlock(x, y, z, 54<<4|facing, sections)
+				features.Chests = append(features.Chests, LootChest{
+					Pos:   BlockPos{int32(chunkX*16 + x), int32(y), int32(chunkZ*16 + z)},
+					Table: LootDungeon,
+					Seed:  r.Int63(),
+				})
+				break
+			}
+		}
+
+		mob := dungeonMobs[r.Intn(len(dungeonMobs))]
+		setBlock(cx, y, cz, 52<<4, sections)
+		features.Spawners = append(features.Spawners, MobSpawner{
+			Pos: BlockPos{int32(chunkX*16 + cx), int32(y), int32(chunkZ*16 + cz)},
+			Mob: mob,
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package world
+
+// LootChest is a generated container whose contents are rolled from a loot
+// table the first time it is opened.
+type LootChest struct {
+	Pos   BlockPos
+	Table string // Name of the table in LootTables
+	Seed  int64  // Seed for LootTable.Fill
+}
+
+// MobSpawner is a generated mob spawner block.
+type MobSpawner struct {
+	Pos BlockPos
+	Mob string // Entity name shown in and spawned by the spawner, e.g. "Zombie"
+}
+
+// StructureMob is a mob that lives in a generated structure, such as the
+// witch of a witch hut. It is spawned once, when its chunk is first sent.
+type StructureMob struct {
+	Pos BlockPos
+	Mob string // Entity name, as for MobSpawner
+}
+
+// Villager is a villager living in a generated village building. It wanders
+// within the village bounds.
+type Villager struct {
+	Pos                    BlockPos // Where it spawns, on its building's floor
+	Profession             int      // One of the Profession constants
+	Seed                   int64    // Seed for its career and trades
+	MinX, MinZ, MaxX, MaxZ int      // Village bounds
+}
+
+// TrapKind says what a generated trap does when set off.
+type TrapKind int
+
+const (
+	TrapTNT    TrapKind = iota // Ignites the TNT blocks at Targets
+	TrapArrows                 // Fires the dispensers at Targets
+)
+
+// Trap is a generated trap, set off by stepping on one of its trigger blocks
+// (pressure plates or tripwire). A trap is reported by the chunk containing
+// its first trigger; its other blocks may lie in neighbouring chunks.
+type Trap struct {
+	Kind     TrapKind
+	Triggers []BlockPos
+	Targets  []BlockPos
+}
+
+// ChunkFeatures lists what a generator placed in a chunk besides plain
+// blocks: loot chests, mob spawners, chest minecarts, structure mobs,
+// villagers and traps. Each feature is reported only by the chunk that
+// contains it.
+type ChunkFeatures struct {
+	Chests    []LootChest
+	Spawners  []MobSpawner
+	Minecarts []LootChest // Chest minecarts; Pos is the block the minecart stands in
+	Mobs      []StructureMob
+	Villagers []Villager
+	Traps     []Trap
+}
+
+// FeatureGenerator is implemented by chunk generators that also place loot
+// chests, spawners, entities and traps.
+type FeatureGenerator interface {
+	ChunkGenerator
+	// GenerateWithFeatures is GenerateInternal, also returning the chunk's features.
+	GenerateWithFeatures(chunkX, chunkZ int) ([SectionsPerChunk][ChunkSectionSize]uint16, [256]byte, ChunkFeatures)
+}
@@ -3,28 +3,36 @@ package world
 import (
 	"bytes"
 	"math"
+	"math/rand"
 )
 
 // Generator produces terrain data from a seed using Perlin noise.
 type Generator struct {
 	Seed         int64
-	terrain      *Perlin      // broad height map noise
-	roughness    *Perlin      // fine detail / roughness noise
-	tempNoise    *Perlin      // biome temperature
-	rainNoise    *Perlin      // biome rainfall
-	caveNoise    *Perlin      // 3D cave carving
-	cave2        *Perlin      // secondary 3D cave noise for spaghetti caves
-	treeNoise    *Perlin      // tree placement
-	boulderNoise *Perlin      // boulder placement
-	villageGen   *VillageGrid // village placement grid
-	lakeNoise    *Perlin      // lake carving noise
-	riverNoise   *Perlin      // river carving noise
+	LavaLevel    int                         // caves fill with lava at and below this Y
+	terrain      *Perlin                     // broad height map noise
+	roughness    *Perlin                     // fine detail / roughness noise
+	tempNoise    *Perlin                     // biome temperature
+	rainNoise    *Perlin                     // biome rainfall
+	caveNoise    *Perlin                     // 3D cave carving
+	cave2        *Perlin                     // secondary 3D cave noise for spaghetti caves
+	treeNoise    *Perlin                     // tree placement
+	boulderNoise *Perlin                     // boulder placement
+	villageGen   *VillageGrid                // village placement grid
+	mineshafts   *MineshaftGrid              // abandoned mineshaft placement grid
+	temples      *StructureGrid[*TemplePlan] // desert pyramid, jungle temple, witch hut and igloo placement grid
+	lakeNoise    *Perlin                     // lake carving noise
+	riverNoise   *Perlin                     // river carving noise
+	depthNoise   *Perlin                     // filler layer depth variation
+	clayBands    [64]uint16                  // mesa stained clay colours, repeating every 64 blocks of height
+	amplified    bool                        // exaggerate terrain height (amplified world type)
 }
 
 // NewGenerator creates a terrain generator from a seed.
 func NewGenerator(seed int64) *Generator {
 	g := &Generator{
 		Seed:         seed,
+		LavaLevel:    DefaultLavaLevel,
 		terrain:      NewPerlin(seed),
 		roughness:    NewPerlin(seed + 100),
 		tempNoise:    NewPerlin(seed + 1),
@@ -35,11 +43,38 @@ func NewGenerator(seed int64) *Generator {
 		boulderNoise: NewPerlin(seed + 200),
 	}
 	g.villageGen = NewVillageGrid(seed, g.tempNoise, g.rainNoise, g.SurfaceHeight)
+	g.mineshafts = NewMineshaftGrid(seed, g.SurfaceHeight)
+	g.temples = NewStructureGrid(seed, templeSalt, templeCellSize, g.templeSite)
 	g.lakeNoise = NewPerlin(seed + 300)
 	g.riverNoise = NewPerlin(seed + 400)
+	g.depthNoise = NewPerlin(seed + 500)
+	g.clayBands = clayBands(seed)
 	return g
 }
 
+// NewAmplifiedGenerator creates a generator for the amplified world type, whose
+// land rises far higher above sea level than in the default world.
+func NewAmplifiedGenerator(seed int64) *Generator {
+	g := NewGenerator(seed)
+	g.amplified = true
+	return g
+}
+
+// Amplified terrain scaling: land height above sea level is multiplied by
+// amplifiedScale and capped at amplifiedMaxHeight to leave room for trees.
+const (
+	amplifiedScale     = 3.0
+	amplifiedMaxHeight = 240
+)
+
+// LevelType implements ChunkGenerator.
+func (g *Generator) LevelType() string {
+	if g.amplified {
+		return "amplified"
+	}
+	return "default"
+}
+
 // SurfaceHeight returns the solid surface Y for the given world-space x, z.
 func (g *Generator) SurfaceHeight(x, z int) int {
 	// Biome Blending: sample biomes in a neighborhood to smooth transitions
@@ -97,9 +132,79 @@ func (g *Generator) SurfaceHeight(x, z int) int {
 		height -= depth
 	}
 
+	if g.amplified && height > WaterLevel {
+		height = min(WaterLevel+(height-WaterLevel)*amplifiedScale, amplifiedMaxHeight)
+	}
+
 	return int(height)
 }
 
+// Biome returns the biome at world-space x, z, including the shore biomes
+// (rivers, beaches and oceans) that depend on the terrain height.
+func (g *Generator) Biome(x, z int) *Biome {
+	return g.shoreBiome(x, z, g.SurfaceHeight(x, z), g.SurfaceHeight)
+}
+
+// shoreBiome refines the climate biome at (x, z) using the column's surface
+// height: river channels become River, deep water becomes Ocean and low land
+// within 4 blocks of water becomes Beach. heightAt returns the surface height
+// of nearby columns. Swamps and mushroom islands keep their own shores.
+func (g *Generator) shoreBiome(x, z, surfH int, heightAt func(x, z int) int) *Biome {
+	climate := BiomeAt(g.tempNoise, g.rainNoise, x, z)
+	if climate == BiomeSwampland || climate == BiomeMushroomIsland || surfH > WaterLevel+1 {
+		return climate
+	}
+
+	if surfH < WaterLevel {
+		// Same noise and thresholds as the river and lake carving in SurfaceHeight
+		rv := math.Abs(g.riverNoise.Noise2D(float64(x)*0.003, float64(z)*0.003))
+		if rv < 0.04 {
+			if climate.HasSnow {
+				return BiomeFrozenRiver
+			}
+			return BiomeRiver
+		}
+		if g.lakeNoise.Noise2D(float64(x)*0.01, float64(z)*0.01) > 0.82 {
+			return climate
+		}
+		if surfH < WaterLevel-4 {
+			if climate.HasSnow {
+				return BiomeFrozenOcean
+			}
+			return BiomeOcean
+		}
+	} else if climate == BiomeExtremeHills {
+		return climate // Stony shores
+	} else if heightAt(x+4, z) >= WaterLevel && heightAt(x-4, z) >= WaterLevel &&
+		heightAt(x, z+4) >= WaterLevel && heightAt(x, z-4) >= WaterLevel {
+		return climate // Low land, but no water nearby
+	}
+
+	if climate.HasSnow {
+		return BiomeColdBeach
+	}
+	return BiomeBeach
+}
+
+// clayBands returns the seeded sequence of hardened and stained clay layers
+// used for mesa strata.
+func clayBands(seed int64) [64]uint16 {
+	var bands [64]uint16
+	for i := range bands {
+		bands[i] = 172 << 4 // Hardened clay
+	}
+	colours := []uint16{1, 4, 12, 14, 0, 8} // orange, yellow, brown, red, white, light gray
+	r := rand.New(rand.NewSource(seed ^ 0x6D657361))
+	for y := r.Intn(4); y < len(bands); y += 2 + r.Intn(5) {
+		colour := 159<<4 | colours[r.Intn(len(colours))]
+		for n := 1 + r.Intn(3); n > 0 && y < len(bands); n-- {
+			bands[y] = colour
+			y++
+		}
+	}
+	return bands
+}
+
 // isCave returns true if the block at (x,y,z) should be carved into a cave.
 func (g *Generator) isCave(x, y, z int) bool {
 	// Simple 3D Perlin for caves, starting below surface
@@ -140,14 +245,89 @@ func (g *Generator) shouldPlaceTree(x, z int, biome *Biome) bool {
 	return randVal < effectiveDensity
 }
 
+// columnChance returns a deterministic pseudo-random value in [0, 1) for the
+// column at (x, z). Different salts give independent values.
+func columnChance(x, z int, salt int64) float64 {
+	hash := uint32(x*73856093 ^ z*191152071 ^ int(salt))
+	hash ^= hash >> 16
+	hash *= 0x85ebca6b
+	hash ^= hash >> 13
+	hash *= 0xc2b2ae35
+	hash ^= hash >> 16
+	return float64(hash) / (float64(math.MaxUint32) + 1)
+}
+
+// positionSeed mixes a world seed with a chunk or cell position and a salt
+// into a well-distributed seed, using the splitmix64 finalizer. Different
+// salts give independent seeds for the same position.
+func positionSeed(seed int64, x, z int64, salt int64) int64 {
+	const k1 int64 = -7046029254386353131 // splitmix64 step 1
+	const k2 int64 = -4265267296055464877 // splitmix64 step 2
+	h := seed ^ (x * k1) ^ (z * 7823434773480878946) ^ (salt * k2)
+	h ^= h >> 33
+	h *= k1
+	h ^= h >> 27
+	h *= k2
+	h ^= h >> 31
+	return h
+}
+
 // WaterLevel is the sea level.
 const WaterLevel = 62
 
+// DefaultLavaLevel is the highest Y at which caves fill with lava instead of air or water.
+const DefaultLavaLevel = 10
+
+// bedrockLayers is the number of layers at the bottom of the world that may
+// contain bedrock. Y 0 is always bedrock; higher layers are increasingly sparse.
+const bedrockLayers = 5
+
+// isBedrock returns true if (x, y, z) is part of the noisy bedrock floor.
+func (g *Generator) isBedrock(x, y, z int) bool {
+	if y == 0 {
+		return true
+	}
+	if y >= bedrockLayers {
+		return false
+	}
+	hash := uint32(x*73856093 ^ y*19349663 ^ z*83492791 ^ int(g.Seed))
+	hash ^= hash >> 16
+	hash *= 0x85ebca6b
+	hash ^= hash >> 13
+	return int(hash%bedrockLayers) >= y
+}
+
+// fillerDepth returns how many blocks of biome filler lie under the surface
+// block at (x, z) before the strata and stone begin: 3 to 5 blocks.
+func (g *Generator) fillerDepth(x, z int) int {
+	n := g.depthNoise.Noise2D(float64(x)*0.1, float64(z)*0.1) // -1..1
+	return 3 + int(math.Round(n+1))
+}
+
+// undergroundBlock returns the block at height y below the surface surfH of a
+// column: filler, then the biome's strata, then stone. In mesas the filler and
+// strata are replaced by the clay band at that height.
+func (g *Generator) undergroundBlock(biome *Biome, y, surfH, depth int) uint16 {
+	block := uint16(1 << 4) // Stone
+	switch {
+	case y >= surfH-depth:
+		block = biome.FillerBlock
+	case biome.StrataBlock != 0 && y >= surfH-2*depth:
+		block = biome.StrataBlock
+	default:
+		return block
+	}
+	if biome.ClayBands {
+		return g.clayBands[y%len(g.clayBands)]
+	}
+	return block
+}
+
 func (g *Generator) BlockAt(x, y, z int) uint16 {
 	if y < 0 || y > 255 {
 		return 0
 	}
-	if y == 0 {
+	if g.isBedrock(x, y, z) {
 		return 7 << 4 // bedrock
 	}
 
@@ -159,21 +339,21 @@ func (g *Generator) BlockAt(x, y, z int) uint16 {
 		return 0 // air
 	}
 
-	biome := BiomeAt(g.tempNoise, g.rainNoise, x, z)
+	biome := g.shoreBiome(x, z, surfH, g.SurfaceHeight)
 	if y < surfH {
-		return biome.FillerBlock
+		return g.undergroundBlock(biome, y, surfH, g.fillerDepth(x, z))
 	}
 	return biome.SurfaceBlock
 }
 
 // generateTrees places various tree types based on biome.
-func (g *Generator) generateTrees(chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16, heightCache *[24][24]int, chunkInVill bool) {
+func (g *Generator) generateTrees(chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16, heightCache *[24][24]int, columnBiomes *[256]*Biome, chunkInVill bool) {
 	for lx := 2; lx < 14; lx++ {
 		for lz := 2; lz < 14; lz++ {
 			wx := chunkX*16 + lx
 			wz := chunkZ*16 + lz
 
-			biome := BiomeAt(g.tempNoise, g.rainNoise, wx, wz)
+			biome := columnBiomes[lz*16+lx]
 
 			if biome.TreeDensity <= 0 || (chunkInVill && g.villageGen.IsInVillage(wx, wz)) {
 				continue
@@ -207,39 +387,34 @@ func (g *Generator) generateTrees(chunkX, chunkZ int, sections *[SectionsPerChun
 				continue
 			}
 
-			// Surface must be grass, snowy dirt, or sand
+			// Surface must be grass, snowy dirt, sand or mycelium
 			surfBlock := sections[surfaceY/16][(surfaceY%16*16+lz)*16+lx] >> 4
-			if surfBlock != 2 && surfBlock != 80 && surfBlock != 3 && surfBlock != 12 { // grass, snow, dirt, or sand
+			if surfBlock != 2 && surfBlock != 80 && surfBlock != 3 && surfBlock != 12 && surfBlock != 110 {
 				continue
 			}
 
 			// Determine tree type
-			treeType := 0 // 0: Oak, 1: Spruce, 2: Birch, 3: Jungle, 4: Dark Oak, 5: Cactus
-			if biome == BiomeDesert {
-				treeType = 5
-			} else if biome == BiomeJungle {
-				treeType = 3
-			} else if biome == BiomeDarkForest {
-				treeType = 4
-			} else if biome == BiomeForest {
-				// 30% chance of Birch in forests
-				if (wx*31+wz*17)%10 < 3 {
-					treeType = 2
-				}
-			} else if biome == BiomeExtremeHills || biome == BiomeSnowyTundra {
-				treeType = 1
+			treeType := biome.Tree
+			if biome.AltTreeChance > 0 && columnChance(wx, wz, g.Seed+7) < biome.AltTreeChance {
+				treeType = biome.AltTree
 			}
 
 			switch treeType {
-			case 1: // Spruce
+			case TreeSpruce:
 				g.buildSpruceTree(lx, surfaceY+1, lz, sections)
-			case 2: // Birch
+			case TreeBirch:
 				g.buildGenericTree(lx, surfaceY+1, lz, 2, sections)
-			case 3: // Jungle
+			case TreeJungle:
 				g.buildJungleTree(lx, surfaceY+1, lz, sections)
-			case 4: // Dark Oak
+			case TreeDarkOak:
 				g.buildDarkOakTree(lx, surfaceY+1, lz, sections)
-			case 5: // Desert (Cactus or Dead Bush)
+			case TreeAcacia:
+				g.buildAcaciaTree(lx, surfaceY+1, lz, sections)
+			case TreeSwampOak:
+				g.buildSwampTree(lx, surfaceY+1, lz, sections)
+			case TreeHugeMushroom:
+				g.buildHugeMushroom(lx, surfaceY+1, lz, (wx+wz)&1 == 0, sections)
+			case TreeCactus: // Cactus or Dead Bush
 				// 40% chance of Cactus, 60% of Dead Bush
 				if (wx*13+wz*7)%10 < 4 {
 					g.buildCactus(lx, surfaceY+1, lz, sections)
@@ -423,6 +598,167 @@ func (g *Generator) buildDarkOakTree(lx, y, lz int, sections *[SectionsPerChunk]
 	}
 }
 
+// setLeaves places leaves at local (lx, y, lz) if the spot is inside the chunk and empty.
+func setLeaves(lx, y, lz int, state uint16, sections *[SectionsPerChunk][ChunkSectionSize]uint16) bool {
+	if lx < 0 || lx >= 16 || lz < 0 || lz >= 16 || y < 0 || y > 255 {
+		return false
+	}
+	idx := ((y%16)*16+lz)*16 + lx
+	if sections[y/16][idx] != 0 {
+		return false
+	}
+	sections[y/16][idx] = state
+	return true
+}
+
+// buildAcaciaTree builds an acacia: a trunk that leans to one side partway up,
+// topped by a flat, wide canopy.
+func (g *Generator) buildAcaciaTree(lx, y, lz int, sections *[SectionsPerChunk][ChunkSectionSize]uint16) {
+	height := 5 + (lx*5+lz*11)%3
+	dirs := [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	dir := dirs[(lx*3+lz*7)%4]
+	bendAt := height - 3
+
+	// Trunk: straight up to bendAt, then one block sideways per block up
+	tx, tz := lx, lz
+	for dy := 0; dy < height; dy++ {
+		if dy > bendAt {
+			tx, tz = tx+dir[0], tz+dir[1]
+		}
+		if tx < 0 || tx >= 16 || tz < 0 || tz >= 16 {
+			break
+		}
+		ty := y + dy
+		sec, sy := ty/16, ty%16
+		current := sections[sec][(sy*16+tz)*16+tx] >> 4
+		if current == 0 || current == 31 || current == 18 || current == 161 {
+			sections[sec][(sy*16+tz)*16+tx] = 162 << 4 // Acacia log
+		}
+	}
+
+	// Flat canopy: a wide layer with a smaller layer on top
+	leafBase := uint16(161 << 4) // Acacia leaves
+	top := y + height
+	for dx := -3; dx <= 3; dx++ {
+		for dz := -3; dz <= 3; dz++ {
+			if abs(dx)+abs(dz) <= 4 {
+				setLeaves(tx+dx, top-1, tz+dz, leafBase, sections)
+			}
+			if abs(dx) <= 1 && abs(dz) <= 1 {
+				setLeaves(tx+dx, top, tz+dz, leafBase, sections)
+			}
+		}
+	}
+}
+
+// buildSwampTree builds a short oak with a wide canopy draped in vines.
+func (g *Generator) buildSwampTree(lx, y, lz int, sections *[SectionsPerChunk][ChunkSectionSize]uint16) {
+	height := 5 + (lx*7+lz*3)%3
+	trunkTop := y + height - 1
+	for ty := y; ty <= trunkTop; ty++ {
+		sec, sy := ty/16, ty%16
+		current := sections[sec][(sy*16+lz)*16+lx] >> 4
+		if current == 0 || current == 31 || current == 18 || current == 8 || current == 9 {
+			sections[sec][(sy*16+lz)*16+lx] = 17 << 4 // Oak log
+		}
+	}
+
+	leafBase := uint16(18 << 4) // Oak leaves
+	for dy := -2; dy <= 1; dy++ {
+		radius := 3
+		if dy == 1 {
+			radius = 2
+		}
+		ly := trunkTop + dy
+		for dx := -radius; dx <= radius; dx++ {
+			for dz := -radius; dz <= radius; dz++ {
+				if abs(dx) == radius && abs(dz) == radius {
+					continue
+				}
+				setLeaves(lx+dx, ly, lz+dz, leafBase, sections)
+			}
+		}
+	}
+
+	// Vines hang from the outer edge of the canopy. The metadata is the side of
+	// the vine block its supporting leaves are on: 1 south, 2 west, 4 north, 8 east.
+	sides := []struct {
+		dx, dz int
+		meta   uint16
+	}{{4, 0, 2}, {-4, 0, 8}, {0, 4, 4}, {0, -4, 1}}
+	for _, side := range sides {
+		for offset := -2; offset <= 2; offset++ {
+			vx, vz := lx+side.dx, lz+side.dz
+			if side.dx == 0 {
+				vx += offset
+			} else {
+				vz += offset
+			}
+			length := (vx*5 + vz*3 + offset) % 4
+			if length < 0 {
+				length = -length
+			}
+			for vy := trunkTop - 1; vy > trunkTop-1-length; vy-- {
+				if !setLeaves(vx, vy, vz, 106<<4|side.meta, sections) {
+					break
+				}
+			}
+		}
+	}
+}
+
+// buildHugeMushroom builds a brown (flat cap) or red (domed cap) huge mushroom.
+func (g *Generator) buildHugeMushroom(lx, y, lz int, brown bool, sections *[SectionsPerChunk][ChunkSectionSize]uint16) {
+	block := uint16(100) // Red huge mushroom
+	if brown {
+		block = 99
+	}
+	height := 5 + (lx*3+lz*5)%3
+	stem := block<<4 | 10 // Stem texture on the sides
+	cap := block<<4 | 14  // Cap texture on every side
+	for ty := y; ty < y+height; ty++ {
+		sec, sy := ty/16, ty%16
+		if sections[sec][(sy*16+lz)*16+lx] == 0 {
+			sections[sec][(sy*16+lz)*16+lx] = stem
+		}
+	}
+
+	top := y + height
+	if brown {
+		for dx := -3; dx <= 3; dx++ {
+			for dz := -3; dz <= 3; dz++ {
+				if abs(dx) == 3 && abs(dz) == 3 {
+					continue
+				}
+				setLeaves(lx+dx, top, lz+dz, cap, sections)
+			}
+		}
+		return
+	}
+	for dx := -1; dx <= 1; dx++ {
+		for dz := -1; dz <= 1; dz++ {
+			setLeaves(lx+dx, top, lz+dz, cap, sections)
+		}
+	}
+	for dy := 1; dy <= 3; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			for dz := -2; dz <= 2; dz++ {
+				if (abs(dx) == 2) != (abs(dz) == 2) {
+					setLeaves(lx+dx, top-dy, lz+dz, cap, sections)
+				}
+			}
+		}
+	}
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
 // buildCactus builds a 2-3 block high cactus.
 func (g *Generator) buildCactus(lx, y, lz int, sections *[SectionsPerChunk][ChunkSectionSize]uint16) {
 	height := 2 + (lx*7+lz*13)%2
@@ -433,11 +769,11 @@ func (g *Generator) buildCactus(lx, y, lz int, sections *[SectionsPerChunk][Chun
 }
 
 // generateBoulders places varied rock clusters.
-func (g *Generator) generateBoulders(chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16, heightCache *[24][24]int, chunkInVill bool) {
+func (g *Generator) generateBoulders(chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16, heightCache *[24][24]int, columnBiomes *[256]*Biome, chunkInVill bool) {
 	for lx := 1; lx < 15; lx++ {
 		for lz := 1; lz < 15; lz++ {
 			wx, wz := chunkX*16+lx, chunkZ*16+lz
-			biome := BiomeAt(g.tempNoise, g.rainNoise, wx, wz)
+			biome := columnBiomes[lz*16+lx]
 			if biome.BoulderDensity <= 0 || (chunkInVill && g.villageGen.IsInVillage(wx, wz)) {
 				continue
 			}
@@ -550,7 +886,22 @@ func (g *Generator) generateBoulders(chunkX, chunkZ int, sections *[SectionsPerC
 	}
 }
 
+// GenerateInternal implements ChunkGenerator.
 func (g *Generator) GenerateInternal(chunkX, chunkZ int) ([SectionsPerChunk][ChunkSectionSize]uint16, [256]byte) {
+	var features ChunkFeatures
+	return g.generate(chunkX, chunkZ, &features)
+}
+
+// GenerateWithFeatures implements FeatureGenerator.
+func (g *Generator) GenerateWithFeatures(chunkX, chunkZ int) ([SectionsPerChunk][ChunkSectionSize]uint16, [256]byte, ChunkFeatures) {
+	var features ChunkFeatures
+	sections, biomes := g.generate(chunkX, chunkZ, &features)
+	return sections, biomes, features
+}
+
+// generate produces a chunk column, adding its loot chests, spawners and
+// chest minecarts to features.
+func (g *Generator) generate(chunkX, chunkZ int, features *ChunkFeatures) ([SectionsPerChunk][ChunkSectionSize]uint16, [256]byte) {
 	var sections [SectionsPerChunk][ChunkSectionSize]uint16
 	var biomes [256]byte
 
@@ -598,13 +949,21 @@ func (g *Generator) GenerateInternal(chunkX, chunkZ int) ([SectionsPerChunk][Chu
 		}
 	}
 
+	// Shore biomes look at heights 4 blocks away, which are all in the cache
+	cachedHeight := func(wx, wz int) int {
+		return heightCache[wx-chunkX*16+4][wz-chunkZ*16+4]
+	}
+	var columnBiomes [256]*Biome
+
 	for lx := 0; lx < 16; lx++ {
 		for lz := 0; lz < 16; lz++ {
 			wx, wz := chunkX*16+lx, chunkZ*16+lz
-			biome := BiomeAt(g.tempNoise, g.rainNoise, wx, wz)
+			surfH := heightCache[lx+4][lz+4]
+			biome := g.shoreBiome(wx, wz, surfH, cachedHeight)
+			columnBiomes[lz*16+lx] = biome
 			biomes[lz*16+lx] = biome.ID
 
-			surfH := heightCache[lx+4][lz+4]
+			depth := g.fillerDepth(wx, wz)
 
 			// Fill from bottom up
 			for y := 0; y < 256; y++ {
@@ -612,7 +971,7 @@ func (g *Generator) GenerateInternal(chunkX, chunkZ int) ([SectionsPerChunk][Chu
 				sy := y % 16
 				idx := (sy*16+lz)*16 + lx
 
-				if y == 0 {
+				if g.isBedrock(wx, y, wz) {
 					sections[sec][idx] = 7 << 4 // Bedrock
 					continue
 				}
@@ -620,8 +979,10 @@ func (g *Generator) GenerateInternal(chunkX, chunkZ int) ([SectionsPerChunk][Chu
 				if y <= surfH {
 					// Check for caves (if active)
 					if y < surfH-2 && g.isCave(wx, y, wz) {
-						// Fill water if below sea level
-						if y <= WaterLevel {
+						// Lava pools at the bottom, water if below sea level
+						if y <= g.LavaLevel {
+							sections[sec][idx] = 11 << 4
+						} else if y <= WaterLevel {
 							sections[sec][idx] = 8 << 4
 						} else {
 							sections[sec][idx] = 0 // Air
@@ -630,7 +991,7 @@ func (g *Generator) GenerateInternal(chunkX, chunkZ int) ([SectionsPerChunk][Chu
 					}
 
 					if y < surfH {
-						sections[sec][idx] = biome.FillerBlock
+						sections[sec][idx] = g.undergroundBlock(biome, y, surfH, depth)
 					} else {
 						// Surface block logic
 						// If under water, use sand/gravel instead of grass
@@ -652,16 +1013,41 @@ func (g *Generator) GenerateInternal(chunkX, chunkZ int) ([SectionsPerChunk][Chu
 					break
 				}
 			}
+
+			// Lily pads float on still water in swamps
+			if surfH < WaterLevel && biome.LilyPadDensity > 0 && columnChance(wx, wz, g.Seed+111) < biome.LilyPadDensity {
+				y := WaterLevel + 1
+				sections[y/16][((y%16)*16+lz)*16+lx] = 111 << 4
+			}
 		}
 	}
 
-	// 2. Village placement
-	g.villageGen.generateVillage(chunkX, chunkZ, &sections)
+	// 2. Worm caves and ravines
+	g.carve(chunkX, chunkZ, &sections, &heightCache)
+
+	// 3. Ores and stone pockets
+	maxY := 0
+	for lx := 4; lx < 20; lx++ {
+		for lz := 4; lz < 20; lz++ {
+			if heightCache[lx][lz] > maxY {
+				maxY = heightCache[lx][lz]
+			}
+		}
+	}
+	g.generateOres(chunkX, chunkZ, maxY, &sections)
+
+	// 4. Underground structures
+	g.mineshafts.generateMineshafts(chunkX, chunkZ, &sections, features)
+	g.generateDungeons(chunkX, chunkZ, &sections, features)
+
+	// 5. Villages and biome structures
+	g.villageGen.generateVillage(chunkX, chunkZ, &sections, features)
+	g.generateTemples(chunkX, chunkZ, &sections, features)
 
-	// 3. Decorations
-	chunkInVill := g.villageGen.ChunkInVillage(chunkX, chunkZ)
-	g.generateBoulders(chunkX, chunkZ, &sections, &heightCache, chunkInVill)
-	g.generateTrees(chunkX, chunkZ, &sections, &heightCache, chunkInVill)
+	// 6. Decorations, kept out of villages and structures
+	chunkInVill := g.villageGen.ChunkInVillage(chunkX, chunkZ) || g.chunkHasTemple(chunkX, chunkZ)
+	g.generateBoulders(chunkX, chunkZ, &sections, &heightCache, &columnBiomes, chunkInVill)
+	g.generateTrees(chunkX, chunkZ, &sections, &heightCache, &columnBiomes, chunkInVill)
 
 	return sections, biomes
 }
@@ -113,3 +113,142 @@ func BenchmarkGenerator_SurfaceHeight(b *testing.B) {
 		gen.SurfaceHeight(i%1000, i/1000)
 	}
 }
+
+func TestAmplifiedTerrainIsTaller(t *testing.T) {
+	normal := NewGenerator(42)
+	amplified := NewAmplifiedGenerator(42)
+	higher := 0
+	for x := 0; x < 2000; x += 50 {
+		n, a := normal.SurfaceHeight(x, 0), amplified.SurfaceHeight(x, 0)
+		if a < n {
+			t.Fatalf("amplified height %d below default height %d at x=%d", a, n, x)
+		}
+		if a > amplifiedMaxHeight {
+			t.Fatalf("amplified height %d above cap %d", a, amplifiedMaxHeight)
+		}
+		if a > n {
+			higher++
+		}
+	}
+	if higher == 0 {
+		t.Error("amplified terrain is never taller than default terrain")
+	}
+	if amplified.LevelType() != "amplified" || normal.LevelType() != "default" {
+		t.Errorf("level types = %q, %q", normal.LevelType(), amplified.LevelType())
+	}
+}
+
+func TestBedrockNoise(t *testing.T) {
+	g := NewGenerator(999)
+
+	var counts [bedrockLayers + 1]int
+	for x := 0; x < 64; x++ {
+		for z := 0; z < 64; z++ {
+			for y := 0; y <= bedrockLayers; y++ {
+				if g.isBedrock(x, y, z) {
+					counts[y]++
+				}
+			}
+		}
+	}
+	if counts[0] != 64*64 {
+		t.Errorf("y=0 has %d bedrock blocks, want all %d", counts[0], 64*64)
+	}
+	for y := 1; y < bedrockLayers; y++ {
+		if counts[y] == 0 || counts[y] == 64*64 {
+			t.Errorf("y=%d has %d bedrock blocks, want a partial layer", y, counts[y])
+		}
+		if counts[y] > counts[y-1] {
+			t.Errorf("y=%d has more bedrock (%d) than the layer below (%d)", y, counts[y], counts[y-1])
+		}
+	}
+	if counts[bedrockLayers] != 0 {
+		t.Errorf("bedrock found at y=%d", bedrockLayers)
+	}
+}
+
+func TestUndergroundStrata(t *testing.T) {
+	g := NewGenerator(31337)
+
+	// Plains: a few blocks of dirt, then stone
+	if got := g.undergroundBlock(BiomePlains, 69, 70, 3); got != 3<<4 {
+		t.Errorf("plains just below surface = %d, want dirt", got)
+	}
+	if got := g.undergroundBlock(BiomePlains, 60, 70, 3); got != 1<<4 {
+		t.Errorf("plains deep underground = %d, want stone", got)
+	}
+	// Desert: sand, then sandstone, then stone
+	for _, tt := range []struct {
+		y    int
+		want uint16
+	}{{68, 12 << 4}, {65, 24 << 4}, {62, 1 << 4}} {
+		if got := g.undergroundBlock(BiomeDesert, tt.y, 70, 3); got != tt.want {
+			t.Errorf("desert at y=%d = %d, want %d", tt.y, got, tt.want)
+		}
+	}
+
+	for x := -200; x < 200; x += 37 {
+		for z := -200; z < 200; z += 37 {
+			surfH := g.SurfaceHeight(x, z)
+			if surfH < 20 {
+				continue
+			}
+			if got := g.BlockAt(x, surfH-12, z); got != 1<<4 {
+				t.Errorf("BlockAt(%d, %d, %d) = %d, want stone 12 blocks below the surface", x, surfH-12, z, got)
+			}
+		}
+	}
+}
+
+func TestCaveLavaPools(t *testing.T) {
+	g := NewGenerator(4242)
+	lavaLow := 0
+	for cx := 0; cx < 6; cx++ {
+		for cz := 0; cz < 6; cz++ {
+			sections, _ := g.GenerateInternal(cx, cz)
+			for sec := range sections {
+				for i, block := range sections[sec] {
+					if block>>4 != 11 {
+						continue
+					}
+					if y := sec*16 + i/256; y > g.LavaLevel {
+						t.Fatalf("lava above the lava level at y=%d in chunk (%d, %d)", y, cx, cz)
+					}
+					lavaLow++
+				}
+			}
+		}
+	}
+	if lavaLow == 0 {
+		t.Error("no lava generated in the caves of 36 chunks")
+	}
+}
+
+func TestBiomeTreeBuilders(t *testing.T) {
+	g := NewGenerator(1)
+	tests := []struct {
+		name  string
+		build func(sections *[SectionsPerChunk][ChunkSectionSize]uint16)
+		want  []uint16 // block IDs that must appear
+	}{
+		{"acacia", func(s *[SectionsPerChunk][ChunkSectionSize]uint16) { g.buildAcaciaTree(8, 70, 8, s) }, []uint16{162, 161}},
+		{"swamp oak", func(s *[SectionsPerChunk][ChunkSectionSize]uint16) { g.buildSwampTree(8, 70, 8, s) }, []uint16{17, 18, 106}},
+		{"brown mushroom", func(s *[SectionsPerChunk][ChunkSectionSize]uint16) { g.buildHugeMushroom(8, 70, 8, true, s) }, []uint16{99}},
+		{"red mushroom", func(s *[SectionsPerChunk][ChunkSectionSize]uint16) { g.buildHugeMushroom(8, 70, 8, false, s) }, []uint16{100}},
+	}
+	for _, tt := range tests {
+		var sections [SectionsPerChunk][ChunkSectionSize]uint16
+		tt.build(&sections)
+		found := make(map[uint16]bool)
+		for sec := range sections {
+			for _, block := range sections[sec] {
+				found[block>>4] = true
+			}
+		}
+		for _, id := range tt.want {
+			if !found[id] {
+				t.Errorf("%s tree has no block %d", tt.name, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package world
+
+import "math/rand"
+
+// ChestSize is the number of slots in a single chest.
+const ChestSize = 27
+
+// Names of the loot tables used by generated chests.
+const (
+	LootDungeon       = "dungeon"
+	LootMineshaft     = "mineshaft"
+	LootDesertPyramid = "desert_pyramid"
+	LootJungleTemple  = "jungle_temple"
+	LootWitchHut      = "witch_hut"
+	LootIgloo         = "igloo"
+
+	LootVillageBlacksmith = "village_blacksmith"
+	LootVillageHouse      = "village_house"
+	LootVillageChurch     = "village_church"
+)
+
+// LootEntry is one weighted choice of a loot table.
+type LootEntry struct {
+	ItemID   int16
+	Damage   int16
+	Min, Max int // Stack size range, inclusive
+	Weight   int
+}
+
+// LootTable fills a chest with a random number of weighted item stacks.
+type LootTable struct {
+	Rolls      int // Stacks always rolled
+	ExtraRolls int // Up to this many more stacks, chosen at random
+	Entries    []LootEntry
+}
+
+// ItemStack is one slot of generated loot. Empty slots have ItemID -1.
+type ItemStack struct {
+	ItemID int16
+	Damage int16
+	Count  byte
+}
+
+// LootTables are the tables generated chests refer to by name.
+// Items and weights match vanilla 1.8.
+var LootTables = map[string]*LootTable{
+	LootDungeon: {
+		Rolls: 8,
+		Entries: []LootEntry{
+			{ItemID: 329, Min: 1, Max: 1, Weight: 10}, // Saddle
+			{ItemID: 265, Min: 1, Max: 4, Weight: 10}, // Iron ingot
+			{ItemID: 297, Min: 1, Max: 1, Weight: 10}, // Bread
+			{ItemID: 296, Min: 1, Max: 4, Weight: 10}, // Wheat
+			{ItemID: 289, Min: 1, Max: 4, Weight: 10}, // Gunpowder
+			{ItemID: 287, Min: 1, Max: 4, Weight: 10}, // String
+			{ItemID: 325, Min: 1, Max: 1, Weight: 10}, // Bucket
+			{ItemID: 322, Min: 1, Max: 1, Weight: 1},  // Golden apple
+			{ItemID: 331, Min: 1, Max: 4, Weight: 10}, // Redstone
+			{ItemID: 2256, Min: 1, Max: 1, Weight: 4}, // Music disc "13"
+			{ItemID: 2257, Min: 1, Max: 1, Weight: 4}, // Music disc "cat"
+			{ItemID: 421, Min: 1, Max: 1, Weight: 10}, // Name tag
+			{ItemID: 418, Min: 1, Max: 1, Weight: 2},  // Gold horse armor
+			{ItemID: 417, Min: 1, Max: 1, Weight: 5},  // Iron horse armor
+			{ItemID: 419, Min: 1, Max: 1, Weight: 1},  // Diamond horse armor
+		},
+	},
+	LootMineshaft: {
+		Rolls:      3,
+		ExtraRolls: 3,
+		Entries: []LootEntry{
+			{ItemID: 265, Min: 1, Max: 5, Weight: 10},           // Iron ingot
+			{ItemID: 266, Min: 1, Max: 3, Weight: 5},            // Gold ingot
+			{ItemID: 331, Min: 4, Max: 9, Weight: 5},            // Redstone
+			{ItemID: 351, Damage: 4, Min: 4, Max: 9, Weight: 5}, // Lapis lazuli
+			{ItemID: 264, Min: 1, Max: 2, Weight: 3},            // Diamond
+			{ItemID: 263, Min: 3, Max: 8, Weight: 10},           // Coal
+			{ItemID: 297, Min: 1, Max: 3, Weight: 15},           // Bread
+			{ItemID: 257, Min: 1, Max: 1, Weight: 1},            // Iron pickaxe
+			{ItemID: 66, Min: 4, Max: 8, Weight: 1},             // Rail
+			{ItemID: 362, Min: 2, Max: 4, Weight: 10},           // Melon seeds
+			{ItemID: 361, Min: 2, Max: 4, Weight: 10},           // Pumpkin seeds
+			{ItemID: 329, Min: 1, Max: 1, Weight: 3},            // Saddle
+			{ItemID: 417, Min: 1, Max: 1, Weight: 1},            // Iron horse armor
+		},
+	},
+	LootDesertPyramid: {
+		Rolls:      2,
+		ExtraRolls: 4,
+		Entries: []LootEntry{
+			{ItemID: 264, Min: 1, Max: 3, Weight: 5},            // Diamond
+			{ItemID: 265, Min: 1, Max: 5, Weight: 15},           // Iron ingot
+			{ItemID: 266, Min: 2, Max: 7, Weight: 15},           // Gold ingot
+			{ItemID: 388, Min: 1, Max: 3, Weight: 15},           // Emerald
+			{ItemID: 352, Min: 4, Max: 6, Weight: 25},           // Bone
+			{ItemID: 375, Min: 1, Max: 3, Weight: 25},           // Spider eye
+			{ItemID: 367, Min: 3, Max: 7, Weight: 25},           // Rotten flesh
+			{ItemID: 329, Min: 1, Max: 1, Weight: 20},           // Saddle
+			{ItemID: 417, Min: 1, Max: 1, Weight: 15},           // Iron horse armor
+			{ItemID: 418, Min: 1, Max: 1, Weight: 10},           // Gold horse armor
+			{ItemID: 419, Min: 1, Max: 1, Weight: 5},            // Diamond horse armor
+			{ItemID: 322, Min: 1, Max: 1, Weight: 20},           // Golden apple
+			{ItemID: 322, Damage: 1, Min: 1, Max: 1, Weight: 2}, // Enchanted golden apple
+		},
+	},
+	LootJungleTemple: {
+		Rolls:      2,
+		ExtraRolls: 4,
+		Entries: []LootEntry{
+			{ItemID: 264, Min: 1, Max: 3, Weight: 3},  // Diamond
+			{ItemID: 265, Min: 1, Max: 5, Weight: 10}, // Iron ingot
+			{ItemID: 266, Min: 2, Max: 7, Weight: 15}, // Gold ingot
+			{ItemID: 388, Min: 1, Max: 3, Weight: 2},  // Emerald
+			{ItemID: 352, Min: 4, Max: 6, Weight: 20}, // Bone
+			{ItemID: 367, Min: 3, Max: 7, Weight: 16}, // Rotten flesh
+			{ItemID: 329, Min: 1, Max: 1, Weight: 3},  // Saddle
+			{ItemID: 417, Min: 1, Max: 1, Weight: 1},  // Iron horse armor
+			{ItemID: 418, Min: 1, Max: 1, Weight: 1},  // Gold horse armor
+			{ItemID: 419, Min: 1, Max: 1, Weight: 1},  // Diamond horse armor
+		},
+	},
+	// Vanilla witch huts have no chest; this one holds brewing ingredients.
+	LootWitchHut: {
+		Rolls:      3,
+		ExtraRolls: 3,
+		Entries: []LootEntry{
+			{ItemID: 374, Min: 1, Max: 3, Weight: 15}, // Glass bottle
+			{ItemID: 375, Min: 1, Max: 2, Weight: 10}, // Spider eye
+			{ItemID: 331, Min: 1, Max: 4, Weight: 10}, // Redstone
+			{ItemID: 348, Min: 1, Max: 4, Weight: 10}, // Glowstone dust
+			{ItemID: 353, Min: 1, Max: 3, Weight: 10}, // Sugar
+			{ItemID: 289, Min: 1, Max: 2, Weight: 10}, // Gunpowder
+			{ItemID: 372, Min: 1, Max: 3, Weight: 5},  // Nether wart
+			{ItemID: 280, Min: 1, Max: 2, Weight: 5},  // Stick
+			{ItemID: 376, Min: 1, Max: 1, Weight: 3},  // Fermented spider eye
+		},
+	},
+	LootIgloo: {
+		Rolls:      2,
+		ExtraRolls: 6,
+		Entries: []LootEntry{
+			{ItemID: 260, Min: 1, Max: 3, Weight: 15}, // Apple
+			{ItemID: 263, Min: 1, Max: 4, Weight: 15}, // Coal
+			{ItemID: 371, Min: 1, Max: 3, Weight: 10}, // Gold nugget
+			{ItemID: 275, Min: 1, Max: 1, Weight: 2},  // Stone axe
+			{ItemID: 367, Min: 1, Max: 1, Weight: 10}, // Rotten flesh
+			{ItemID: 388, Min: 1, Max: 1, Weight: 1},  // Emerald
+			{ItemID: 296, Min: 2, Max: 3, Weight: 10}, // Wheat
+			{ItemID: 322, Min: 1, Max: 1, Weight: 1},  // Golden apple
+		},
+	},
+	LootVillageBlacksmith: {
+		Rolls:      3,
+		ExtraRolls: 5,
+		Entries: []LootEntry{
+			{ItemID: 264, Min: 1, Max: 3, Weight: 3},  // Diamond
+			{ItemID: 265, Min: 1, Max: 5, Weight: 10}, // Iron ingot
+			{ItemID: 266, Min: 1, Max: 3, Weight: 5},  // Gold ingot
+			{ItemID: 297, Min: 1, Max: 3, Weight: 15}, // Bread
+			{ItemID: 260, Min: 1, Max: 3, Weight: 15}, // Apple
+			{ItemID: 257, Min: 1, Max: 1, Weight: 5},  // Iron pickaxe
+			{ItemID: 267, Min: 1, Max: 1, Weight: 5},  // Iron sword
+			{ItemID: 307, Min: 1, Max: 1, Weight: 5},  // Iron chestplate
+			{ItemID: 306, Min: 1, Max: 1, Weight: 5},  // Iron helmet
+			{ItemID: 308, Min: 1, Max: 1, Weight: 5},  // Iron leggings
+			{ItemID: 309, Min: 1, Max: 1, Weight: 5},  // Iron boots
+			{ItemID: 49, Min: 3, Max: 7, Weight: 5},   // Obsidian
+			{ItemID: 6, Min: 3, Max: 7, Weight: 5},    // Oak sapling
+			{ItemID: 329, Min: 1, Max: 1, Weight: 3},  // Saddle
+			{ItemID: 417, Min: 1, Max: 1, Weight: 1},  // Iron horse armor
+			{ItemID: 418, Min: 1, Max: 1, Weight: 1},  // Gold horse armor
+			{ItemID: 419, Min: 1, Max: 1, Weight: 1},  // Diamond horse armor
+		},
+	},
+	// Vanilla village houses have no chest; this one holds food and farming supplies.
+	LootVillageHouse: {
+		Rolls:      2,
+		ExtraRolls: 4,
+		Entries: []LootEntry{
+			{ItemID: 297, Min: 1, Max: 4, Weight: 15}, // Bread
+			{ItemID: 260, Min: 1, Max: 5, Weight: 15}, // Apple
+			{ItemID: 296, Min: 2, Max: 8, Weight: 10}, // Wheat
+			{ItemID: 295, Min: 2, Max: 6, Weight: 10}, // Wheat seeds
+			{ItemID: 391, Min: 1, Max: 4, Weight: 10}, // Carrot
+			{ItemID: 392, Min: 1, Max: 4, Weight: 10}, // Potato
+			{ItemID: 263, Min: 1, Max: 4, Weight: 10}, // Coal
+			{ItemID: 280, Min: 2, Max: 6, Weight: 10}, // Stick
+			{ItemID: 334, Min: 1, Max: 3, Weight: 5},  // Leather
+			{ItemID: 290, Min: 1, Max: 1, Weight: 3},  // Wooden hoe
+			{ItemID: 388, Min: 1, Max: 2, Weight: 2},  // Emerald
+		},
+	},
+	// Vanilla village churches have no chest; this one holds a cleric's wares.
+	LootVillageChurch: {
+		Rolls:      2,
+		ExtraRolls: 4,
+		Entries: []LootEntry{
+			{ItemID: 331, Min: 1, Max: 4, Weight: 10},            // Redstone
+			{ItemID: 348, Min: 1, Max: 4, Weight: 10},            // Glowstone dust
+			{ItemID: 351, Damage: 4, Min: 1, Max: 4, Weight: 10}, // Lapis lazuli
+			{ItemID: 367, Min: 1, Max: 4, Weight: 10},            // Rotten flesh
+			{ItemID: 374, Min: 1, Max: 3, Weight: 10},            // Glass bottle
+			{ItemID: 340, Min: 1, Max: 2, Weight: 8},             // Book
+			{ItemID: 339, Min: 2, Max: 6, Weight: 8},             // Paper
+			{ItemID: 371, Min: 1, Max: 5, Weight: 8},             // Gold nugget
+			{ItemID: 381, Min: 1, Max: 1, Weight: 2},             // Eye of ender
+			{ItemID: 384, Min: 1, Max: 3, Weight: 2},             // Bottle o' enchanting
+			{ItemID: 388, Min: 1, Max: 2, Weight: 3},             // Emerald
+		},
+	},
+}
+
+// Fill rolls the contents of a chest from seed. Each roll puts a stack in a
+// random slot, replacing whatever an earlier roll put there, as in vanilla.
+func (t *LootTable) Fill(seed int64) [ChestSize]ItemStack {
+	var slots [ChestSize]ItemStack
+	for i := range slots {
+		slots[i].ItemID = -1
+	}
+
+	total := 0
+	for _, e := range t.Entries {
+		total += e.Weight
+	}
+	if total == 0 {
+		return slots
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	rolls := t.Rolls
+	if t.ExtraRolls > 0 {
+		rolls += r.Intn(t.ExtraRolls + 1)
+	}
+	for i := 0; i < rolls; i++ {
+		pick := r.Intn(total)
+		var entry LootEntry
+		for _, e := range t.Entries {
+			if pick < e.Weight {
+				entry = e
+				break
+			}
+			pick -= e.Weight
+		}
+		count := entry.Min + r.Intn(entry.Max-entry.Min+1)
+		slots[r.Intn(ChestSize)] = ItemStack{ItemID: entry.ItemID, Damage: entry.Damage, Count: byte(count)}
+	}
+	return slots
+}
@@ -0,0 +1,121 @@
+package world
+
+import (
+	"image"
+	"image/color"
+)
+
+// biomeMapColors are the colours biomes are drawn with on rendered maps,
+// keyed by biome ID.
+var biomeMapColors = map[byte]color.RGBA{
+	BiomeOcean.ID:          {0x00, 0x00, 0x70, 0xff},
+	BiomePlains.ID:         {0x8d, 0xb3, 0x60, 0xff},
+	BiomeDesert.ID:         {0xfa, 0x94, 0x18, 0xff},
+	BiomeExtremeHills.ID:   {0x60, 0x60, 0x60, 0xff},
+	BiomeForest.ID:         {0x05, 0x66, 0x21, 0xff},
+	BiomeJungle.ID:         {0x53, 0x7b, 0x09, 0xff},
+	BiomeDarkForest.ID:     {0x40, 0x51, 0x1a, 0xff},
+	BiomeSnowyTundra.ID:    {0xff, 0xff, 0xff, 0xff},
+	BiomeTaiga.ID:          {0x0b, 0x66, 0x59, 0xff},
+	BiomeSavanna.ID:        {0xbd, 0xb2, 0x5f, 0xff},
+	BiomeSwampland.ID:      {0x07, 0xf9, 0xb2, 0xff},
+	BiomeMesa.ID:           {0xd9, 0x45, 0x15, 0xff},
+	BiomeMushroomIsland.ID: {0xff, 0x00, 0xff, 0xff},
+	BiomeBirchForest.ID:    {0x30, 0x74, 0x44, 0xff},
+	BiomeRiver.ID:          {0x00, 0x00, 0xff, 0xff},
+	BiomeFrozenRiver.ID:    {0xa0, 0xa0, 0xff, 0xff},
+	BiomeFrozenOcean.ID:    {0x90, 0x90, 0xa0, 0xff},
+	BiomeBeach.ID:          {0xfa, 0xde, 0x55, 0xff},
+	BiomeColdBeach.ID:      {0xfa, 0xf0, 0xc0, 0xff},
+}
+
+// Colours of water and village footprints on rendered maps.
+var (
+	mapWaterColor    = color.RGBA{0x30, 0x50, 0xd0, 0xff}
+	mapRoadColor     = color.RGBA{0x94, 0x7a, 0x4a, 0xff}
+	mapBuildingColor = color.RGBA{0x5c, 0x3a, 0x1e, 0xff}
+	mapFarmColor     = color.RGBA{0xc8, 0xc0, 0x40, 0xff}
+	mapWellColor     = color.RGBA{0x80, 0x80, 0x80, 0xff}
+)
+
+// RenderMap draws a top-down map of the width x height pixel area whose top
+// left corner is world column (minX, minZ), one pixel per scale blocks. Land
+// is coloured by biome and shaded by surface height, water is blue and
+// darker the deeper it is, and villages show their roads, buildings, farms
+// and well.
+func (g *Generator) RenderMap(minX, minZ, width, height, scale int) *image.RGBA {
+	if scale < 1 {
+		scale = 1
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for pz := 0; pz < height; pz++ {
+		for px := 0; px < width; px++ {
+			x, z := minX+px*scale, minZ+pz*scale
+			h := g.SurfaceHeight(x, z)
+			biome := g.shoreBiome(x, z, h, g.SurfaceHeight)
+			var c color.RGBA
+			if h < WaterLevel {
+				c = shadeMapColor(mapWaterColor, -float64(WaterLevel-h)/40)
+			} else {
+				c = shadeMapColor(biomeMapColors[biome.ID], float64(h-WaterLevel-16)/96)
+			}
+			img.SetRGBA(px, pz, c)
+		}
+	}
+
+	maxX, maxZ := minX+width*scale-1, minZ+height*scale-1
+	v := g.villageGen
+	v.sites.SitesNear(minX, minZ, maxX, maxZ, villageCellSize, func(site villageSite) {
+		plan := v.planVillage(site.wx, site.wz)
+		bMinX, bMinZ, bMaxX, bMaxZ := plan.footprint()
+		for pz := divFloor(bMinZ-minZ, scale); pz <= divFloor(bMaxZ-minZ, scale); pz++ {
+			for px := divFloor(bMinX-minX, scale); px <= divFloor(bMaxX-minX, scale); px++ {
+				if px < 0 || pz < 0 || px >= width || pz >= height {
+					continue
+				}
+				if c, ok := plan.mapColor(v, minX+px*scale, minZ+pz*scale); ok {
+					img.SetRGBA(px, pz, c)
+				}
+			}
+		}
+	})
+	return img
+}
+
+// mapColor returns the colour of the village footprint at (x, z), or false
+// if the column is not part of the village.
+func (p *VillagePlan) mapColor(v *VillageGrid, x, z int) (color.RGBA, bool) {
+	inside := func(b bbox) bool {
+		return x >= b.minX && x <= b.maxX && z >= b.minZ && z <= b.maxZ
+	}
+	if abs(x-p.VX) <= 2 && abs(z-p.VZ) <= 2 {
+		return mapWellColor, true
+	}
+	for _, b := range p.Buildings {
+		if inside(b.Box) {
+			return mapBuildingColor, true
+		}
+	}
+	for _, f := range p.Farms {
+		if inside(f.Box) {
+			return mapFarmColor, true
+		}
+	}
+	if v.isNearRoad(x, z, p.Roads, 1) {
+		return mapRoadColor, true
+	}
+	return color.RGBA{}, false
+}
+
+// shadeMapColor lightens c by amount (0 to 1) or darkens it when amount is
+// negative.
+func shadeMapColor(c color.RGBA, amount float64) color.RGBA {
+	amount = max(-0.6, min(0.6, amount))
+	shade := func(v uint8) uint8 {
+		if amount < 0 {
+			return uint8(float64(v) * (1 + amount))
+		}
+		return uint8(float64(v) + float64(255-v)*amount)
+	}
+	return color.RGBA{shade(c.R), shade(c.G), shade(c.B), c.A}
+}
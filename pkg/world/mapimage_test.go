@@ -0,0 +1,38 @@
+package world
+
+import "testing"
+
+func TestRenderMap(t *testing.T) {
+	g := NewGenerator(12345)
+	vx, vz, ok := g.Locate("village", 0, 0, 2000)
+	if !ok {
+		t.Fatal("no village to render")
+	}
+
+	img := g.RenderMap(vx-64, vz-64, 64, 64, 2)
+	if b := img.Bounds(); b.Dx() != 64 || b.Dy() != 64 {
+		t.Fatalf("image is %dx%d, want 64x64", b.Dx(), b.Dy())
+	}
+	if c := img.RGBAAt(32, 32); c != mapWellColor {
+		t.Errorf("well pixel = %v, want %v", c, mapWellColor)
+	}
+	buildings := 0
+	for pz := 0; pz < 64; pz++ {
+		for px := 0; px < 64; px++ {
+			if img.RGBAAt(px, pz) == mapBuildingColor {
+				buildings++
+			}
+		}
+	}
+	if buildings == 0 {
+		t.Error("no village buildings drawn")
+	}
+
+	// Rendering is deterministic
+	again := g.RenderMap(vx-64, vz-64, 64, 64, 2)
+	for i := range img.Pix {
+		if img.Pix[i] != again.Pix[i] {
+			t.Fatal("rendering the same area twice gave different images")
+		}
+	}
+}
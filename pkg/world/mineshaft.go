@@ -0,0 +1,359 @@
+package world
+
+// mineshaftCellSize is the side of the grid cells mineshafts are placed in.
+// Each cell holds at most one mineshaft.
+const mineshaftCellSize = 256
+
+// Mineshaft layout limits: pieces start no further than mineshaftRadius blocks
+// from the start room and no more than maxShaftDepth pieces away from it.
+const (
+	mineshaftRadius = 80
+	maxShaftDepth   = 8
+)
+
+// mineshaftSalt separates the mineshaft random stream from other per-cell streams.
+const mineshaftSalt = 0x6D696E65
+
+type shaftKind int
+
+const (
+	shaftRoom shaftKind = iota
+	shaftCorridor
+	shaftCrossing
+	shaftStairs
+)
+
+// box3 is an inclusive block bounding box.
+type box3 struct {
+	minX, minY, minZ, maxX, maxY, maxZ int
+}
+
+func (b box3) intersects(o box3) bool {
+	return b.minX <= o.maxX && b.maxX >= o.minX && b.minY <= o.maxY && b.maxY >= o.minY && b.minZ <= o.maxZ && b.maxZ >= o.minZ
+}
+
+func (b box3) intersectsChunk(chunkX, chunkZ int) bool {
+	return b.minX <= chunkX*16+15 && b.maxX >= chunkX*16 && b.minZ <= chunkZ*16+15 && b.maxZ >= chunkZ*16
+}
+
+func (b box3) union(o box3) box3 {
+	return box3{min(b.minX, o.minX), min(b.minY, o.minY), min(b.minZ, o.minZ), max(b.maxX, o.maxX), max(b.maxY, o.maxY), max(b.maxZ, o.maxZ)}
+}
+
+// shaftPiece is one room, corridor, crossing or staircase of a mineshaft.
+// Corridor-like pieces are three blocks wide and run from (x, y, z), the
+// centre of their entrance at foot level, in direction dir for length blocks.
+type shaftPiece struct {
+	kind    shaftKind
+	box     box3
+	x, y, z int
+	dir     int // 0=+Z, 1=-Z, 2=+X, 3=-X
+	length  int
+	seed    int64 // Seeds rails, cobwebs and minecarts so every chunk agrees
+	spider  bool  // Corridor full of cobwebs around a cave spider spawner
+}
+
+// shaftDirs are the unit vectors of the piece directions.
+var shaftDirs = [4][2]int{{0, 1}, {0, -1}, {1, 0}, {-1, 0}}
+
+// shaftDir returns the direction with unit vector (dx, dz).
+func shaftDir(dx, dz int) int {
+	for d, v := range shaftDirs {
+		if v[0] == dx && v[1] == dz {
+			return d
+		}
+	}
+	return 0
+}
+
+// at converts a position along the piece (a) and across it (c) to world x, z.
+func (p *shaftPiece) at(a, c int) (int, int) {
+	dx, dz := shaftDirs[p.dir][0], shaftDirs[p.dir][1]
+	return p.x + a*dx + c*dz, p.z + a*dz - c*dx
+}
+
+// side returns the direction pointing across the piece towards positive c
+// when sign is 1, or negative c when sign is -1.
+func (p *shaftPiece) side(sign int) int {
+	dx, dz := shaftDirs[p.dir][0], shaftDirs[p.dir][1]
+	return shaftDir(dz*sign, -dx*sign)
+}
+
+// MineshaftPlan is the deterministic layout of one abandoned mineshaft.
+type MineshaftPlan struct {
+	X, Y, Z int // Centre of the start room floor
+	pieces  []shaftPiece
+	bounds  box3
+}
+
+// MineshaftGrid places abandoned mineshafts deterministically per seed, at
+// most one per grid cell, and builds the parts of them that fall in a chunk.
+type MineshaftGrid struct {
+	heightFunc func(x, z int) int
+	grid       *StructureGrid[*MineshaftPlan]
+}
+
+// NewMineshaftGrid creates a MineshaftGrid for the given world seed.
+// heightFunc keeps start rooms well below the surface.
+func NewMineshaftGrid(seed int64, heightFunc func(x, z int) int) *MineshaftGrid {
+	m := &MineshaftGrid{heightFunc: heightFunc}
+	m.grid = NewStructureGrid(seed, mineshaftSalt, mineshaftCellSize, func(cellX, cellZ int) (*MineshaftPlan, bool) {
+		plan := m.calculatePlan(cellX, cellZ)
+		return plan, plan != nil
+	})
+	return m
+}
+
+// plan returns the mineshaft of grid cell (cellX, cellZ), or nil if it has none.
+func (m *MineshaftGrid) plan(cellX, cellZ int) *MineshaftPlan {
+	plan, _ := m.grid.Site(cellX, cellZ)
+	return plan
+}
+
+func (m *MineshaftGrid) calculatePlan(cellX, cellZ int) *MineshaftPlan {
+	r := newCarverRand(m.grid.CellSeed(int64(cellX), int64(cellZ)))
+	// Half of the cells get a mineshaft
+	if r.Intn(2) != 0 {
+		return nil
+	}
+	x := cellX*mineshaftCellSize + 32 + r.Intn(mineshaftCellSize-64)
+	z := cellZ*mineshaftCellSize + 32 + r.Intn(mineshaftCellSize-64)
+	top := min(m.heightFunc(x, z)-12, 48)
+	if top < 16 {
+		return nil // Under deep ocean
+	}
+	y := 10 + r.Intn(top-10)
+
+	sx, sz := 7+r.Intn(6), 7+r.Intn(6)
+	height := 4 + r.Intn(3)
+	room := shaftPiece{
+		kind: shaftRoom,
+		box:  box3{x - sx/2, y, z - sz/2, x - sx/2 + sx - 1, y + height, z - sz/2 + sz - 1},
+		x:    x, y: y, z: z,
+	}
+	plan := &MineshaftPlan{X: x, Y: y, Z: z, pieces: []shaftPiece{room}, bounds: room.box}
+
+	// Corridors leave the room at random points along each wall
+	b := room.box
+	walls := [4]struct{ x, z, dx, dz, dir, length int }{
+		{b.minX, b.maxZ + 1, 1, 0, 0, sx},
+		{b.minX, b.minZ - 1, 1, 0, 1, sx},
+		{b.maxX + 1, b.minZ, 0, 1, 2, sz},
+		{b.minX - 1, b.minZ, 0, 1, 3, sz},
+	}
+	for _, w := range walls {
+		for pos := 0; ; pos += 4 {
+			pos += r.Intn(w.length)
+			if pos+3 > w.length {
+				break
+			}
+			m.addPiece(plan, r, w.x+(pos+1)*w.dx, y+1, w.z+(pos+1)*w.dz, w.dir, 1)
+		}
+	}
+	return plan
+}
+
+// addPiece adds a corridor, crossing or staircase entered at (x, y, z) heading
+// in direction dir, then the pieces leading on from it. Pieces that would run
+// into an existing piece are dropped, leaving a dead end.
+func (m *MineshaftGrid) addPiece(plan *MineshaftPlan, r *carverRand, x, y, z, dir, depth int) {
+	if depth > maxShaftDepth || abs(x-plan.X) > mineshaftRadius || abs(z-plan.Z) > mineshaftRadius {
+		return
+	}
+
+	p := shaftPiece{x: x, y: y, z: z, dir: dir}
+	switch k := r.Intn(100); {
+	case k >= 80:
+		p.kind, p.length = shaftCrossing, 3
+	case k >= 70 && y-4 > bedrockLayers+2:
+		p.kind, p.length = shaftStairs, 8
+	default:
+		p.kind, p.length = shaftCorridor, 5*(r.Intn(3)+2)
+	}
+
+	x0, z0 := p.at(0, -1)
+	x1, z1 := p.at(p.length-1, 1)
+	p.box = box3{min(x0, x1), y, min(z0, z1), max(x0, x1), y + 2, max(z0, z1)}
+	if p.kind == shaftStairs {
+		p.box.minY = y - 4
+	}
+	for i := range plan.pieces {
+		if plan.pieces[i].box.intersects(p.box) {
+			return
+		}
+	}
+	p.seed = r.Int63()
+	p.spider = p.kind == shaftCorridor && r.Intn(23) == 0
+	plan.pieces = append(plan.pieces, p)
+	plan.bounds = plan.bounds.union(p.box)
+
+	switch p.kind {
+	case shaftCorridor:
+		switch r.Intn(4) {
+		case 0, 1:
+			nx, nz := p.at(p.length, 0)
+			m.addPiece(plan, r, nx, y, nz, dir, depth+1)
+		case 2:
+			nx, nz := p.at(p.length-2, -2)
+			m.addPiece(plan, r, nx, y, nz, p.side(-1), depth+1)
+		case 3:
+			nx, nz := p.at(p.length-2, 2)
+			m.addPiece(plan, r, nx, y, nz, p.side(1), depth+1)
+		}
+	case shaftCrossing:
+		nx, nz := p.at(3, 0)
+		m.addPiece(plan, r, nx, y, nz, dir, depth+1)
+		nx, nz = p.at(1, -2)
+		m.addPiece(plan, r, nx, y, nz, p.side(-1), depth+1)
+		nx, nz = p.at(1, 2)
+		m.addPiece(plan, r, nx, y, nz, p.side(1), depth+1)
+	case shaftStairs:
+		nx, nz := p.at(p.length, 0)
+		m.addPiece(plan, r, nx, y-4, nz, dir, depth+1)
+	}
+}
+
+// generateMineshafts builds the parts of nearby mineshafts that fall inside
+// chunk (chunkX, chunkZ), and reports their spawners and chest minecarts.
+func (m *MineshaftGrid) generateMineshafts(chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16, features *ChunkFeatures) {
+	cellX := divFloor(chunkX*16, mineshaftCellSize)
+	cellZ := divFloor(chunkZ*16, mineshaftCellSize)
+	for cx := cellX - 1; cx <= cellX+1; cx++ {
+		for cz := cellZ - 1; cz <= cellZ+1; cz++ {
+			plan := m.plan(cx, cz)
+			if plan == nil || !plan.bounds.intersectsChunk(chunkX, chunkZ) {
+				continue
+			}
+			for i := range plan.pieces {
+				if plan.pieces[i].box.intersectsChunk(chunkX, chunkZ) {
+					plan.pieces[i].build(chunkX, chunkZ, sections, features)
+				}
+			}
+		}
+	}
+}
+
+// build writes the part of the piece inside chunk (chunkX, chunkZ). The same
+// random numbers are drawn whichever chunk is built, so pieces line up.
+func (p *shaftPiece) build(chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16, features *ChunkFeatures) {
+	minX, minZ := chunkX*16, chunkZ*16
+	inChunk := func(x, y, z int) bool {
+		return x >= minX && x < minX+16 && z >= minZ && z < minZ+16 && y > 0 && y < 256
+	}
+	get := func(x, y, z int) uint16 {
+		if !inChunk(x, y, z) {
+			return 0
+		}
+		return sections[y/16][((y%16)*16+z-minZ)*16+x-minX]
+	}
+	// Mineshafts cut through everything but bedrock and liquids
+	place := func(x, y, z int, state uint16) {
+		if !inChunk(x, y, z) {
+			return
+		}
+		if id := get(x, y, z) >> 4; id == 7 || (id >= 8 && id <= 11) {
+			return
+		}
+		setBlock(x-minX, y, z-minZ, state, sections)
+	}
+	// The floor under a corridor is bridged with planks where it is missing
+	bridge := func(x, y, z int) {
+		if inChunk(x, y, z) && get(x, y, z) == 0 {
+			setBlock(x-minX, y, z-minZ, 5<<4, sections)
+		}
+	}
+
+	r := newCarverRand(p.seed)
+	switch p.kind {
+	case shaftRoom:
+		for x := p.box.minX; x <= p.box.maxX; x++ {
+			for z := p.box.minZ; z <= p.box.maxZ; z++ {
+				place(x, p.box.minY, z, 3<<4) // Dirt floor
+				for y := p.box.minY + 1; y <= p.box.maxY; y++ {
+					place(x, y, z, 0)
+				}
+			}
+		}
+
+	case shaftCrossing:
+		for a := 0; a < p.length; a++ {
+			for c := -1; c <= 1; c++ {
+				x, z := p.at(a, c)
+				for h := 0; h < 3; h++ {
+					place(x, p.y+h, z, 0)
+				}
+				bridge(x, p.y-1, z)
+			}
+		}
+
+	case shaftStairs:
+		for a := 0; a < p.length; a++ {
+			floor := 0
+			if a >= 2 {
+				floor = -min(a-1, 4)
+			}
+			for c := -1; c <= 1; c++ {
+				x, z := p.at(a, c)
+				for h := floor; h <= min(floor+3, 2); h++ {
+					place(x, p.y+h, z, 0)
+				}
+			}
+		}
+
+	case shaftCorridor:
+		var rail uint16 = 66 << 4 // North-south
+		if p.dir >= 2 {
+			rail |= 1 // East-west
+		}
+		spawnerAt := -1
+		if p.spider {
+			spawnerAt = p.length/2 + r.Intn(2)
+		}
+		for a := 0; a < p.length; a++ {
+			support := a%5 == 2
+			for c := -1; c <= 1; c++ {
+				x, z := p.at(a, c)
+				for h := 0; h < 3; h++ {
+					web := (p.spider && r.Float64() < 0.6) || (h == 2 && r.Float64() < 0.05)
+					switch {
+					case support && c != 0 && h < 2:
+						place(x, p.y+h, z, 85<<4) // Fence post
+					case support && h == 2:
+						place(x, p.y+h, z, 5<<4) // Plank beam
+					case web:
+						place(x, p.y+h, z, 30<<4)
+					default:
+						place(x, p.y+h, z, 0)
+					}
+				}
+				bridge(x, p.y-1, z)
+			}
+
+			x, z := p.at(a, 0)
+			hasRail := r.Intn(4) != 0
+			switch {
+			case a == spawnerAt:
+				place(x, p.y, z, 52<<4)
+				if inChunk(x, p.y, z) && get(x, p.y, z) == 52<<4 {
+					features.Spawners = append(features.Spawners, MobSpawner{Pos: BlockPos{int32(x), int32(p.y), int32(z)}, Mob: "CaveSpider"})
+				}
+			case hasRail && get(x, p.y-1, z) != 0 && get(x, p.y, z) != 85<<4:
+				place(x, p.y, z, rail)
+			}
+
+			// One section in a hundred has a chest minecart
+			if a%5 == 0 && r.Intn(100) == 0 {
+				ma := a + r.Intn(5)
+				mx, mz := p.at(ma, 0)
+				seed := r.Int63()
+				if ma != spawnerAt && inChunk(mx, p.y, mz) {
+					features.Minecarts = append(features.Minecarts, LootChest{
+						Pos:   BlockPos{int32(mx), int32(p.y), int32(mz)},
+						Table: LootMineshaft,
+						Seed:  seed,
+					})
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package world
+
+import (
+	"math"
+	"math/rand"
+)
+
+// OreVein describes one kind of blob scattered through the stone of every chunk:
+// ores, and the dirt, gravel and stone variant pockets.
+type OreVein struct {
+	Block      uint16 // Block state placed (blockID << 4 | meta)
+	Size       int    // Blocks per vein (roughly); 1 places single blocks
+	Count      int    // Veins attempted per chunk
+	ExtraCount int    // Up to this many more veins per chunk, chosen at random
+	MinY, MaxY int    // Height range of vein centres, MaxY exclusive
+	Triangular bool   // Centre heights cluster around the middle of the range
+	Biomes     []byte // Biome IDs the vein generates in; nil means everywhere
+}
+
+// OreVeins are the veins generated underground, in placement order.
+// Sizes, counts and heights match vanilla 1.8.
+var OreVeins = []OreVein{
+	{Block: 3 << 4, Size: 33, Count: 10, MinY: 0, MaxY: 256},                 // Dirt
+	{Block: 13 << 4, Size: 33, Count: 8, MinY: 0, MaxY: 256},                 // Gravel
+	{Block: 1<<4 | 1, Size: 33, Count: 10, MinY: 0, MaxY: 80},                // Granite
+	{Block: 1<<4 | 3, Size: 33, Count: 10, MinY: 0, MaxY: 80},                // Diorite
+	{Block: 1<<4 | 5, Size: 33, Count: 10, MinY: 0, MaxY: 80},                // Andesite
+	{Block: 16 << 4, Size: 17, Count: 20, MinY: 0, MaxY: 128},                // Coal ore
+	{Block: 15 << 4, Size: 9, Count: 20, MinY: 0, MaxY: 64},                  // Iron ore
+	{Block: 14 << 4, Size: 9, Count: 2, MinY: 0, MaxY: 32},                   // Gold ore
+	{Block: 73 << 4, Size: 8, Count: 8, MinY: 0, MaxY: 16},                   // Redstone ore
+	{Block: 56 << 4, Size: 8, Count: 1, MinY: 0, MaxY: 16},                   // Diamond ore
+	{Block: 21 << 4, Size: 7, Count: 1, MinY: 0, MaxY: 32, Triangular: true}, // Lapis ore
+	{Block: 129 << 4, Size: 1, Count: 3, ExtraCount: 5, MinY: 4, MaxY: 32, // Emerald ore
+		Biomes: []byte{3}}, // Extreme Hills
+}
+
+// oreSalt separates the ore random stream from other per-chunk streams.
+const oreSalt = 0x6F7265
+
+// chunkRand returns a random source for chunk (chunkX, chunkZ) that depends only
+// on the seed, the chunk and salt, so generation is the same whatever order chunks are made in.
+func (g *Generator) chunkRand(chunkX, chunkZ int, salt int64) *rand.Rand {
+	return rand.New(rand.NewSource(g.chunkSeed(chunkX, chunkZ, salt)))
+}
+
+// generateOres places ore veins and stone pockets in the stone of a chunk.
+// Veins can reach up to 8 blocks past the chunk they start in, so the veins of
+// the neighbouring chunks are replayed too and clipped to this chunk. This keeps
+// veins continuous across chunk borders. Nothing is placed above maxY, the
+// highest solid block in the chunk.
+func (g *Generator) generateOres(chunkX, chunkZ, maxY int, sections *[SectionsPerChunk][ChunkSectionSize]uint16) {
+	for ox := chunkX - 1; ox <= chunkX+1; ox++ {
+		for oz := chunkZ - 1; oz <= chunkZ+1; oz++ {
+			r := g.chunkRand(ox, oz, oreSalt)
+			biome := BiomeAt(g.tempNoise, g.rainNoise, ox*16+8, oz*16+8)
+			for _, vein := range OreVeins {
+				generateVeins(r, vein, biome, ox, oz, chunkX, chunkZ, maxY, sections)
+			}
+		}
+	}
+}
+
+// generateVeins places the veins of one kind that start in chunk (originX, originZ),
+// writing only the blocks that fall inside chunk (chunkX, chunkZ).
+func generateVeins(r *rand.Rand, vein OreVein, biome *Biome, originX, originZ, chunkX, chunkZ, maxY int, sections *[SectionsPerChunk][ChunkSectionSize]uint16) {
+	if vein.Biomes != nil {
+		found := false
+		for _, id := range vein.Biomes {
+			if biome.ID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return
+		}
+	}
+
+	count := vein.Count
+	if vein.ExtraCount > 0 {
+		count += r.Intn(vein.ExtraCount + 1)
+	}
+	for i := 0; i < count; i++ {
+		x := originX*16 + r.Intn(16)
+		z := originZ*16 + r.Intn(16)
+		var y int
+		if vein.Triangular {
+			half := (vein.MaxY - vein.MinY) / 2
+			y = vein.MinY + r.Intn(half) + r.Intn(half)
+		} else {
+			y = vein.MinY + r.Intn(vein.MaxY-vein.MinY)
+		}
+
+		if vein.Size <= 1 {
+			placeOre(x, y, z, vein.Block, chunkX, chunkZ, sections)
+			continue
+		}
+		placeVein(r, x, y, z, vein.Size, vein.Block, chunkX, chunkZ, maxY, sections)
+	}
+}
+
+// placeVein places a vanilla-style vein: a string of blobs along a short random
+// line through (x, y, z), widest in the middle. The random numbers used do not
+// depend on which chunk is being written, so every chunk sees the same vein.
+func placeVein(r *rand.Rand, x, y, z, size int, block uint16, chunkX, chunkZ, maxY int, sections *[SectionsPerChunk][ChunkSectionSize]uint16) {
+	angle := r.Float64() * math.Pi
+	reach := float64(size) / 8
+	x1 := float64(x) + 0.5 + math.Sin(angle)*reach
+	x2 := float64(x) + 0.5 - math.Sin(angle)*reach
+	z1 := float64(z) + 0.5 + math.Cos(angle)*reach
+	z2 := float64(z) + 0.5 - math.Cos(angle)*reach
+	y1 := float64(y + r.Intn(3) - 2)
+	y2 := float64(y + r.Intn(3) - 2)
+
+	// Skip veins that cannot touch the solid part of this chunk, still drawing
+	// their random numbers so the veins after them are unchanged
+	minX, minZ := chunkX*16, chunkZ*16
+	maxRadius := (float64(size)/8 + 1) / 2
+	if math.Max(x1, x2)+maxRadius < float64(minX) || math.Min(x1, x2)-maxRadius >= float64(minX+16) ||
+		math.Max(z1, z2)+maxRadius < float64(minZ) || math.Min(z1, z2)-maxRadius >= float64(minZ+16) ||
+		math.Min(y1, y2)-maxRadius > float64(maxY) {
+		for i := 0; i < size; i++ {
+			r.Float64()
+		}
+		return
+	}
+
+	for i := 0; i < size; i++ {
+		f := float64(i) / float64(size)
+		cx := x1 + (x2-x1)*f
+		cy := y1 + (y2-y1)*f
+		cz := z1 + (z2-z1)*f
+		spread := r.Float64() * float64(size) / 16
+		radius := ((math.Sin(math.Pi*f)+1)*spread + 1) / 2
+
+		// Only visit the part of the blob inside this chunk and below maxY
+		bx0, bx1 := max(int(math.Floor(cx-radius)), minX), min(int(math.Floor(cx+radius)), minX+15)
+		bz0, bz1 := max(int(math.Floor(cz-radius)), minZ), min(int(math.Floor(cz+radius)), minZ+15)
+		by0, by1 := max(int(math.Floor(cy-radius)), 1), min(int(math.Floor(cy+radius)), maxY)
+		for bx := bx0; bx <= bx1; bx++ {
+			dx := (float64(bx) + 0.5 - cx) / radius
+			for by := by0; by <= by1; by++ {
+				dy := (float64(by) + 0.5 - cy) / radius
+				for bz := bz0; bz <= bz1; bz++ {
+					dz := (float64(bz) + 0.5 - cz) / radius
+					if dx*dx+dy*dy+dz*dz < 1 {
+						placeOre(bx, by, bz, block, chunkX, chunkZ, sections)
+					}
+				}
+			}
+		}
+	}
+}
+
+// placeOre sets world-space (x, y, z) to block if it lies in chunk (chunkX, chunkZ)
+// and is currently stone (including granite, diorite and andesite).
+func placeOre(x, y, z int, block uint16, chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16) {
+	lx, lz := x-chunkX*16, z-chunkZ*16
+	if lx < 0 || lx >= 16 || lz < 0 || lz >= 16 || y < 1 || y > 255 {
+		return
+	}
+	idx := ((y%16)*16+lz)*16 + lx
+	if sections[y/16][idx]>>4 == 1 {
+		sections[y/16][idx] = block
+	}
+}
@@ -0,0 +1,79 @@
+package world
+
+import "testing"
+
+// stoneChunk returns chunk sections filled with stone up to y=127.
+func stoneChunk() *[SectionsPerChunk][ChunkSectionSize]uint16 {
+	var sections [SectionsPerChunk][ChunkSectionSize]uint16
+	for sec := 0; sec < 8; sec++ {
+		for i := range sections[sec] {
+			sections[sec][i] = 1 << 4
+		}
+	}
+	return &sections
+}
+
+func TestOreGeneration(t *testing.T) {
+	g := NewGenerator(2024)
+	counts := make(map[uint16]int)
+	for cx := 0; cx < 4; cx++ {
+		for cz := 0; cz < 4; cz++ {
+			sections := stoneChunk()
+			g.generateOres(cx, cz, 127, sections)
+			for sec := range sections {
+				for i, block := range sections[sec] {
+					if block == 1<<4 {
+						continue
+					}
+					counts[block]++
+					y := sec*16 + i/256
+					if block == 56<<4 && y > 20 {
+						t.Errorf("diamond ore at y=%d", y)
+					}
+					if block == 16<<4 && y > 135 {
+						t.Errorf("coal ore at y=%d", y)
+					}
+				}
+			}
+		}
+	}
+
+	for _, block := range []uint16{16 << 4, 15 << 4, 14 << 4, 73 << 4, 56 << 4, 3 << 4, 13 << 4, 1<<4 | 1} {
+		if counts[block] == 0 {
+			t.Errorf("no blocks of state %d generated in 16 chunks", block)
+		}
+	}
+	if counts[16<<4] < counts[56<<4] {
+		t.Errorf("coal (%d) should be more common than diamond (%d)", counts[16<<4], counts[56<<4])
+	}
+}
+
+func TestOresDeterministicAcrossChunkOrder(t *testing.T) {
+	g1, g2 := NewGenerator(7), NewGenerator(7)
+
+	a := stoneChunk()
+	g1.generateOres(3, -2, 127, a)
+
+	// Generating neighbours first must not change the result
+	g2.generateOres(2, -2, 127, stoneChunk())
+	g2.generateOres(4, -2, 127, stoneChunk())
+	b := stoneChunk()
+	g2.generateOres(3, -2, 127, b)
+
+	if *a != *b {
+		t.Error("ore placement depends on generation order")
+	}
+}
+
+func TestOresReplaceOnlyStone(t *testing.T) {
+	g := NewGenerator(1)
+	var sections [SectionsPerChunk][ChunkSectionSize]uint16
+	g.generateOres(0, 0, 255, &sections)
+	for sec := range sections {
+		for i, block := range sections[sec] {
+			if block != 0 {
+				t.Fatalf("ore placed in air at section %d index %d", sec, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package world
+
+import "sync"
+
+// StructureGrid places one kind of structure deterministically per seed.
+// The world is divided into square cells of cellSize blocks; each cell holds
+// at most one structure, whose site is computed by the site function from the
+// seed and the cell alone, and cached. Structures therefore come out the same
+// whatever order chunks are generated in.
+type StructureGrid[S any] struct {
+	seed     int64
+	salt     int64
+	cellSize int
+	site     func(cellX, cellZ int) (S, bool)
+
+	mu    sync.RWMutex
+	cache map[cellKey]gridCell[S]
+}
+
+// gridCell is the cached result of a StructureGrid's site function.
+type gridCell[S any] struct {
+	site S
+	ok   bool
+}
+
+// NewStructureGrid creates a grid of cellSize-block cells. The salt separates
+// the grid's hashes from those of other grids on the same seed.
+func NewStructureGrid[S any](seed, salt int64, cellSize int, site func(cellX, cellZ int) (S, bool)) *StructureGrid[S] {
+	return &StructureGrid[S]{
+		seed:     seed,
+		salt:     salt,
+		cellSize: cellSize,
+		site:     site,
+		cache:    make(map[cellKey]gridCell[S]),
+	}
+}
+
+// CellSize returns the side of the grid cells in blocks.
+func (g *StructureGrid[S]) CellSize() int { return g.cellSize }
+
+// CellSeed returns a well-mixed seed for (cx, cz), for random sources tied to a cell.
+func (g *StructureGrid[S]) CellSeed(cx, cz int64) int64 {
+	return positionSeed(g.seed, cx, cz, g.salt)
+}
+
+// CellHash returns a deterministic value in [0, mod) for (cx, cz).
+func (g *StructureGrid[S]) CellHash(cx, cz, mod int64) int64 {
+	h := g.CellSeed(cx, cz)
+	if h < 0 {
+		h = -h
+	}
+	return h % mod
+}
+
+// CellAt returns the cell containing world-space (x, z).
+func (g *StructureGrid[S]) CellAt(x, z int) (int, int) {
+	return divFloor(x, g.cellSize), divFloor(z, g.cellSize)
+}
+
+// Site returns the structure of cell (cellX, cellZ), and whether the cell has one.
+func (g *StructureGrid[S]) Site(cellX, cellZ int) (S, bool) {
+	key := cellKey{cellX, cellZ}
+	g.mu.RLock()
+	if c, ok := g.cache[key]; ok {
+		g.mu.RUnlock()
+		return c.site, c.ok
+	}
+	g.mu.RUnlock()
+
+	site, ok := g.site(cellX, cellZ)
+
+	g.mu.Lock()
+	g.cache[key] = gridCell[S]{site, ok}
+	g.mu.Unlock()
+
+	return site, ok
+}
+
+// SitesNear calls fn for the structure of every cell overlapping the block
+// area [minX, maxX] x [minZ, maxZ] widened by reach on each side.
+func (g *StructureGrid[S]) SitesNear(minX, minZ, maxX, maxZ, reach int, fn func(site S)) {
+	cellMinX, cellMinZ := g.CellAt(minX-reach, minZ-reach)
+	cellMaxX, cellMaxZ := g.CellAt(maxX+reach, maxZ+reach)
+	for cx := cellMinX; cx <= cellMaxX; cx++ {
+		for cz := cellMinZ; cz <= cellMaxZ; cz++ {
+			if site, ok := g.Site(cx, cz); ok {
+				fn(site)
+			}
+		}
+	}
+}
+
+// Nearest returns the structure closest to world column (x, z) that match
+// accepts, searching outwards one ring of cells at a time up to radius cells
+// away. pos gives the column a structure's distance is measured to.
+func (g *StructureGrid[S]) Nearest(x, z, radius int, pos func(S) (int, int), match func(S) bool) (S, bool) {
+	var best S
+	found := false
+	bestDist := 0
+	cellX, cellZ := g.CellAt(x, z)
+	for r := 0; r <= radius; r++ {
+		for cx := cellX - r; cx <= cellX+r; cx++ {
+			for cz := cellZ - r; cz <= cellZ+r; cz++ {
+				if abs(cx-cellX) != r && abs(cz-cellZ) != r {
+					continue // Inside the ring, searched already
+				}
+				site, ok := g.Site(cx, cz)
+				if !ok || !match(site) {
+					continue
+				}
+				sx, sz := pos(site)
+				if d := (sx-x)*(sx-x) + (sz-z)*(sz-z); !found || d < bestDist {
+					best, bestDist, found = site, d, true
+				}
+			}
+		}
+		// Every cell further out is at least r whole cells away
+		if found && bestDist <= (r*g.cellSize)*(r*g.cellSize) {
+			break
+		}
+	}
+	return best, found
+}
+
+// StructureNames lists the structures Generator.Locate can find.
+var StructureNames = []string{
+	"village",
+	DesertPyramid.String(),
+	JungleTemple.String(),
+	WitchHut.String(),
+	Igloo.String(),
+	"mineshaft",
+}
+
+// Locate returns the column of the named structure closest to world column
+// (x, z), if there is one within radius blocks. Villages are located by their
+// well, biome structures by their centre and mineshafts by their start room.
+func (g *Generator) Locate(name string, x, z, radius int) (int, int, bool) {
+	sx, sz, ok := g.nearestStructure(name, x, z, radius)
+	if !ok || (sx-x)*(sx-x)+(sz-z)*(sz-z) > radius*radius {
+		return 0, 0, false
+	}
+	return sx, sz, true
+}
+
+// nearestStructure searches the grid of the named structure for Locate,
+// covering every cell within radius blocks.
+func (g *Generator) nearestStructure(name string, x, z, radius int) (int, int, bool) {
+	switch name {
+	case "village":
+		site, ok := g.villageGen.sites.Nearest(x, z, radius/villageCellSize+1,
+			func(s villageSite) (int, int) { return s.wx, s.wz },
+			func(villageSite) bool { return true })
+		return site.wx, site.wz, ok
+	case "mineshaft":
+		plan, ok := g.mineshafts.grid.Nearest(x, z, radius/mineshaftCellSize+1,
+			func(p *MineshaftPlan) (int, int) { return p.X, p.Z },
+			func(*MineshaftPlan) bool { return true })
+		if !ok {
+			return 0, 0, false
+		}
+		return plan.X, plan.Z, true
+	}
+	for kind := DesertPyramid; kind <= Igloo; kind++ {
+		if kind.String() == name {
+			p, ok := g.FindTemple(kind, x, z, radius/templeCellSize+1)
+			if !ok {
+				return 0, 0, false
+			}
+			return p.X + p.Width/2, p.Z + p.Depth/2, true
+		}
+	}
+	return 0, 0, false
+}
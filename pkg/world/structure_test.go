@@ -0,0 +1,255 @@
+package world
+
+import "testing"
+
+func TestDungeonGeneration(t *testing.T) {
+	g := NewGenerator(12345)
+	found := 0
+	for cx := -8; cx < 8 && found < 3; cx++ {
+		for cz := -8; cz < 8 && found < 3; cz++ {
+			sections, _, features := g.GenerateWithFeatures(cx, cz)
+			get := func(p BlockPos) uint16 {
+				lx, lz := int(p.X)-cx*16, int(p.Z)-cz*16
+				return sections[p.Y/16][((int(p.Y)%16)*16+lz)*16+lx]
+			}
+			for _, sp := range features.Spawners {
+				if sp.Mob == "CaveSpider" {
+					continue // Mineshaft spawner
+				}
+				found++
+				if get(sp.Pos) != 52<<4 {
+					t.Errorf("no spawner block at %v", sp.Pos)
+				}
+				floor := get(BlockPos{sp.Pos.X, sp.Pos.Y - 1, sp.Pos.Z}) >> 4
+				if floor != 4 && floor != 48 {
+					t.Errorf("dungeon floor at %v is block %d, want cobblestone", sp.Pos, floor)
+				}
+			}
+			for _, c := range features.Chests {
+				if get(c.Pos)>>4 != 54 {
+					t.Errorf("no chest block at %v", c.Pos)
+				}
+				switch c.Table {
+				case LootDungeon, LootVillageHouse, LootVillageChurch, LootVillageBlacksmith:
+				default:
+					t.Errorf("dungeon chest uses table %q", c.Table)
+				}
+			}
+		}
+	}
+	if found == 0 {
+		t.Fatal("no dungeons generated in 256 chunks")
+	}
+
+	// Features are the same when the chunk is regenerated
+	_, _, a := g.GenerateWithFeatures(3, -2)
+	_, _, b := NewGenerator(12345).GenerateWithFeatures(3, -2)
+	if len(a.Chests) != len(b.Chests) || len(a.Spawners) != len(b.Spawners) || len(a.Minecarts) != len(b.Minecarts) {
+		t.Error("features differ between generators with the same seed")
+	}
+}
+
+// findMineshaft returns the first mineshaft plan of the grid near the origin.
+func findMineshaft(t *testing.T, m *MineshaftGrid) *MineshaftPlan {
+	t.Helper()
+	for cx := -2; cx <= 2; cx++ {
+		for cz := -2; cz <= 2; cz++ {
+			if plan := m.plan(cx, cz); plan != nil {
+				return plan
+			}
+		}
+	}
+	t.Fatal("no mineshaft in 25 cells")
+	return nil
+}
+
+func TestMineshaftLayout(t *testing.T) {
+	g := NewGenerator(12345)
+	plan := findMineshaft(t, g.mineshafts)
+	if len(plan.pieces) < 5 {
+		t.Errorf("mineshaft has only %d pieces", len(plan.pieces))
+	}
+	for i, p := range plan.pieces {
+		if p.box.minY <= bedrockLayers {
+			t.Errorf("piece %d reaches bedrock: %+v", i, p.box)
+		}
+		for j := i + 1; j < len(plan.pieces); j++ {
+			if p.box.intersects(plan.pieces[j].box) {
+				t.Errorf("pieces %d and %d overlap", i, j)
+			}
+		}
+	}
+
+	// A fresh grid lays out the same mineshaft
+	again := NewGenerator(12345).mineshafts.plan(divFloor(plan.X, mineshaftCellSize), divFloor(plan.Z, mineshaftCellSize))
+	if again == nil || len(again.pieces) != len(plan.pieces) || again.bounds != plan.bounds {
+		t.Error("mineshaft layout is not deterministic")
+	}
+}
+
+func TestMineshaftCrossesChunks(t *testing.T) {
+	g := NewGenerator(12345)
+	plan := findMineshaft(t, g.mineshafts)
+
+	// Build every chunk the mineshaft touches into solid stone
+	chunks := make(map[ChunkPos]*[SectionsPerChunk][ChunkSectionSize]uint16)
+	for cx := divFloor(plan.bounds.minX, 16); cx <= divFloor(plan.bounds.maxX, 16); cx++ {
+		for cz := divFloor(plan.bounds.minZ, 16); cz <= divFloor(plan.bounds.maxZ, 16); cz++ {
+			var features ChunkFeatures
+			sections := stoneChunk()
+			g.mineshafts.generateMineshafts(cx, cz, sections, &features)
+			chunks[ChunkPos{int32(cx), int32(cz)}] = sections
+		}
+	}
+	blockAt := func(x, y, z int) uint16 {
+		sections := chunks[ChunkPos{int32(divFloor(x, 16)), int32(divFloor(z, 16))}]
+		return sections[y/16][((y%16)*16+z-divFloor(z, 16)*16)*16+x-divFloor(x, 16)*16]
+	}
+
+	crossings := 0
+	rails := 0
+	for _, p := range plan.pieces {
+		if p.kind != shaftCorridor {
+			continue
+		}
+		x0, z0 := p.at(0, 0)
+		x1, z1 := p.at(p.length-1, 0)
+		if divFloor(x0, 16) != divFloor(x1, 16) || divFloor(z0, 16) != divFloor(z1, 16) {
+			crossings++
+		}
+		for a := 0; a < p.length; a++ {
+			x, z := p.at(a, 0)
+			// Head height along the middle of a corridor is open, or a cobweb
+			if b := blockAt(x, p.y+1, z) >> 4; b != 0 && b != 30 {
+				t.Errorf("corridor at (%d, %d, %d) blocked by %d", x, p.y+1, z, b)
+			}
+			if blockAt(x, p.y, z)>>4 == 66 {
+				rails++
+			}
+		}
+	}
+	if crossings == 0 {
+		t.Error("no corridor crosses a chunk border")
+	}
+	if rails == 0 {
+		t.Error("no rails placed")
+	}
+}
+
+func TestLootTableFill(t *testing.T) {
+	for name, table := range LootTables {
+		a := table.Fill(42)
+		if a != table.Fill(42) {
+			t.Errorf("%s: same seed gave different loot", name)
+		}
+		stacks := 0
+		for _, slot := range a {
+			if slot.ItemID == -1 {
+				continue
+			}
+			stacks++
+			ok := false
+			for _, e := range table.Entries {
+				if e.ItemID == slot.ItemID && e.Damage == slot.Damage && int(slot.Count) >= e.Min && int(slot.Count) <= e.Max {
+					ok = true
+					break
+				}
+			}
+			if !ok {
+				t.Errorf("%s: unexpected stack %+v", name, slot)
+			}
+		}
+		if stacks == 0 || stacks > table.Rolls+table.ExtraRolls {
+			t.Errorf("%s: %d stacks filled", name, stacks)
+		}
+	}
+}
+
+func TestStructureGrid(t *testing.T) {
+	calls := 0
+	site := func(cellX, cellZ int) (int, bool) {
+		calls++
+		return cellX*100 + cellZ, cellX%2 == 0
+	}
+	grid := NewStructureGrid(12345, 1, 64, site)
+
+	if v, ok := grid.Site(2, 3); !ok || v != 203 {
+		t.Errorf("Site(2, 3) = %d, %v", v, ok)
+	}
+	if _, ok := grid.Site(1, 3); ok {
+		t.Error("Site(1, 3) reported a structure")
+	}
+	grid.Site(2, 3)
+	grid.Site(1, 3)
+	if calls != 2 {
+		t.Errorf("site function called %d times, want 2 (results are cached)", calls)
+	}
+
+	// Cells overlapping [-1, 64] widened by 1 are -1, 0 and 1 on each axis
+	var visited []int
+	grid.SitesNear(-1, 0, 64, 63, 1, func(v int) { visited = append(visited, v) })
+	if len(visited) != 3 {
+		t.Errorf("SitesNear visited %v, want the 3 cells with even X", visited)
+	}
+
+	other := NewStructureGrid(12345, 2, 64, site)
+	same := 0
+	for i := int64(0); i < 100; i++ {
+		h := grid.CellHash(i, -i, 1000)
+		if h < 0 || h >= 1000 {
+			t.Fatalf("CellHash out of range: %d", h)
+		}
+		if h == other.CellHash(i, -i, 1000) {
+			same++
+		}
+	}
+	if same > 5 {
+		t.Errorf("grids with different salts agree on %d of 100 hashes", same)
+	}
+}
+
+func TestLocate(t *testing.T) {
+	g := NewGenerator(12345)
+	for _, name := range StructureNames {
+		x, z, ok := g.Locate(name, 100, -200, 5000)
+		if !ok {
+			t.Errorf("no %s within 5000 blocks", name)
+			continue
+		}
+		if d := (x-100)*(x-100) + (z+200)*(z+200); d > 5000*5000 {
+			t.Errorf("%s at %d, %d is further than 5000 blocks", name, x, z)
+		}
+	}
+	if _, _, ok := g.Locate("stronghold", 0, 0, 5000); ok {
+		t.Error("located an unknown structure")
+	}
+
+	// The located village is the closest one
+	vx, vz, _ := g.Locate("village", 100, -200, 5000)
+	best := (vx-100)*(vx-100) + (vz+200)*(vz+200)
+	for cx := -10; cx <= 10; cx++ {
+		for cz := -10; cz <= 10; cz++ {
+			if x, z, ok := g.villageGen.villageCenter(cx, cz); ok {
+				if d := (x-100)*(x-100) + (z+200)*(z+200); d < best {
+					t.Errorf("village at %d, %d is closer than located %d, %d", x, z, vx, vz)
+				}
+			}
+		}
+	}
+
+	plan, ok := g.VillagePlanAt(vx+1, vz+1)
+	if !ok || plan.VX != vx || plan.VZ != vz {
+		t.Fatalf("VillagePlanAt next to the well = %v, %v", plan, ok)
+	}
+	if lines := plan.Describe(); len(lines) != 1+len(plan.Buildings)+countRoads(plan.Roads)+len(plan.Farms) {
+		t.Errorf("Describe returned %d lines", len(lines))
+	}
+}
+
+func countRoads(roads []roadSegment) int {
+	n := len(roads)
+	for _, r := range roads {
+		n += countRoads(r.children)
+	}
+	return n
+}
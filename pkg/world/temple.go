@@ -0,0 +1,570 @@
+package world
+
+// Desert pyramids, jungle temples, witch huts and igloos share one structure
+// grid with at most one structure per cell, like vanilla's 32-chunk spacing.
+// The biome at the chosen spot decides which is built; cells whose spot lies
+// in any other biome stay empty.
+const (
+	templeCellSize = 512
+	templeSpread   = 384 // Structures start within this many blocks of the cell corner
+	templeSalt     = 0x74656D70
+)
+
+// TempleKind identifies a kind of biome structure.
+type TempleKind int
+
+const (
+	DesertPyramid TempleKind = iota
+	JungleTemple
+	WitchHut
+	Igloo
+)
+
+// String returns the structure name used in commands, e.g. "desert_pyramid".
+func (k TempleKind) String() string {
+	switch k {
+	case DesertPyramid:
+		return "desert_pyramid"
+	case JungleTemple:
+		return "jungle_temple"
+	case WitchHut:
+		return "witch_hut"
+	case Igloo:
+		return "igloo"
+	}
+	return "unknown"
+}
+
+// templeSizes holds each kind's footprint as built facing -Z: width along X
+// and depth along Z.
+var templeSizes = [...][2]int{
+	DesertPyramid: {21, 21},
+	JungleTemple:  {12, 15},
+	WitchHut:      {7, 9},
+	Igloo:         {7, 7},
+}
+
+// TemplePlan is one placed biome structure.
+type TemplePlan struct {
+	Kind         TempleKind
+	X, Z         int // Minimum corner of the footprint
+	Y            int // Floor level
+	Width, Depth int // Footprint along X and Z
+	Facing       int // Side the entrance is on: 0=+Z, 1=-Z, 2=+X, 3=-X
+	seed         int64
+}
+
+// Contains reports whether world column (x, z) is inside the structure's footprint.
+func (p *TemplePlan) Contains(x, z int) bool {
+	return x >= p.X && x < p.X+p.Width && z >= p.Z && z < p.Z+p.Depth
+}
+
+// templeSite rolls the structure of a temple grid cell.
+func (g *Generator) templeSite(cellX, cellZ int) (*TemplePlan, bool) {
+	r := newCarverRand(g.temples.CellSeed(int64(cellX), int64(cellZ)))
+	x := cellX*templeCellSize + r.Intn(templeSpread)
+	z := cellZ*templeCellSize + r.Intn(templeSpread)
+
+	var kind TempleKind
+	switch g.Biome(x, z) {
+	case BiomeDesert:
+		kind = DesertPyramid
+	case BiomeJungle:
+		kind = JungleTemple
+	case BiomeSwampland:
+		kind = WitchHut
+	case BiomeSnowyTundra:
+		kind = Igloo
+	default:
+		return nil, false
+	}
+
+	p := &TemplePlan{Kind: kind, X: x, Z: z, Facing: r.Intn(4), seed: r.Int63()}
+	p.Width, p.Depth = templeSizes[kind][0], templeSizes[kind][1]
+	if p.Facing >= 2 {
+		p.Width, p.Depth = p.Depth, p.Width
+	}
+
+	center := g.SurfaceHeight(x+p.Width/2, z+p.Depth/2)
+	lowest, sum := center, center
+	for _, c := range [][2]int{{x, z}, {x + p.Width - 1, z}, {x, z + p.Depth - 1}, {x + p.Width - 1, z + p.Depth - 1}} {
+		h := g.SurfaceHeight(c[0], c[1])
+		lowest = min(lowest, h)
+		sum += h
+	}
+	switch kind {
+	case DesertPyramid:
+		p.Y = lowest
+	case JungleTemple:
+		p.Y = sum / 5
+	case WitchHut:
+		p.Y = max(center, WaterLevel) + 3 // On stilts above the swamp water
+	case Igloo:
+		p.Y = center
+	}
+	if kind != WitchHut && lowest < WaterLevel {
+		return nil, false // Would stand in water
+	}
+	return p, true
+}
+
+// FindTemple returns the structure of the given kind closest to world column
+// (x, z), searching grid cells up to radius cells away.
+func (g *Generator) FindTemple(kind TempleKind, x, z, radius int) (*TemplePlan, bool) {
+	return g.temples.Nearest(x, z, radius,
+		func(p *TemplePlan) (int, int) { return p.X + p.Width/2, p.Z + p.Depth/2 },
+		func(p *TemplePlan) bool { return p.Kind == kind })
+}
+
+// templesInChunk calls fn for every biome structure with part of its
+// footprint in chunk (chunkX, chunkZ).
+func (g *Generator) templesInChunk(chunkX, chunkZ int, fn func(p *TemplePlan)) {
+	minX, minZ := chunkX*16, chunkZ*16
+	g.temples.SitesNear(minX, minZ, minX+15, minZ+15, 0, func(p *TemplePlan) {
+		if p.X <= minX+15 && p.X+p.Width > minX && p.Z <= minZ+15 && p.Z+p.Depth > minZ {
+			fn(p)
+		}
+	})
+}
+
+// chunkHasTemple reports whether a biome structure stands in chunk (chunkX, chunkZ).
+func (g *Generator) chunkHasTemple(chunkX, chunkZ int) bool {
+	found := false
+	g.templesInChunk(chunkX, chunkZ, func(*TemplePlan) { found = true })
+	return found
+}
+
+// generateTemples builds the parts of biome structures that fall in the chunk.
+func (g *Generator) generateTemples(chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16, features *ChunkFeatures) {
+	g.templesInChunk(chunkX, chunkZ, func(p *TemplePlan) {
+		b := &templeBuilder{
+			plan:     p,
+			chunkX:   chunkX,
+			chunkZ:   chunkZ,
+			sections: sections,
+			features: features,
+			rand:     newCarverRand(p.seed),
+		}
+		switch p.Kind {
+		case DesertPyramid:
+			b.buildDesertPyramid()
+		case JungleTemple:
+			b.buildJungleTemple()
+		case WitchHut:
+			b.buildWitchHut()
+		case Igloo:
+			b.buildIgloo()
+		}
+	})
+}
+
+// Horizontal directions, numbered like village and temple facings.
+const (
+	dirSouth = iota // +Z
+	dirNorth        // -Z
+	dirEast         // +X
+	dirWest         // -X
+)
+
+// templeRotations maps a direction in a structure's own frame, where the
+// entrance is on the north side, to the world direction for each facing.
+var templeRotations = [4][4]int{
+	0: {dirNorth, dirSouth, dirWest, dirEast},
+	1: {dirSouth, dirNorth, dirEast, dirWest},
+	2: {dirWest, dirEast, dirSouth, dirNorth},
+	3: {dirEast, dirWest, dirNorth, dirSouth},
+}
+
+// Block metadata for each world direction, indexed by dirSouth..dirWest.
+var (
+	facingMeta = [4]uint16{3, 2, 5, 4} // Chests, furnaces, dispensers and ladders facing that way
+	stairsMeta = [4]uint16{2, 3, 0, 1} // Stairs rising towards that side
+	hookMeta   = [4]uint16{0, 2, 3, 1} // Tripwire hooks pointing that way
+)
+
+// templeBuilder places one structure's blocks, in the structure's own frame,
+// into a chunk. Coordinates are (x, dy, z): x and z run over the unrotated
+// footprint with the entrance at z = 0, and dy is relative to the floor.
+// Blocks outside the chunk are skipped, and every random roll is made
+// whether or not its block lands in the chunk, so each chunk builds its part
+// of the same structure.
+type templeBuilder struct {
+	plan           *TemplePlan
+	chunkX, chunkZ int
+	sections       *[SectionsPerChunk][ChunkSectionSize]uint16
+	features       *ChunkFeatures
+	rand           *carverRand
+}
+
+// pos converts structure coordinates to a world position.
+func (b *templeBuilder) pos(x, dy, z int) BlockPos {
+	p := b.plan
+	w, d := templeSizes[p.Kind][0], templeSizes[p.Kind][1]
+	var wx, wz int
+	switch p.Facing {
+	case 0:
+		wx, wz = p.X+w-1-x, p.Z+d-1-z
+	case 2:
+		wx, wz = p.X+d-1-z, p.Z+x
+	case 3:
+		wx, wz = p.X+z, p.Z+w-1-x
+	default:
+		wx, wz = p.X+x, p.Z+z
+	}
+	return BlockPos{int32(wx), int32(p.Y + dy), int32(wz)}
+}
+
+// local returns the chunk-local coordinates of a world position, and whether
+// it is inside the chunk.
+func (b *templeBuilder) local(pos BlockPos) (int, int, int, bool) {
+	lx, okX := worldToLocal(int(pos.X), b.chunkX*16)
+	lz, okZ := worldToLocal(int(pos.Z), b.chunkZ*16)
+	return lx, int(pos.Y), lz, okX && okZ && pos.Y >= 0 && pos.Y < 256
+}
+
+// dir converts a direction in the structure's frame to a world direction.
+func (b *templeBuilder) dir(d int) int {
+	return templeRotations[b.plan.Facing][d]
+}
+
+func (b *templeBuilder) set(x, dy, z int, state uint16) {
+	if lx, y, lz, ok := b.local(b.pos(x, dy, z)); ok {
+		setBlock(lx, y, lz, state, b.sections)
+	}
+}
+
+// fill sets every block of the box between two corners.
+func (b *templeBuilder) fill(x0, dy0, z0, x1, dy1, z1 int, state uint16) {
+	for x := x0; x <= x1; x++ {
+		for dy := dy0; dy <= dy1; dy++ {
+			for z := z0; z <= z1; z++ {
+				b.set(x, dy, z, state)
+			}
+		}
+	}
+}
+
+// foundation fills the column below (x, dy, z) with state down to the first
+// solid terrain block, so the structure doesn't float over dips and water.
+func (b *templeBuilder) foundation(x, dy, z int, state uint16) {
+	lx, y, lz, ok := b.local(b.pos(x, dy, z))
+	if !ok {
+		return
+	}
+	for ; y > bedrockLayers; y-- {
+		idx := ((y%16)*16+lz)*16 + lx
+		if isSolidTerrain(b.sections[y/16][idx]) {
+			return
+		}
+		b.sections[y/16][idx] = state
+	}
+}
+
+// cobble returns cobblestone or, for two in five blocks, mossy cobblestone.
+func (b *templeBuilder) cobble(x, dy, z int) uint16 {
+	pos := b.pos(x, dy, z)
+	if columnChance(int(pos.X), int(pos.Z)+int(pos.Y)*4099, b.plan.seed) < 0.4 {
+		return 48 << 4
+	}
+	return 4 << 4
+}
+
+// fillCobble is fill with a mix of cobblestone and mossy cobblestone.
+func (b *templeBuilder) fillCobble(x0, dy0, z0, x1, dy1, z1 int) {
+	for x := x0; x <= x1; x++ {
+		for dy := dy0; dy <= dy1; dy++ {
+			for z := z0; z <= z1; z++ {
+				b.set(x, dy, z, b.cobble(x, dy, z))
+			}
+		}
+	}
+}
+
+// chest places a loot chest facing direction d of the structure's frame.
+func (b *templeBuilder) chest(x, dy, z, d int, table string) {
+	seed := b.rand.Int63()
+	b.set(x, dy, z, 54<<4|facingMeta[b.dir(d)])
+	pos := b.pos(x, dy, z)
+	if _, _, _, ok := b.local(pos); ok {
+		b.features.Chests = append(b.features.Chests, LootChest{Pos: pos, Table: table, Seed: seed})
+	}
+}
+
+// trap records a trap. It is reported by the chunk holding its first trigger.
+func (b *templeBuilder) trap(kind TrapKind, triggers, targets [][3]int) {
+	t := Trap{Kind: kind}
+	for _, c := range triggers {
+		t.Triggers = append(t.Triggers, b.pos(c[0], c[1], c[2]))
+	}
+	for _, c := range targets {
+		t.Targets = append(t.Targets, b.pos(c[0], c[1], c[2]))
+	}
+	if _, _, _, ok := b.local(t.Triggers[0]); ok {
+		b.features.Traps = append(b.features.Traps, t)
+	}
+}
+
+// tripwire places a tripwire across z from hook x0 to hook x1, returning its blocks.
+func (b *templeBuilder) tripwire(x0, x1, dy, z int) [][3]int {
+	b.set(x0, dy, z, 131<<4|hookMeta[b.dir(dirEast)]|4)
+	b.set(x1, dy, z, 131<<4|hookMeta[b.dir(dirWest)]|4)
+	blocks := [][3]int{{x0, dy, z}, {x1, dy, z}}
+	for x := x0 + 1; x < x1; x++ {
+		b.set(x, dy, z, 132<<4|4) // Attached tripwire
+		blocks = append(blocks, [3]int{x, dy, z})
+	}
+	return blocks
+}
+
+// buildDesertPyramid builds a hollow stepped sandstone pyramid. Under the
+// clay pattern in the middle of the floor a shaft drops into a hidden room
+// with four chests and a pressure plate wired to nine TNT below it.
+func (b *templeBuilder) buildDesertPyramid() {
+	const sandstone = 24 << 4
+	const chiseled = 24<<4 | 1
+
+	for x := 0; x < 21; x++ {
+		for z := 0; z < 21; z++ {
+			b.foundation(x, -1, z, sandstone)
+			b.fill(x, 1, z, x, 12, z, 0)
+		}
+	}
+
+	// Each level is the outline of a square one block smaller on every side
+	b.fill(0, 0, 0, 20, 0, 20, sandstone)
+	for h := 1; h <= 10; h++ {
+		for x := h; x <= 20-h; x++ {
+			for z := h; z <= 20-h; z++ {
+				if h == 10 || x == h || x == 20-h || z == h || z == 20-h {
+					b.set(x, h, z, sandstone)
+				}
+			}
+		}
+	}
+
+	// Entrance in the middle of the front
+	b.set(10, 1, 1, 0)
+	b.set(10, 2, 2, 0)
+	b.set(9, 1, 1, chiseled)
+	b.set(11, 1, 1, chiseled)
+
+	// Orange clay star around a blue clay centre
+	for dx := -2; dx <= 2; dx++ {
+		for dz := -2; dz <= 2; dz++ {
+			switch {
+			case dx == 0 && dz == 0:
+				b.set(10, 0, 10, 159<<4|11)
+			case abs(dx) == abs(dz) || dx == 0 || dz == 0:
+				b.set(10+dx, 0, 10+dz, 159<<4|1)
+			}
+		}
+	}
+
+	// Hidden treasure room, reached by digging through the blue clay
+	b.fill(7, -15, 7, 13, -9, 13, sandstone)
+	b.fill(8, -12, 8, 12, -10, 12, 0)
+	b.fill(9, -9, 9, 11, -1, 11, sandstone)
+	b.fill(10, -9, 10, 10, -1, 10, 0)
+	b.fill(9, -14, 9, 11, -14, 11, 46<<4)
+	b.set(10, -12, 10, 70<<4) // Stone pressure plate
+
+	b.chest(10, -12, 8, dirSouth, LootDesertPyramid)
+	b.chest(10, -12, 12, dirNorth, LootDesertPyramid)
+	b.chest(8, -12, 10, dirEast, LootDesertPyramid)
+	b.chest(12, -12, 10, dirWest, LootDesertPyramid)
+
+	var tnt [][3]int
+	for x := 9; x <= 11; x++ {
+		for z := 9; z <= 11; z++ {
+			tnt = append(tnt, [3]int{x, -14, z})
+		}
+	}
+	b.trap(TrapTNT, [][3]int{{10, -12, 10}}, tnt)
+}
+
+// buildJungleTemple builds a two-storey mossy cobblestone temple. Stairs lead
+// down to a basement split into two rooms, each with a chest guarded by a
+// tripwire that fires the dispenser in the back wall.
+func (b *templeBuilder) buildJungleTemple() {
+	for x := 0; x < 12; x++ {
+		for z := 0; z < 15; z++ {
+			b.foundation(x, -5, z, 4<<4)
+			b.fill(x, 1, z, x, 14, z, 0)
+		}
+	}
+
+	// Basement
+	b.fillCobble(0, -4, 0, 11, 0, 14)
+	b.fill(1, -3, 1, 10, -1, 13, 0)
+	b.fillCobble(4, -3, 1, 4, -1, 13)
+	b.fill(4, -3, 2, 4, -2, 2, 0) // Doorway between the corridor and the chest room
+
+	// Ground floor, upper storey and roof
+	for dy := 1; dy <= 3; dy++ {
+		for x := 0; x < 12; x++ {
+			for z := 0; z < 15; z++ {
+				if x == 0 || x == 11 || z == 0 || z == 14 {
+					b.set(x, dy, z, b.cobble(x, dy, z))
+				}
+			}
+		}
+	}
+	b.fill(5, 1, 0, 6, 2, 0, 0)
+	for z := 3; z <= 11; z += 4 {
+		b.set(0, 2, z, 0)
+		b.set(11, 2, z, 0)
+	}
+	b.fillCobble(0, 4, 0, 11, 4, 14)
+	for dy := 5; dy <= 7; dy++ {
+		for x := 2; x <= 9; x++ {
+			for z := 2; z <= 12; z++ {
+				if x == 2 || x == 9 || z == 2 || z == 12 {
+					b.set(x, dy, z, b.cobble(x, dy, z))
+				}
+			}
+		}
+	}
+	b.fill(5, 5, 2, 6, 6, 2, 0)
+	b.fillCobble(2, 8, 2, 9, 8, 12)
+	b.fillCobble(4, 9, 5, 7, 9, 9)
+
+	// Ladder from the ground floor up onto the first floor
+	for dy := 1; dy <= 4; dy++ {
+		b.set(10, dy, 13, 65<<4|facingMeta[b.dir(dirNorth)])
+	}
+
+	// Stairs down into the basement corridor
+	stairs := 67<<4 | stairsMeta[b.dir(dirNorth)]
+	b.set(1, 0, 4, 0)
+	b.set(1, 0, 5, 0)
+	b.set(1, -1, 4, stairs)
+	b.set(1, -2, 5, stairs)
+	b.set(1, -3, 6, stairs)
+
+	dispenser := 23<<4 | facingMeta[b.dir(dirNorth)]
+
+	// Corridor: tripwire across, chest at the end
+	b.trap(TrapArrows, b.tripwire(1, 3, -3, 9), [][3]int{{2, -2, 14}})
+	b.set(2, -2, 14, dispenser)
+	b.chest(2, -3, 13, dirNorth, LootJungleTemple)
+
+	// Chest room
+	b.trap(TrapArrows, b.tripwire(5, 10, -3, 7), [][3]int{{7, -2, 14}})
+	b.set(7, -2, 14, dispenser)
+	b.chest(9, -3, 13, dirNorth, LootJungleTemple)
+}
+
+// buildWitchHut builds a spruce hut on oak log stilts with a cauldron, a
+// chest and a witch inside. A pressure plate behind the door fires a
+// dispenser in the back wall.
+func (b *templeBuilder) buildWitchHut() {
+	const planks = 5<<4 | 1 // Spruce
+	const log = 17 << 4     // Oak
+	const fence = 85 << 4
+
+	b.fill(0, 0, 0, 6, 6, 8, 0)
+	for _, c := range [][2]int{{1, 2}, {5, 2}, {1, 8}, {5, 8}} {
+		b.foundation(c[0], -1, c[1], log)
+	}
+
+	b.fill(1, 0, 1, 5, 0, 8, planks)
+	for dy := 1; dy <= 3; dy++ {
+		for x := 1; x <= 5; x++ {
+			for z := 2; z <= 8; z++ {
+				corner := (x == 1 || x == 5) && (z == 2 || z == 8)
+				switch {
+				case corner:
+					b.set(x, dy, z, log)
+				case x == 1 || x == 5 || z == 2 || z == 8:
+					b.set(x, dy, z, planks)
+				}
+			}
+		}
+		b.set(1, dy, 1, fence) // Porch posts
+		b.set(5, dy, 1, fence)
+	}
+	b.set(3, 1, 2, 0)
+	b.set(3, 2, 2, 0)
+	b.set(1, 2, 5, fence)
+	b.set(5, 2, 5, fence)
+
+	// Pitched roof
+	up := 134<<4 | stairsMeta[b.dir(dirEast)]
+	down := 134<<4 | stairsMeta[b.dir(dirWest)]
+	for z := 0; z <= 8; z++ {
+		for i := 0; i < 3; i++ {
+			b.set(i, 4+i, z, up)
+			b.set(6-i, 4+i, z, down)
+			b.fill(i+1, 4+i, z, 5-i, 4+i, z, planks)
+		}
+	}
+
+	b.set(2, 1, 7, 118<<4) // Cauldron
+	b.set(4, 1, 7, 58<<4)  // Crafting table
+	b.chest(4, 1, 4, dirWest, LootWitchHut)
+	b.mob(3, 1, 5, "Witch")
+
+	b.set(3, 1, 3, 72<<4) // Wooden pressure plate
+	b.set(3, 2, 8, 23<<4|facingMeta[b.dir(dirNorth)])
+	b.trap(TrapArrows, [][3]int{{3, 1, 3}}, [][3]int{{3, 2, 8}})
+}
+
+// buildIgloo builds a snow dome. A trapdoor in its floor hides a ladder down
+// to a stone brick basement with a chest, guarded by a pressure plate over TNT.
+func (b *templeBuilder) buildIgloo() {
+	const snow = 80 << 4
+	const bricks = 98 << 4
+
+	b.fill(0, 1, 0, 6, 6, 6, 0)
+	for x := 0; x < 7; x++ {
+		for z := 0; z < 7; z++ {
+			dx, dz := abs(x-3), abs(z-3)
+			if dx == 3 && dz == 3 {
+				continue
+			}
+			b.foundation(x, -1, z, snow)
+			b.set(x, 0, z, snow)
+			switch max(dx, dz) {
+			case 3:
+				b.set(x, 1, z, snow)
+				b.set(x, 2, z, snow)
+			case 2:
+				b.set(x, 3, z, snow)
+			default:
+				b.set(x, 4, z, snow)
+			}
+		}
+	}
+	b.fill(3, 1, 0, 3, 2, 0, 0)
+	b.set(1, 1, 2, 61<<4|facingMeta[b.dir(dirEast)]) // Furnace
+	b.set(5, 1, 2, 58<<4)                            // Crafting table
+
+	// Basement and the ladder shaft down to it
+	b.fill(0, -12, 0, 6, -5, 6, bricks)
+	b.fill(1, -9, 1, 5, -7, 5, 0)
+	b.fill(2, -4, 4, 4, -1, 6, bricks)
+	b.set(3, 0, 5, 96<<4) // Trapdoor
+	ladder := 65<<4 | facingMeta[b.dir(dirNorth)]
+	for dy := -9; dy <= -1; dy++ {
+		b.set(3, dy, 5, ladder)
+	}
+
+	b.chest(3, -9, 1, dirSouth, LootIgloo)
+	b.set(3, -9, 2, 70<<4) // Stone pressure plate
+	var tnt [][3]int
+	for x := 2; x <= 4; x++ {
+		for z := 1; z <= 3; z++ {
+			b.set(x, -11, z, 46<<4)
+			tnt = append(tnt, [3]int{x, -11, z})
+		}
+	}
+	b.trap(TrapTNT, [][3]int{{3, -9, 2}}, tnt)
+}
+
+// mob records a mob living in the structure.
+func (b *templeBuilder) mob(x, dy, z int, name string) {
+	pos := b.pos(x, dy, z)
+	if _, _, _, ok := b.local(pos); ok {
+		b.features.Mobs = append(b.features.Mobs, StructureMob{Pos: pos, Mob: name})
+	}
+}
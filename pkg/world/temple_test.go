@@ -0,0 +1,131 @@
+package world
+
+import "testing"
+
+// findTemple returns the structure of the given kind nearest the origin.
+func findTemple(t *testing.T, g *Generator, kind TempleKind) *TemplePlan {
+	t.Helper()
+	p, ok := g.FindTemple(kind, 0, 0, 12)
+	if !ok {
+		t.Fatalf("no %v within 12 cells", kind)
+	}
+	return p
+}
+
+func TestTemplePlacementMatchesBiome(t *testing.T) {
+	g := NewGenerator(12345)
+	want := map[TempleKind]*Biome{
+		DesertPyramid: BiomeDesert,
+		JungleTemple:  BiomeJungle,
+		WitchHut:      BiomeSwampland,
+		Igloo:         BiomeSnowyTundra,
+	}
+	for cx := -6; cx <= 6; cx++ {
+		for cz := -6; cz <= 6; cz++ {
+			p, ok := g.temples.Site(cx, cz)
+			if !ok {
+				continue
+			}
+			if b := g.Biome(p.X, p.Z); b != want[p.Kind] {
+				t.Errorf("%v at (%d, %d) is in biome %s", p.Kind, p.X, p.Z, b.Name)
+			}
+			if p.X < cx*templeCellSize || p.X+p.Width > (cx+1)*templeCellSize {
+				t.Errorf("%v at x=%d leaves cell %d", p.Kind, p.X, cx)
+			}
+		}
+	}
+}
+
+func TestTemplesBuilt(t *testing.T) {
+	tests := []struct {
+		kind    TempleKind
+		table   string
+		chests  int
+		traps   int
+		trigger uint16 // Block ID of the first trap's trigger
+		target  uint16 // Block ID of the first trap's targets
+	}{
+		{DesertPyramid, LootDesertPyramid, 4, 1, 70, 46},
+		{JungleTemple, LootJungleTemple, 2, 2, 131, 23},
+		{WitchHut, LootWitchHut, 1, 1, 72, 23},
+		{Igloo, LootIgloo, 1, 1, 70, 46},
+	}
+	g := NewGenerator(12345)
+	for _, tt := range tests {
+		t.Run(tt.kind.String(), func(t *testing.T) {
+			p := findTemple(t, g, tt.kind)
+
+			chunks := make(map[ChunkPos][SectionsPerChunk][ChunkSectionSize]uint16)
+			var features ChunkFeatures
+			for cx := divFloor(p.X, 16); cx <= divFloor(p.X+p.Width-1, 16); cx++ {
+				for cz := divFloor(p.Z, 16); cz <= divFloor(p.Z+p.Depth-1, 16); cz++ {
+					sections, _, f := g.GenerateWithFeatures(cx, cz)
+					chunks[ChunkPos{int32(cx), int32(cz)}] = sections
+					features.Chests = append(features.Chests, f.Chests...)
+					features.Traps = append(features.Traps, f.Traps...)
+					features.Mobs = append(features.Mobs, f.Mobs...)
+				}
+			}
+			blockAt := func(pos BlockPos) uint16 {
+				x, y, z := int(pos.X), int(pos.Y), int(pos.Z)
+				sections := chunks[ChunkPos{int32(divFloor(x, 16)), int32(divFloor(z, 16))}]
+				return sections[y/16][((y%16)*16+z-divFloor(z, 16)*16)*16+x-divFloor(x, 16)*16] >> 4
+			}
+
+			chests := 0
+			for _, c := range features.Chests {
+				if c.Table != tt.table {
+					continue // Dungeon or mineshaft chest under the structure
+				}
+				chests++
+				if !p.Contains(int(c.Pos.X), int(c.Pos.Z)) {
+					t.Errorf("chest at %v outside the footprint", c.Pos)
+				}
+				if blockAt(c.Pos) != 54 {
+					t.Errorf("no chest block at %v", c.Pos)
+				}
+			}
+			if chests != tt.chests {
+				t.Errorf("found %d chests, want %d", chests, tt.chests)
+			}
+
+			if len(features.Traps) != tt.traps {
+				t.Fatalf("found %d traps, want %d", len(features.Traps), tt.traps)
+			}
+			trap := features.Traps[0]
+			if b := blockAt(trap.Triggers[0]); b != tt.trigger {
+				t.Errorf("trigger at %v is block %d, want %d", trap.Triggers[0], b, tt.trigger)
+			}
+			for _, target := range trap.Targets {
+				if b := blockAt(target); b != tt.target {
+					t.Errorf("trap target at %v is block %d, want %d", target, b, tt.target)
+				}
+			}
+
+			if tt.kind == WitchHut && (len(features.Mobs) != 1 || features.Mobs[0].Mob != "Witch") {
+				t.Errorf("witch hut mobs = %v, want one witch", features.Mobs)
+			}
+		})
+	}
+}
+
+func TestFindTemple(t *testing.T) {
+	g := NewGenerator(12345)
+	p := findTemple(t, g, Igloo)
+	dist := func(p *TemplePlan) int {
+		dx, dz := p.X+p.Width/2, p.Z+p.Depth/2
+		return dx*dx + dz*dz
+	}
+	for cx := -12; cx <= 12; cx++ {
+		for cz := -12; cz <= 12; cz++ {
+			if q, ok := g.temples.Site(cx, cz); ok && q.Kind == Igloo && dist(q) < dist(p) {
+				t.Errorf("igloo at (%d, %d) is closer than the one found at (%d, %d)", q.X, q.Z, p.X, p.Z)
+			}
+		}
+	}
+	if _, ok := g.FindTemple(DesertPyramid, 0, 0, 0); ok {
+		if q, _ := g.temples.Site(0, 0); q == nil || q.Kind != DesertPyramid {
+			t.Error("FindTemple with radius 0 looked beyond the origin cell")
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package world
 
-import "sync"
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
 
 // VillageGrid handles deterministic village placement on a sparse grid.
 // Every VILLAGE_CELL_SIZE blocks in X and Z forms a grid cell; each cell
@@ -41,74 +45,54 @@ type buildingSite struct {
 	smallOnly    bool
 }
 
-// VillageGrid carries the world seed used for per-cell rolls.
 type cellKey struct {
 	x, z int
 }
 
-type centerResult struct {
+// villageSite is the world-space center of a village.
+type villageSite struct {
 	wx, wz int
-	ok     bool
 }
 
+// VillageGrid carries the world seed used for per-cell rolls.
 type VillageGrid struct {
 	seed       int64
 	tempNoise  *Perlin
 	rainNoise  *Perlin
 	heightFunc func(x, z int) int
+	sites      *StructureGrid[villageSite]
 
-	mu          sync.RWMutex
-	centerCache map[cellKey]centerResult
-	planCache   map[cellKey]*VillagePlan
+	mu        sync.RWMutex
+	planCache map[cellKey]*VillagePlan
 }
 
 // NewVillageGrid creates a VillageGrid for the given world seed and biome noise.
 func NewVillageGrid(seed int64, tempNoise, rainNoise *Perlin, heightFunc func(x, z int) int) *VillageGrid {
-	return &VillageGrid{
-		seed:        seed,
-		tempNoise:   tempNoise,
-		rainNoise:   rainNoise,
-		heightFunc:  heightFunc,
-		centerCache: make(map[cellKey]centerResult),
-		planCache:   make(map[cellKey]*VillagePlan),
-	}
+	v := &VillageGrid{
+		seed:       seed,
+		tempNoise:  tempNoise,
+		rainNoise:  rainNoise,
+		heightFunc: heightFunc,
+		planCache:  make(map[cellKey]*VillagePlan),
+	}
+	v.sites = NewStructureGrid(seed, 0, villageCellSize, func(cellX, cellZ int) (villageSite, bool) {
+		wx, wz, ok := v.calculateVillageCenter(cellX, cellZ)
+		return villageSite{wx, wz}, ok
+	})
+	return v
 }
 
 // cellHash returns a deterministic value in [0, mod) for cell (cx, cz).
 func (v *VillageGrid) cellHash(cx, cz, mod int64) int64 {
-	const k1 int64 = -7046029254386353131 // splitmix64 step 1
-	const k2 int64 = -4265267296055464877 // splitmix64 step 2
-	h := v.seed ^ (cx * k1) ^ (cz * 7823434773480878946)
-	h ^= h >> 33
-	h *= k1
-	h ^= h >> 27
-	h *= k2
-	h ^= h >> 31
-	if h < 0 {
-		h = -h
-	}
-	return h % mod
+	return v.sites.CellHash(cx, cz, mod)
 }
 
 // villageCenter returns the world-space (x, z) center of a village in grid cell
 // (cellX, cellZ), and ok=true if that cell contains a village (25% chance)
 // AND no neighboring village is too close (minimum 80 blocks apart).
 func (v *VillageGrid) villageCenter(cellX, cellZ int) (int, int, bool) {
-	key := cellKey{cellX, cellZ}
-	v.mu.RLock()
-	if res, ok := v.centerCache[key]; ok {
-		v.mu.RUnlock()
-		return res.wx, res.wz, res.ok
-	}
-	v.mu.RUnlock()
-
-	wx, wz, ok := v.calculateVillageCenter(cellX, cellZ)
-
-	v.mu.Lock()
-	v.centerCache[key] = centerResult{wx, wz, ok}
-	v.mu.Unlock()
-
-	return wx, wz, ok
+	site, ok := v.sites.Site(cellX, cellZ)
+	return site.wx, site.wz, ok
 }
 
 func (v *VillageGrid) calculateVillageCenter(cellX, cellZ int) (wx, wz int, ok bool) {
@@ -186,7 +170,7 @@ func divFloor(a, b int) int {
 
 // generateVillage writes village structures into sections for chunk (chunkX, chunkZ).
 // All coordinates stay inside the section array exactly like generateTrees does.
-func (v *VillageGrid) generateVillage(chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16) {
+func (v *VillageGrid) generateVillage(chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16, features *ChunkFeatures) {
 	// Chunk world-space bounds
 	minWX := chunkX * 16
 	minWZ := chunkZ * 16
@@ -217,7 +201,7 @@ func (v *VillageGrid) generateVillage(chunkX, chunkZ int, sections *[SectionsPer
 				surfY = WaterLevel // Don't build villages underwater
 			}
 
-			v.buildVillage(vx, vz, surfY, chunkX, chunkZ, sections)
+			v.buildVillage(vx, vz, surfY, chunkX, chunkZ, sections, features)
 		}
 	}
 }
@@ -611,7 +595,7 @@ func (v *VillageGrid) calculateVillagePlan(vx, vz int) *VillagePlan {
 	}
 
 	var fallback []buildingSite
-	hasHall, hasChurch, hasMarket := false, false, false
+	hasHall, hasChurch, hasMarket, hasSmithy := false, false, false, false
 	for i, site := range sites {
 		h := v.cellHash(int64(site.doorX+i*7), int64(site.doorZ+i*13+3), 100)
 		if h < 20 {
@@ -626,6 +610,8 @@ func (v *VillageGrid) calculateVillagePlan(vx, vz int) *VillagePlan {
 				stype = 3
 			} else if h >= 70 && !hasMarket {
 				stype = 4
+			} else if h >= 60 && !hasSmithy {
+				stype = 5
 			}
 		}
 		var bx, bz, ex, ez int
@@ -642,6 +628,13 @@ func (v *VillageGrid) calculateVillagePlan(vx, vz int) *VillagePlan {
 		case 4:
 			ax, az := doorToAnchorMarketplace(site.doorX, site.doorZ, site.facing)
 			bx, bz, ex, ez = ax-1, az-1, ax+14, az+14
+		case 5:
+			ax, az := doorToAnchorBlacksmith(site.doorX, site.doorZ, site.facing)
+			if site.facing < 2 {
+				bx, bz, ex, ez = ax-1, az-1, ax+10, az+7
+			} else {
+				bx, bz, ex, ez = ax-1, az-1, ax+7, az+10
+			}
 		}
 		newB := bbox{bx, bz, ex, ez}
 		collides := false
@@ -669,6 +662,8 @@ func (v *VillageGrid) calculateVillagePlan(vx, vz int) *VillagePlan {
 			hasChurch = true
 		} else if stype == 4 {
 			hasMarket = true
+		} else if stype == 5 {
+			hasSmithy = true
 		}
 		placed = append(placed, newB)
 		plan.Buildings = append(plan.Buildings, struct {
@@ -900,7 +895,23 @@ func doorToAnchorMarketplace(doorX, doorZ, facing int) (int, int) {
 	return doorX, doorZ
 }
 
-func (v *VillageGrid) buildVillage(vx, vz, surfY, chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16) {
+// doorToAnchorBlacksmith computes the anchor for the 10x7 blacksmith, which
+// is 7x10 when its door faces along X.
+func doorToAnchorBlacksmith(doorX, doorZ, facing int) (int, int) {
+	switch facing {
+	case 0: // door at (hx+2, hz+6)
+		return doorX - 2, doorZ - 6
+	case 1: // door at (hx+7, hz)
+		return doorX - 7, doorZ
+	case 2: // door at (hx+6, hz+7)
+		return doorX - 6, doorZ - 7
+	case 3: // door at (hx, hz+2)
+		return doorX, doorZ - 2
+	}
+	return doorX, doorZ
+}
+
+func (v *VillageGrid) buildVillage(vx, vz, surfY, chunkX, chunkZ int, sections *[SectionsPerChunk][ChunkSectionSize]uint16, features *ChunkFeatures) {
 	originX := chunkX * 16
 	originZ := chunkZ * 16
 	maxWX := originX + 15
@@ -933,6 +944,9 @@ func (v *VillageGrid) buildVillage(vx, vz, surfY, chunkX, chunkZ int, sections *
 		poppy        uint16 = 38 << 4  // poppy flower
 		goldBlock    uint16 = 41 << 4  // gold block (bell)
 		bed          uint16 = 26 << 4  // bed
+		chest        uint16 = 54 << 4  // chest
+		furnace      uint16 = 61 << 4  // furnace
+		lava         uint16 = 11 << 4  // lava (stationary)
 	)
 
 	log := biome.VillageLog
@@ -951,11 +965,23 @@ func (v *VillageGrid) buildVillage(vx, vz, surfY, chunkX, chunkZ int, sections *
 		return x
 	}
 
+	// chestTable is the loot table of chests placed by the building being
+	// built; they are recorded as loot chests of this chunk.
+	chestTable := ""
+
 	placeBlock := func(wx, y, wz int, state uint16) {
 		lx := wx - originX
 		lz := wz - originZ
 		setBlock(lx, y, lz, state, sections)
 
+		if state>>4 == chest>>4 && chestTable != "" && lx >= 0 && lx < 16 && lz >= 0 && lz < 16 {
+			features.Chests = append(features.Chests, LootChest{
+				Pos:   BlockPos{int32(wx), int32(y), int32(wz)},
+				Table: chestTable,
+				Seed:  v.sites.CellSeed(int64(wx), int64(wz)) + int64(y),
+			})
+		}
+
 		// Foundation support: if this is at the base floor level (surfY),
 		// fill down to solid ground to avoid floating buildings.
 		if y == surfY {
@@ -1124,20 +1150,25 @@ func (v *VillageGrid) buildVillage(vx, vz, surfY, chunkX, chunkZ int, sections *
 		}
 
 		dx, dz, fac := b.Site.doorX, b.Site.doorZ, b.Site.facing
+		chestTable = buildingLoot[b.Type]
 		switch b.Type {
 		case 1:
 			hx, hz := doorToAnchorHouse(dx, dz, fac)
-			v.buildHouse(hx, hz, surfY, fac, placeBlock, log, planks, cobble, glass, woodFence, torch, woodStairs, woodenDoor, air, woodSlab, bed)
+			v.buildHouse(hx, hz, surfY, fac, placeBlock, log, planks, cobble, glass, woodFence, torch, woodStairs, woodenDoor, air, woodSlab, bed, chest)
 		case 2:
 			hx, hz := doorToAnchorHall(dx, dz, fac)
 			v.buildLargeHall(hx, hz, surfY, fac, placeBlock, log, planks, cobble, glass, torch, woodStairs, woodenDoor, air, woodSlab, bed)
 		case 3:
 			hx, hz := doorToAnchorChurch(dx, dz, fac)
-			v.buildChurch(hx, hz, surfY, fac, placeBlock, log, planks, cobble, glass, torch, woodStairs, woodenDoor, slab, air, goldBlock, woodFence)
+			v.buildChurch(hx, hz, surfY, fac, placeBlock, log, planks, cobble, glass, torch, woodStairs, woodenDoor, slab, air, goldBlock, woodFence, chest)
 		case 4:
 			hx, hz := doorToAnchorMarketplace(dx, dz, fac)
 			v.buildMarketplace(hx, hz, surfY, fac, placeBlock, log, woodFence, woodSlab, pathBlock, torch, air)
+		case 5:
+			hx, hz := doorToAnchorBlacksmith(dx, dz, fac)
+			v.buildBlacksmith(hx, hz, surfY, fac, placeBlock, log, planks, cobble, glass, woodFence, slab, woodenDoor, air, chest, furnace, lava)
 		}
+		chestTable = ""
 		pw := 1
 		switch fac {
 		case 0:
@@ -1250,6 +1281,78 @@ func (v *VillageGrid) buildVillage(vx, vz, surfY, chunkX, chunkZ int, sections *
 	for i := range plan.Roads {
 		placeLamps(&plan.Roads[i])
 	}
+
+	// ------------------------------------------------------------------
+	// 7. Villagers, one per building, standing on its floor
+	// ------------------------------------------------------------------
+	minX, minZ, maxX, maxZ := plan.Bounds()
+	for _, b := range plan.Buildings {
+		sx, sz := (b.Box.minX+b.Box.maxX)/2, (b.Box.minZ+b.Box.maxZ)/2
+		lx, okX := worldToLocal(sx, originX)
+		lz, okZ := worldToLocal(sz, originZ)
+		if !okX || !okZ {
+			continue
+		}
+		get := func(y int) uint16 {
+			return sections[y/16][((y%16)*16+lz)*16+lx]
+		}
+		for y := surfY + 1; y < surfY+8; y++ {
+			if get(y) == air && get(y+1) == air && isSolidTerrain(get(y-1)) {
+				features.Villagers = append(features.Villagers, Villager{
+					Pos:        BlockPos{int32(sx), int32(y), int32(sz)},
+					Profession: buildingProfessions[b.Type],
+					Seed:       v.sites.CellSeed(int64(sx), int64(sz)),
+					MinX:       minX,
+					MinZ:       minZ,
+					MaxX:       maxX,
+					MaxZ:       maxZ,
+				})
+				break
+			}
+		}
+	}
+}
+
+// Villager professions, as sent in villager entity metadata.
+const (
+	ProfessionFarmer = iota
+	ProfessionLibrarian
+	ProfessionPriest
+	ProfessionBlacksmith
+	ProfessionButcher
+)
+
+// buildingProfessions is the profession of the villager living in each
+// building type: houses, halls, churches, marketplaces and blacksmiths.
+var buildingProfessions = map[int]int{
+	1: ProfessionFarmer,
+	2: ProfessionLibrarian,
+	3: ProfessionPriest,
+	4: ProfessionButcher,
+	5: ProfessionBlacksmith,
+}
+
+// buildingLoot is the loot table of the chests in each building type.
+var buildingLoot = map[int]string{
+	1: LootVillageHouse,
+	3: LootVillageChurch,
+	5: LootVillageBlacksmith,
+}
+
+// Bounds returns the area covered by the village's well, buildings and farms.
+func (p *VillagePlan) Bounds() (minX, minZ, maxX, maxZ int) {
+	minX, minZ, maxX, maxZ = p.VX-3, p.VZ-3, p.VX+3, p.VZ+3
+	grow := func(b bbox) {
+		minX, minZ = min(minX, b.minX), min(minZ, b.minZ)
+		maxX, maxZ = max(maxX, b.maxX), max(maxZ, b.maxZ)
+	}
+	for _, b := range p.Buildings {
+		grow(b.Box)
+	}
+	for _, f := range p.Farms {
+		grow(f.Box)
+	}
+	return minX, minZ, maxX, maxZ
 }
 
 // buildMarketplace creates an open market with 4 stall structures and a central path cross.
@@ -1494,7 +1597,7 @@ func (v *VillageGrid) buildLargeHall(hx, hz, surfY, facing int, place func(wx, y
 
 // buildHouse places a 5x5 plank house with a solid symmetrical facade.
 // facing: 0=door on +Z wall, 1=door on -Z wall, 2=door on +X wall, 3=door on -X wall
-func (v *VillageGrid) buildHouse(hx, hz, surfY, facing int, place func(wx, y, wz int, state uint16), log, planks, cobble, glass, fence, torch, stairs, door, air, woodSlab, bed uint16) {
+func (v *VillageGrid) buildHouse(hx, hz, surfY, facing int, place func(wx, y, wz int, state uint16), log, planks, cobble, glass, fence, torch, stairs, door, air, woodSlab, bed, chest uint16) {
 	const w = 5
 	const h = 3
 
@@ -1580,6 +1683,18 @@ func (v *VillageGrid) buildHouse(hx, hz, surfY, facing int, place func(wx, y, wz
 		place(hx+3, surfY+1, hz+3, bed|11)
 	}
 
+	// Chest in the back corner beside the bed, facing the door
+	switch facing {
+	case 0:
+		place(hx+3, surfY+1, hz+1, chest|3)
+	case 1:
+		place(hx+1, surfY+1, hz+3, chest|2)
+	case 2:
+		place(hx+1, surfY+1, hz+3, chest|5)
+	case 3:
+		place(hx+3, surfY+1, hz+1, chest|4)
+	}
+
 	// Pitched Roof - ridge perpendicular to door wall for variety
 	if facing == 0 || facing == 1 {
 		// Ridge runs along X axis, slopes face N/S
@@ -1633,7 +1748,7 @@ func (v *VillageGrid) buildHouse(hx, hz, surfY, facing int, place func(wx, y, wz
 // buildChurch creates a polished village church with a ground-level entrance and open belfry.
 // facing: 0=door on +Z wall, 1=door on -Z wall.
 // For facing=0 the entire structure is mirrored along Z.
-func (v *VillageGrid) buildChurch(hx, hz, surfY, facing int, place func(wx, y, wz int, state uint16), log, planks, cobble, glass, torch, stairs, door, slab, air, bell, fence uint16) {
+func (v *VillageGrid) buildChurch(hx, hz, surfY, facing int, place func(wx, y, wz int, state uint16), log, planks, cobble, glass, torch, stairs, door, slab, air, bell, fence, chest uint16) {
 	// For facing=0, mirror the Z axis. The church extends 11 blocks in Z (0..10).
 	// We mirror: dz -> 10 - dz, and swap door meta and stair orientations.
 	const totalZ = 10 // hallMaxZ
@@ -1653,6 +1768,13 @@ func (v *VillageGrid) buildChurch(hx, hz, surfY, facing int, place func(wx, y, w
 					meta = 3
 				}
 				state = (blockID << 4) | meta
+			} else if blockID == chest>>4 {
+				if meta == 2 {
+					meta = 3 // north -> south
+				} else if meta == 3 {
+					meta = 2
+				}
+				state = (blockID << 4) | meta
 			} else if blockID == 67 { // cobblestone stairs
 				if meta == 2 {
 					meta = 3 // south -> north
@@ -1880,4 +2002,158 @@ func (v *VillageGrid) buildChurch(hx, hz, surfY, facing int, place func(wx, y, w
 
 	place(hx+2, surfY+1, hz+hallMaxZ-1, cobble) // Altar
 	place(hx+2, surfY+2, hz+hallMaxZ-1, torch|5)
+	place(hx+1, surfY+1, hz+hallMaxZ-1, chest|2) // Beside the altar, facing the door
+}
+
+// buildBlacksmith creates a smithy: a small plank room holding the loot
+// chest beside an open forge with a lava basin and two furnaces, all under
+// a flat stone slab roof.
+// Layout is given in local (u, d) coordinates, u along the 10-block front
+// wall and d from the front (d=0) to the back wall (d=6); it is rotated so
+// the front faces the road.
+// facing: 0=door on +Z wall, 1=door on -Z wall, 2=door on +X wall, 3=door on -X wall
+func (v *VillageGrid) buildBlacksmith(hx, hz, surfY, facing int, place func(wx, y, wz int, state uint16), log, planks, cobble, glass, fence, slab, door, air, chest, furnace, lava uint16) {
+	const w, l = 10, 7
+	const h = 3
+
+	at := func(u, dy, d int, state uint16) {
+		switch facing {
+		case 0:
+			place(hx+u, surfY+dy, hz+l-1-d, state)
+		case 1:
+			place(hx+w-1-u, surfY+dy, hz+d, state)
+		case 2:
+			place(hx+l-1-d, surfY+dy, hz+w-1-u, state)
+		case 3:
+			place(hx+d, surfY+dy, hz+u, state)
+		}
+	}
+	doorMetas := [4]uint16{1, 3, 2, 0}  // +Z, -Z, +X, -X
+	frontMetas := [4]uint16{3, 2, 5, 4} // Chests and furnaces facing the front
+
+	for u := 0; u < w; u++ {
+		for d := 0; d < l; d++ {
+			at(u, 0, d, cobble)
+			for dy := 1; dy <= h; dy++ {
+				at(u, dy, d, air)
+			}
+			at(u, h+1, d, slab)
+		}
+	}
+
+	// Room: u 0..3, plank walls with log corners
+	for u := 0; u <= 3; u++ {
+		for d := 0; d < l; d++ {
+			isWallU := u == 0 || u == 3
+			isWallD := d == 0 || d == l-1
+			if !isWallU && !isWallD {
+				continue
+			}
+			for dy := 1; dy <= h; dy++ {
+				block := planks
+				if isWallU && isWallD {
+					block = log
+				} else if dy == 2 && ((u == 0 && d == 3) || (d == l-1 && (u == 1 || u == 2))) {
+					block = glass
+				}
+				at(u, dy, d, block)
+			}
+		}
+	}
+	at(2, 1, 0, door|doorMetas[facing])
+	at(2, 2, 0, door|8)
+	at(1, 1, l-2, chest|frontMetas[facing])
+
+	// Forge: u 4..9, open at the front with fence posts holding up the roof
+	for dy := 1; dy <= h; dy++ {
+		at(w-1, dy, 0, fence)
+		for u := 4; u < w; u++ {
+			at(u, dy, l-1, cobble)
+		}
+		for d := 4; d < l-1; d++ {
+			at(w-1, dy, d, cobble)
+		}
+	}
+	at(5, 1, l-1, furnace|frontMetas[facing])
+	at(6, 1, l-1, furnace|frontMetas[facing])
+	// Lava basin against the back wall
+	at(6, 1, 5, cobble)
+	at(7, 1, 4, cobble)
+	at(8, 1, 4, cobble)
+	at(7, 1, 5, lava)
+	at(8, 1, 5, lava)
+}
+
+// buildingNames names each building type in VillagePlan.Describe.
+var buildingNames = map[int]string{
+	1: "house",
+	2: "hall",
+	3: "church",
+	4: "marketplace",
+	5: "blacksmith",
+}
+
+// facingNames names the building facings in VillagePlan.Describe.
+var facingNames = [4]string{"+Z", "-Z", "+X", "-X"}
+
+// VillagePlanAt returns the plan of the village whose buildings, farms or
+// roads cover world column (x, z), if there is one.
+func (g *Generator) VillagePlanAt(x, z int) (*VillagePlan, bool) {
+	v := g.villageGen
+	cellX, cellZ := v.sites.CellAt(x, z)
+	for cx := cellX - 1; cx <= cellX+1; cx++ {
+		for cz := cellZ - 1; cz <= cellZ+1; cz++ {
+			vx, vz, ok := v.villageCenter(cx, cz)
+			if !ok {
+				continue
+			}
+			plan := v.planVillage(vx, vz)
+			minX, minZ, maxX, maxZ := plan.footprint()
+			if x >= minX && x <= maxX && z >= minZ && z <= maxZ {
+				return plan, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// footprint returns the area covered by the village, which is its Bounds
+// widened to take in the roads.
+func (p *VillagePlan) footprint() (minX, minZ, maxX, maxZ int) {
+	minX, minZ, maxX, maxZ = p.Bounds()
+	var grow func(seg *roadSegment)
+	grow = func(seg *roadSegment) {
+		minX, minZ = min(minX, seg.sx-2, seg.ex-2), min(minZ, seg.sz-2, seg.ez-2)
+		maxX, maxZ = max(maxX, seg.sx+2, seg.ex+2), max(maxZ, seg.sz+2, seg.ez+2)
+		for i := range seg.children {
+			grow(&seg.children[i])
+		}
+	}
+	for i := range p.Roads {
+		grow(&p.Roads[i])
+	}
+	return minX, minZ, maxX, maxZ
+}
+
+// Describe lists the village's buildings, roads and farms, one per line.
+func (p *VillagePlan) Describe() []string {
+	lines := []string{fmt.Sprintf("Village with well at %d, %d: %d buildings, %d farms", p.VX, p.VZ, len(p.Buildings), len(p.Farms))}
+	for _, b := range p.Buildings {
+		lines = append(lines, fmt.Sprintf("Building: %s at %d..%d, %d..%d, door %d, %d facing %s",
+			buildingNames[b.Type], b.Box.minX, b.Box.maxX, b.Box.minZ, b.Box.maxZ, b.Site.doorX, b.Site.doorZ, facingNames[b.Site.facing]))
+	}
+	var roads func(seg *roadSegment, depth int)
+	roads = func(seg *roadSegment, depth int) {
+		lines = append(lines, fmt.Sprintf("%sRoad: %d, %d to %d, %d", strings.Repeat("  ", depth), seg.sx, seg.sz, seg.ex, seg.ez))
+		for i := range seg.children {
+			roads(&seg.children[i], depth+1)
+		}
+	}
+	for i := range p.Roads {
+		roads(&p.Roads[i], 0)
+	}
+	for _, f := range p.Farms {
+		lines = append(lines, fmt.Sprintf("Farm: %d..%d, %d..%d", f.Box.minX, f.Box.maxX, f.Box.minZ, f.Box.maxZ))
+	}
+	return lines
 }
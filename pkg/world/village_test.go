@@ -50,3 +50,123 @@ func TestVillageStructureContinuity(t *testing.T) {
 		t.Errorf("Village structures missing at boundaries: mask0=0x%x, mask1=0x%x", mask0, mask1)
 	}
 }
+
+func TestVillagersSpawnInBuildings(t *testing.T) {
+	g := NewGenerator(12345)
+	var plan *VillagePlan
+	for cx := -4; cx <= 4 && plan == nil; cx++ {
+		for cz := -4; cz <= 4 && plan == nil; cz++ {
+			if vx, vz, ok := g.villageGen.villageCenter(cx, cz); ok {
+				plan = g.villageGen.planVillage(vx, vz)
+			}
+		}
+	}
+	if plan == nil {
+		t.Fatal("no village in 81 cells")
+	}
+
+	minX, minZ, maxX, maxZ := plan.Bounds()
+	var villagers []Villager
+	for cx := divFloor(minX, 16); cx <= divFloor(maxX, 16); cx++ {
+		for cz := divFloor(minZ, 16); cz <= divFloor(maxZ, 16); cz++ {
+			sections, _, f := g.GenerateWithFeatures(cx, cz)
+			for _, v := range f.Villagers {
+				if v.MinX != minX || v.MinZ != minZ || v.MaxX != maxX || v.MaxZ != maxZ {
+					continue // From a neighbouring village
+				}
+				villagers = append(villagers, v)
+				lx, lz := int(v.Pos.X)-cx*16, int(v.Pos.Z)-cz*16
+				y := int(v.Pos.Y)
+				if sections[y/16][((y%16)*16+lz)*16+lx] != 0 {
+					t.Errorf("villager at %v spawns inside a block", v.Pos)
+				}
+			}
+		}
+	}
+	if len(villagers) != len(plan.Buildings) {
+		t.Errorf("%d villagers for %d buildings", len(villagers), len(plan.Buildings))
+	}
+	for _, v := range villagers {
+		if int(v.Pos.X) < minX || int(v.Pos.X) > maxX || int(v.Pos.Z) < minZ || int(v.Pos.Z) > maxZ {
+			t.Errorf("villager at %v outside the village", v.Pos)
+		}
+	}
+}
+
+func TestVillageBlacksmithAndChests(t *testing.T) {
+	g := NewGenerator(12345)
+	var plan *VillagePlan
+	for cx := -8; cx <= 8 && plan == nil; cx++ {
+		for cz := -8; cz <= 8 && plan == nil; cz++ {
+			vx, vz, ok := g.villageGen.villageCenter(cx, cz)
+			if !ok {
+				continue
+			}
+			p := g.villageGen.planVillage(vx, vz)
+			for _, b := range p.Buildings {
+				if b.Type == 5 {
+					plan = p
+				}
+			}
+		}
+	}
+	if plan == nil {
+		t.Fatal("no village with a blacksmith in 289 cells")
+	}
+
+	inside := func(pos BlockPos, box bbox) bool {
+		return int(pos.X) >= box.minX && int(pos.X) <= box.maxX && int(pos.Z) >= box.minZ && int(pos.Z) <= box.maxZ
+	}
+	for _, b := range plan.Buildings {
+		want := buildingLoot[b.Type]
+		chests, lava, furnaces, villagers := 0, 0, 0, 0
+		for cx := divFloor(b.Box.minX, 16); cx <= divFloor(b.Box.maxX, 16); cx++ {
+			for cz := divFloor(b.Box.minZ, 16); cz <= divFloor(b.Box.maxZ, 16); cz++ {
+				sections, _, f := g.GenerateWithFeatures(cx, cz)
+				for _, v := range f.Villagers {
+					if inside(v.Pos, b.Box) && v.Profession == buildingProfessions[b.Type] {
+						villagers++
+					}
+				}
+				for _, c := range f.Chests {
+					if !inside(c.Pos, b.Box) {
+						continue
+					}
+					chests++
+					lx, lz := int(c.Pos.X)-cx*16, int(c.Pos.Z)-cz*16
+					y := int(c.Pos.Y)
+					if sections[y/16][((y%16)*16+lz)*16+lx]>>4 != 54 {
+						t.Errorf("no chest block at %v", c.Pos)
+					}
+					if c.Table != want {
+						t.Errorf("building type %d chest uses table %q, want %q", b.Type, c.Table, want)
+					}
+				}
+				for lx := 0; lx < 16; lx++ {
+					for lz := 0; lz < 16; lz++ {
+						if !inside(BlockPos{int32(cx*16 + lx), 0, int32(cz*16 + lz)}, b.Box) {
+							continue
+						}
+						for y := 0; y < 256; y++ {
+							switch sections[y/16][((y%16)*16+lz)*16+lx] >> 4 {
+							case 11:
+								lava++
+							case 61:
+								furnaces++
+							}
+						}
+					}
+				}
+			}
+		}
+		if want != "" && chests != 1 {
+			t.Errorf("building type %d has %d loot chests, want 1", b.Type, chests)
+		}
+		if villagers != 1 {
+			t.Errorf("building type %d has %d villagers, want 1", b.Type, villagers)
+		}
+		if b.Type == 5 && (lava == 0 || furnaces == 0) {
+			t.Errorf("blacksmith has %d lava and %d furnaces", lava, furnaces)
+		}
+	}
+}
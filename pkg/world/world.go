@@ -4,6 +4,7 @@ import (
 	"math/rand"
 	"runtime"
 	"sync"
+	"time"
 )
 
 // BlockPos represents a block position in the world.
@@ -20,32 +21,52 @@ type ChunkPos struct {
 type Chunk struct {
 	Sections [SectionsPerChunk][ChunkSectionSize]uint16
 	Biomes   [256]byte
+	Features ChunkFeatures // Loot chests, spawners and minecarts placed by the generator
+
+	idleSince time.Time // When the chunk last became unreferenced (or was realized unreferenced)
 }
 
 // World tracks the state of all blocks, including modifications and cached chunks.
+//
+// Realized chunks are reference counted: players retain the chunks in their
+// view area and release them when they move away or disconnect. Chunks that
+// stay unreferenced past a grace period are evicted by EvictIdleChunks; the
+// block overrides are kept, so an evicted chunk regenerates identically.
 type World struct {
-	mu     sync.RWMutex
-	blocks map[BlockPos]uint16 // manual block overrides (set by SetBlock)
-	chunks map[ChunkPos]*Chunk // realized chunk cache
-	Gen    *Generator
-	genSem chan struct{} // Semaphore to limit concurrent chunk generations
+	mu        sync.RWMutex
+	overrides map[ChunkPos]map[BlockPos]uint16 // manual block overrides (set by SetBlock), grouped by chunk
+	chunks    map[ChunkPos]*Chunk              // realized chunk cache
+	refs      map[ChunkPos]int                 // retain counts, including chunks not realized yet
+	Gen       ChunkGenerator
+	genSem    chan struct{} // Semaphore to limit concurrent chunk generations
 }
 
-// NewWorld creates a new World with the given seed for terrain generation.
+// NewWorld creates a new World with the given seed for default terrain generation.
 func NewWorld(seed int64) *World {
+	return NewWorldWithGenerator(NewGenerator(seed))
+}
+
+// NewWorldWithGenerator creates a new World whose terrain comes from gen.
+func NewWorldWithGenerator(gen ChunkGenerator) *World {
 	// Limit concurrent chunk generation to the number of CPUs, minimum 2
 	maxConcurrent := runtime.NumCPU()
 	if maxConcurrent < 2 {
 		maxConcurrent = 2
 	}
 	return &World{
-		blocks: make(map[BlockPos]uint16),
-		chunks: make(map[ChunkPos]*Chunk),
-		Gen:    NewGenerator(seed),
-		genSem: make(chan struct{}, maxConcurrent),
+		overrides: make(map[ChunkPos]map[BlockPos]uint16),
+		chunks:    make(map[ChunkPos]*Chunk),
+		refs:      make(map[ChunkPos]int),
+		Gen:       gen,
+		genSem:    make(chan struct{}, maxConcurrent),
 	}
 }
 
+// sectionIndex returns the index of a block inside its chunk section.
+func sectionIndex(x, y, z int32) int32 {
+	return ((y&0x0F)*16+(z&0x0F))*16 + (x & 0x0F)
+}
+
 // GetBlock returns the block state (blockID << 4 | metadata) at the given position.
 func (w *World) GetBlock(x, y, z int32) uint16 {
 	if y < 0 || y > 255 {
@@ -53,58 +74,58 @@ func (w *World) GetBlock(x, y, z int32) uint16 {
 	}
 
 	w.mu.RLock()
+	cp := ChunkPos{x >> 4, z >> 4}
 	// Check overrides first
-	if b, ok := w.blocks[BlockPos{x, y, z}]; ok {
+	if b, ok := w.overrides[cp][BlockPos{x, y, z}]; ok {
 		w.mu.RUnlock()
 		return b
 	}
 
 	// Check chunk cache
-	cp := ChunkPos{x >> 4, z >> 4}
 	if chunk, ok := w.chunks[cp]; ok {
 		w.mu.RUnlock()
-		lx, ly, lz := x&0x0F, y&0x0F, z&0x0F
-		sec := y >> 4
-		return chunk.Sections[sec][(ly*16+lz)*16+lx]
+		return chunk.Sections[y>>4][sectionIndex(x, y, z)]
 	}
 	w.mu.RUnlock()
 
+	chunk := w.realize(cp)
+	return chunk.Sections[y>>4][sectionIndex(x, y, z)]
+}
+
+// realize generates the chunk at cp and adds it to the cache, applying the
+// chunk's block overrides. If another goroutine realized it first, that chunk is returned.
+func (w *World) realize(cp ChunkPos) *Chunk {
 	// Realize chunk outside of lock to avoid stalling the entire server
 	// Apply global concurrency limit so multiple players logging in at once don't saturate CPU
 	w.genSem <- struct{}{}
-	sections, biomes := w.Gen.GenerateInternal(int(cp.X), int(cp.Z))
-	<-w.genSem
-
-	chunk := &Chunk{
-		Sections: sections,
-		Biomes:   biomes,
+	var chunk *Chunk
+	if fg, ok := w.Gen.(FeatureGenerator); ok {
+		sections, biomes, features := fg.GenerateWithFeatures(int(cp.X), int(cp.Z))
+		chunk = &Chunk{Sections: sections, Biomes: biomes, Features: features}
+	} else {
+		sections, biomes := w.Gen.GenerateInternal(int(cp.X), int(cp.Z))
+		chunk = &Chunk{Sections: sections, Biomes: biomes}
 	}
+	<-w.genSem
 
 	w.mu.Lock()
+	defer w.mu.Unlock()
 	// Check again in case another goroutine generated it while we were unlocked
 	if existingChunk, ok := w.chunks[cp]; ok {
-		w.mu.Unlock()
-		lx, ly, lz := x&0x0F, y&0x0F, z&0x0F
-		sec := y >> 4
-		return existingChunk.Sections[sec][(ly*16+lz)*16+lx]
+		return existingChunk
 	}
 
-	// Apply ALL existing overrides for this chunk to the realized chunk
+	// Apply the existing overrides for this chunk to the realized chunk
 	// This ensures consistency even if modifications happen before realization.
-	for pos, state := range w.blocks {
-		if pos.X>>4 == cp.X && pos.Z>>4 == cp.Z {
-			lx, ly, lz := pos.X&0x0F, pos.Y&0x0F, pos.Z&0x0F
-			sec := pos.Y >> 4
-			chunk.Sections[sec][(ly*16+lz)*16+lx] = state
-		}
+	for pos, state := range w.overrides[cp] {
+		chunk.Sections[pos.Y>>4][sectionIndex(pos.X, pos.Y, pos.Z)] = state
 	}
 
+	if w.refs[cp] == 0 {
+		chunk.idleSince = time.Now()
+	}
 	w.chunks[cp] = chunk
-	w.mu.Unlock()
-
-	lx, ly, lz := x&0x0F, y&0x0F, z&0x0F
-	sec := y >> 4
-	return chunk.Sections[sec][(ly*16+lz)*16+lx]
+	return chunk
 }
 
 // SetBlock sets the block state at the given position.
@@ -117,14 +138,17 @@ func (w *World) SetBlock(x, y, z int32, state uint16) {
 	defer w.mu.Unlock()
 
 	// Record the modification
-	w.blocks[BlockPos{x, y, z}] = state
+	cp := ChunkPos{x >> 4, z >> 4}
+	chunkOverrides, ok := w.overrides[cp]
+	if !ok {
+		chunkOverrides = make(map[BlockPos]uint16)
+		w.overrides[cp] = chunkOverrides
+	}
+	chunkOverrides[BlockPos{x, y, z}] = state
 
 	// Update cached chunk if it exists
-	cp := ChunkPos{x >> 4, z >> 4}
 	if chunk, ok := w.chunks[cp]; ok {
-		lx, ly, lz := x&0x0F, y&0x0F, z&0x0F
-		sec := y >> 4
-		chunk.Sections[sec][(ly*16+lz)*16+lx] = state
+		chunk.Sections[y>>4][sectionIndex(x, y, z)] = state
 	}
 }
 
@@ -140,46 +164,126 @@ func (w *World) GetChunkData(cx, cz int32) ([]byte, uint16) {
 	}
 	w.mu.RUnlock()
 
-	// Realize chunk outside of lock
-	// Apply global concurrency limit so multiple players logging in at once don't saturate CPU
-	w.genSem <- struct{}{}
-	sections, biomes := w.Gen.GenerateInternal(int(cx), int(cz))
-	<-w.genSem
+	chunk := w.realize(cp)
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return SerializeSections(&chunk.Sections, chunk.Biomes)
+}
+
+// GenerateChunk realizes the chunk if it is not cached yet, without serializing
+// it. Returns true if the chunk had to be generated.
+func (w *World) GenerateChunk(cx, cz int32) bool {
+	if w.IsChunkLoaded(cx, cz) {
+		return false
+	}
+	w.realize(ChunkPos{cx, cz})
+	return true
+}
 
-	chunk := &Chunk{
-		Sections: sections,
-		Biomes:   biomes,
+// Features returns the loot chests, mob spawners and chest minecarts the
+// generator placed in chunk (cx, cz), generating the chunk if necessary.
+// Features are reported as generated, even if players have since changed the blocks.
+func (w *World) Features(cx, cz int32) ChunkFeatures {
+	cp := ChunkPos{cx, cz}
+	w.mu.RLock()
+	chunk, ok := w.chunks[cp]
+	w.mu.RUnlock()
+	if !ok {
+		chunk = w.realize(cp)
+	}
+	return chunk.Features
+}
+
+// LootChestAt returns the generated loot chest at (x, y, z), if there is one.
+func (w *World) LootChestAt(x, y, z int32) (LootChest, bool) {
+	for _, c := range w.Features(x>>4, z>>4).Chests {
+		if c.Pos == (BlockPos{x, y, z}) {
+			return c, true
+		}
 	}
+	return LootChest{}, false
+}
 
+// RetainChunk marks a chunk as being in use, e.g. because it is inside a
+// player's view area. Each call must be paired with a ReleaseChunk.
+func (w *World) RetainChunk(cx, cz int32) {
+	cp := ChunkPos{cx, cz}
 	w.mu.Lock()
-	if existingChunk, ok := w.chunks[cp]; ok {
-		data, mask := SerializeSections(&existingChunk.Sections, existingChunk.Biomes)
-		w.mu.Unlock()
-		return data, mask
+	defer w.mu.Unlock()
+	w.refs[cp]++
+}
+
+// ReleaseChunk drops a reference taken by RetainChunk. Once a chunk has no
+// references left it becomes eligible for eviction.
+func (w *World) ReleaseChunk(cx, cz int32) {
+	cp := ChunkPos{cx, cz}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.refs[cp] <= 0 {
+		return
 	}
+	w.refs[cp]--
+	if w.refs[cp] == 0 {
+		delete(w.refs, cp)
+		if chunk, ok := w.chunks[cp]; ok {
+			chunk.idleSince = time.Now()
+		}
+	}
+}
 
-	// Apply ALL existing overrides for this chunk to the realized chunk
-	for pos, state := range w.blocks {
-		if pos.X>>4 == cx && pos.Z>>4 == cz {
-			lx, ly, lz := pos.X&0x0F, pos.Y&0x0F, pos.Z&0x0F
-			sec := pos.Y >> 4
-			chunk.Sections[sec][(ly*16+lz)*16+lx] = state
+// EvictIdleChunks removes realized chunks that have had no references for at
+// least the grace period and returns how many were evicted.
+func (w *World) EvictIdleChunks(grace time.Duration) int {
+	cutoff := time.Now().Add(-grace)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	evicted := 0
+	for cp, chunk := range w.chunks {
+		if w.refs[cp] == 0 && !chunk.idleSince.After(cutoff) {
+			// Overrides live outside the chunk, so nothing needs saving here
+			delete(w.chunks, cp)
+			evicted++
 		}
 	}
+	return evicted
+}
 
-	w.chunks[cp] = chunk
-	data, mask := SerializeSections(&chunk.Sections, chunk.Biomes)
-	w.mu.Unlock()
+// LoadedChunkCount returns the number of realized chunks held in memory.
+func (w *World) LoadedChunkCount() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.chunks)
+}
 
-	return data, mask
+// IsChunkLoaded returns true if the chunk is realized in memory.
+func (w *World) IsChunkLoaded(cx, cz int32) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, ok := w.chunks[ChunkPos{cx, cz}]
+	return ok
 }
 
 // GetModifications returns a copy of all modified blocks.
 func (w *World) GetModifications() map[BlockPos]uint16 {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
-	result := make(map[BlockPos]uint16, len(w.blocks))
-	for k, v := range w.blocks {
+	result := make(map[BlockPos]uint16)
+	for _, chunkOverrides := range w.overrides {
+		for k, v := range chunkOverrides {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// ChunkModifications returns a copy of the modified blocks inside one chunk.
+func (w *World) ChunkModifications(cx, cz int32) map[BlockPos]uint16 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	chunkOverrides := w.overrides[ChunkPos{cx, cz}]
+	result := make(map[BlockPos]uint16, len(chunkOverrides))
+	for k, v := range chunkOverrides {
 		result[k] = v
 	}
 	return result
@@ -187,19 +291,7 @@ func (w *World) GetModifications() map[BlockPos]uint16 {
 
 // FlatWorldBlock returns the default block state for a flat world at the given Y level.
 func FlatWorldBlock(y int32) uint16 {
-	if y < 0 || y > 255 {
-		return 0
-	}
-	switch {
-	case y == 0:
-		return 7 << 4 // bedrock
-	case y <= 3:
-		return 3 << 4 // dirt
-	case y == 4:
-		return 2 << 4 // grass
-	default:
-		return 0 // air
-	}
+	return defaultFlatGenerator.BlockAt(int(y))
 }
 
 // IsInstantBreak returns true for blocks with zero hardness that the client
@@ -259,6 +351,8 @@ func BlockToItemID(blockState uint16) (int16, int16, byte) {
 		return -1, 0, 0
 	case 8, 9, 10, 11: // water/lava
 		return -1, 0, 0
+	case 51: // fire
+		return -1, 0, 0
 	case 20, 95, 102, 160: // glass, stained glass, glass panes, stained glass panes
 		return -1, 0, 0
 	case 2: // grass block -> drops dirt
@@ -2,6 +2,7 @@ package world
 
 import (
 	"testing"
+	"time"
 )
 
 func TestTreeDetection(t *testing.T) {
@@ -36,3 +37,59 @@ func TestTreeDetection(t *testing.T) {
 		t.Errorf("No logs found in area (-64,-64,5)-(64,64,15) for seed 12345.")
 	}
 }
+
+func TestChunkEviction(t *testing.T) {
+	w := NewWorld(12345)
+
+	w.RetainChunk(0, 0)
+	w.GetBlock(0, 64, 0)  // Realizes retained chunk (0, 0)
+	w.GetBlock(40, 64, 0) // Realizes unreferenced chunk (2, 0)
+	if n := w.LoadedChunkCount(); n != 2 {
+		t.Fatalf("loaded chunks = %d, want 2", n)
+	}
+
+	if n := w.EvictIdleChunks(0); n != 1 {
+		t.Errorf("evicted %d chunks, want 1", n)
+	}
+	if !w.IsChunkLoaded(0, 0) {
+		t.Error("retained chunk was evicted")
+	}
+	if w.IsChunkLoaded(2, 0) {
+		t.Error("unreferenced chunk was not evicted")
+	}
+
+	// A released chunk survives until the grace period has passed
+	w.ReleaseChunk(0, 0)
+	if n := w.EvictIdleChunks(time.Hour); n != 0 {
+		t.Errorf("evicted %d chunks within the grace period, want 0", n)
+	}
+	if n := w.EvictIdleChunks(0); n != 1 {
+		t.Errorf("evicted %d chunks after the grace period, want 1", n)
+	}
+}
+
+func TestOverridesSurviveEviction(t *testing.T) {
+	w := NewWorld(12345)
+	w.SetBlock(5, 200, 5, 1<<4)   // Before the chunk is realized
+	w.GetBlock(5, 200, 5)         // Realize
+	w.SetBlock(6, 201, 6, 4<<4)   // After the chunk is realized
+	w.SetBlock(100, 200, 100, 20) // In another chunk
+
+	w.EvictIdleChunks(0)
+	if w.LoadedChunkCount() != 0 {
+		t.Fatal("chunks should have been evicted")
+	}
+
+	if got := w.GetBlock(5, 200, 5); got != 1<<4 {
+		t.Errorf("block at (5, 200, 5) = %d after eviction, want stone", got)
+	}
+	if got := w.GetBlock(6, 201, 6); got != 4<<4 {
+		t.Errorf("block at (6, 201, 6) = %d after eviction, want cobblestone", got)
+	}
+	if mods := w.ChunkModifications(0, 0); len(mods) != 2 {
+		t.Errorf("chunk (0, 0) has %d modifications, want 2", len(mods))
+	}
+	if mods := w.GetModifications(); len(mods) != 3 {
+		t.Errorf("world has %d modifications, want 3", len(mods))
+	}
+}